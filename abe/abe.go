@@ -17,20 +17,21 @@
 package abe
 
 import (
-	"bytes"
 	"encoding/base64"
-	"encoding/json"
-	//	"errors"
-	"fmt"
-	"net/http"
+	"errors"
 	"regexp"
-	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	//	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// errInvalidPhoneNumber is returned when the decrypted phone number of an
+	// attestation request isn't a valid phone number. The request can't be
+	// attested.
+	errInvalidPhoneNumber = errors.New("decrypted phone number invalid")
+
+	phoneNumberRegexp = regexp.MustCompile(`^\+[0-9]{8,15}$`)
 )
 
 func decryptPhoneNumber(request types.AttestationRequest, account accounts.Account, wallet accounts.Wallet) (string, error) {
@@ -39,12 +40,11 @@ func decryptPhoneNumber(request types.AttestationRequest, account accounts.Accou
 		return "", err
 	}
 	// TODO(asa): Better validation of phone numbers
-	r, _ := regexp.Compile(`^\+[0-9]{8,15}$`)
 	// if !bytes.Equal(crypto.Keccak256(phoneNumber), request.PhoneHash.Bytes()) {
 	//	return string(phoneNumber), errors.New("Phone hash doesn't match decrypted phone number")
 	//} else
-	if !r.MatchString(string(phoneNumber)) {
-		return string(phoneNumber), fmt.Errorf("Decrypted phone number invalid: %s", string(phoneNumber))
+	if !phoneNumberRegexp.MatchString(string(phoneNumber)) {
+		return string(phoneNumber), errInvalidPhoneNumber
 	}
 	return string(phoneNumber), nil
 }
@@ -56,57 +56,3 @@ func createAttestationMessage(request types.AttestationRequest, account accounts
 	}
 	return base64.URLEncoding.EncodeToString(signature), nil
 }
-
-func sendSms(phoneNumber string, message string, account common.Address, issuer common.Address, verificationServiceURL string) error {
-	values := map[string]string{"phoneNumber": phoneNumber, "message": message, "account": base64.URLEncoding.EncodeToString(account.Bytes()), "issuer": base64.URLEncoding.EncodeToString(issuer.Bytes())}
-	jsonValue, _ := json.Marshal(values)
-	var err error
-
-	// Retry 5 times if we fail.
-	for i := 0; i < 5; i++ {
-		_, err := http.Post(verificationServiceURL, "application/json", bytes.NewBuffer(jsonValue))
-		if err == nil {
-			break
-		}
-		time.Sleep(100 * time.Millisecond)
-	}
-	return err
-}
-
-func SendAttestationMessages(receipts []*types.Receipt, block *types.Block, coinbase common.Address, accountManager *accounts.Manager, verificationServiceURL string) {
-	account := accounts.Account{Address: coinbase}
-	var wallet accounts.Wallet
-	var err error
-	for _, receipt := range receipts {
-		for _, request := range receipt.AttestationRequests {
-			if wallet == nil {
-				wallet, err = accountManager.Find(account)
-				if err != nil {
-					log.Error("[Celo] Failed to get account for sms attestation", "err", err)
-					return
-				}
-			}
-
-			if !bytes.Equal(coinbase.Bytes(), request.Verifier.Bytes()) {
-				continue
-			}
-			phoneNumber, err := decryptPhoneNumber(request, account, wallet)
-			if err != nil {
-				log.Error("[Celo] Failed to decrypt phone number", "err", err)
-				continue
-			}
-
-			message, err := createAttestationMessage(request, account, wallet)
-			if err != nil {
-				log.Error("[Celo] Failed to create attestation message", "err", err)
-				continue
-			}
-
-			log.Debug(fmt.Sprintf("[Celo] Sending attestation message: \"%s\"", message), nil, nil)
-			err = sendSms(phoneNumber, message, request.Account, account.Address, verificationServiceURL)
-			if err != nil {
-				log.Error("[Celo] Failed to send SMS", "err", err)
-			}
-		}
-	}
-}
@@ -0,0 +1,272 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package abe
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Attestation delivery backends
+const (
+	DeliveryHTTP    = "http"    // POST to the verification service
+	DeliveryWebhook = "webhook" // POST to a webhook, signed with HMAC and optionally authenticated with a TLS client certificate
+	DeliveryFile    = "file"    // Append to a file, or print to stdout, for local testing
+)
+
+const (
+	deliveryTimeout = 10 * time.Second // Timeout of a request to the verification service or webhook
+
+	webhookTimestampHeader = "X-Celo-Timestamp"
+	webhookSignatureHeader = "X-Celo-Signature"
+)
+
+var (
+	errMissingDeliveryURL     = errors.New("attestation delivery URL missing")
+	errMissingWebhookSecret   = errors.New("attestation webhook secret missing")
+	errMissingWebhookKeyPair  = errors.New("attestation webhook client certificate and key must be set together")
+	errInvalidWebhookCA       = errors.New("no certificate found in the attestation webhook CA file")
+	errUnknownDeliveryBackend = errors.New("unknown attestation delivery backend")
+)
+
+// Config holds the attestation delivery settings.
+type Config struct {
+	Delivery string // Delivery backend, DeliveryHTTP by default
+	URL      string // URL of the verification service or webhook
+
+	WebhookSecret   string // Secret the webhook requests are signed with
+	WebhookCertFile string // TLS client certificate presented to the webhook
+	WebhookKeyFile  string // Key of the TLS client certificate
+	WebhookCAFile   string // CA certificates the webhook server is verified with, the system ones if empty
+
+	File string // File the attestations are appended to, stdout if empty
+}
+
+// DefaultConfig is the default attestation delivery config.
+var DefaultConfig = Config{
+	Delivery: DeliveryHTTP,
+	URL:      "https://mining-pool.celo.org/v0.1/sms",
+}
+
+// Attestation is an attestation message to send to a phone number.
+type Attestation struct {
+	PhoneNumber string
+	Message     string
+	Account     common.Address // Account requesting the attestation
+	Issuer      common.Address // Validator issuing the attestation
+}
+
+// MarshalJSON encodes the attestation in the format of the verification service.
+func (a *Attestation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"phoneNumber": a.PhoneNumber,
+		"message":     a.Message,
+		"account":     base64.URLEncoding.EncodeToString(a.Account.Bytes()),
+		"issuer":      base64.URLEncoding.EncodeToString(a.Issuer.Bytes()),
+	})
+}
+
+// Deliverer sends attestation messages to their phone numbers. Deliver returns
+// nil once the message is accepted for delivery, and the outbox retries it
+// otherwise.
+type Deliverer interface {
+	Deliver(attestation *Attestation) error
+}
+
+// notAcknowledgedError is returned when the recipient of an attestation message
+// received it but didn't accept it.
+type notAcknowledgedError struct {
+	status string
+}
+
+func (e *notAcknowledgedError) Error() string {
+	return fmt.Sprintf("attestation delivery responded %s", e.status)
+}
+
+// NewDeliverer creates the delivery backend of the given config.
+func NewDeliverer(config *Config) (Deliverer, error) {
+	switch config.Delivery {
+	case "", DeliveryHTTP:
+		if config.URL == "" {
+			return nil, errMissingDeliveryURL
+		}
+		return &httpDeliverer{url: config.URL, client: &http.Client{Timeout: deliveryTimeout}}, nil
+
+	case DeliveryWebhook:
+		return newWebhookDeliverer(config)
+
+	case DeliveryFile:
+		if config.File == "" {
+			return &fileDeliverer{w: os.Stdout}, nil
+		}
+		f, err := os.OpenFile(config.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		return &fileDeliverer{w: f, closer: f}, nil
+	}
+	return nil, errUnknownDeliveryBackend
+}
+
+// post sends the JSON body, with the given headers, and checks that the
+// recipient accepted it.
+func post(client *http.Client, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &notAcknowledgedError{status: resp.Status}
+	}
+	return nil
+}
+
+// httpDeliverer posts the attestations to the verification service.
+type httpDeliverer struct {
+	url    string
+	client *http.Client
+}
+
+func (d *httpDeliverer) Deliver(attestation *Attestation) error {
+	body, err := json.Marshal(attestation)
+	if err != nil {
+		return err
+	}
+	return post(d.client, d.url, body, make(http.Header))
+}
+
+// webhookDeliverer posts the attestations to a webhook. The requests carry the
+// HMAC-SHA256 of their timestamp and body, so that the webhook can authenticate
+// them and reject replays.
+type webhookDeliverer struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func newWebhookDeliverer(config *Config) (*webhookDeliverer, error) {
+	if config.URL == "" {
+		return nil, errMissingDeliveryURL
+	}
+	if config.WebhookSecret == "" {
+		return nil, errMissingWebhookSecret
+	}
+	if (config.WebhookCertFile == "") != (config.WebhookKeyFile == "") {
+		return nil, errMissingWebhookKeyPair
+	}
+
+	tlsConfig := new(tls.Config)
+	if config.WebhookCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.WebhookCertFile, config.WebhookKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if config.WebhookCAFile != "" {
+		pem, err := ioutil.ReadFile(config.WebhookCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errInvalidWebhookCA
+		}
+	}
+
+	return &webhookDeliverer{
+		url:    config.URL,
+		secret: []byte(config.WebhookSecret),
+		client: &http.Client{
+			Timeout:   deliveryTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// webhookSignature returns the signature of a webhook request.
+func webhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *webhookDeliverer) Deliver(attestation *Attestation) error {
+	body, err := json.Marshal(attestation)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+
+	header := make(http.Header)
+	header.Set(webhookTimestampHeader, timestamp)
+	header.Set(webhookSignatureHeader, webhookSignature(d.secret, timestamp, body))
+	return post(d.client, d.url, body, header)
+}
+
+// fileDeliverer writes the attestations as JSON lines.
+type fileDeliverer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func (d *fileDeliverer) Deliver(attestation *Attestation) error {
+	body, err := json.Marshal(attestation)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err = d.w.Write(append(body, '\n'))
+	return err
+}
+
+// Close closes the file the attestations are written to.
+func (d *fileDeliverer) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	return d.closer.Close()
+}
@@ -0,0 +1,297 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package abe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// testWebhook is a webhook stub, which accepts the requests signed with its
+// secret.
+type testWebhook struct {
+	*httptest.Server
+	secret []byte
+
+	mu       sync.Mutex
+	messages []map[string]string
+	rejected int
+}
+
+func newTestWebhook(secret string) *testWebhook {
+	w := &testWebhook{secret: []byte(secret)}
+	w.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp := r.Header.Get(webhookTimestampHeader)
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if r.Header.Get(webhookSignatureHeader) != webhookSignature(w.secret, timestamp, body) {
+			w.rejected++
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if ts, _ := strconv.ParseInt(timestamp, 10, 64); ts != now().Unix() {
+			w.rejected++
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var message map[string]string
+		json.Unmarshal(body, &message)
+		w.messages = append(w.messages, message)
+	}))
+	return w
+}
+
+func (w *testWebhook) received() ([]map[string]string, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.messages, w.rejected
+}
+
+// writePEM writes a PEM block to a file in the given directory.
+func writePEM(t *testing.T, dir, name, kind string, bytes []byte) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: bytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newClientCertificate creates a self signed client certificate, returning it
+// along with the paths of the certificate and key files.
+func newClientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "validator"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, writePEM(t, dir, "client.crt", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDer)
+}
+
+func TestHTTPDelivery(t *testing.T) {
+	_, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+	service := newTestService()
+	defer service.Close()
+
+	chain, genesis := newTestChain()
+	request := verifier.request(t, "+14155550000", 1)
+
+	o := NewOutbox(ethdb.NewMemDatabase(), verifier.manager, newHTTPDeliverer(t, service.URL))
+	o.AddBlocks(chain, chain.add(genesis, "", request), verifier.address)
+	o.process()
+
+	received := service.received()
+	if len(received) != 1 {
+		t.Fatalf("messages mismatch: have %d, want 1", len(received))
+	}
+	want := map[string]string{"phoneNumber": "+14155550000", "account": "AAAAAAAAAAAAAAAAAAAAAAAAAAE=", "issuer": received[0]["issuer"]}
+	for field, value := range want {
+		if received[0][field] != value {
+			t.Errorf("%s mismatch: have %q, want %q", field, received[0][field], value)
+		}
+	}
+	if received[0]["message"] == "" {
+		t.Errorf("message mismatch: have %q", received[0]["message"])
+	}
+	if a := attestationState(o, request); a.State != StateAcknowledged {
+		t.Errorf("state mismatch: have %v, want %v", a.State, StateAcknowledged)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	advance, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+
+	dir, err := ioutil.TempDir("", "abe-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The webhook only accepts TLS connections with the client certificate
+	clientCert, certFile, keyFile := newClientCertificate(t, dir)
+	webhook := newTestWebhook("secret")
+	webhook.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: x509.NewCertPool()}
+	webhook.TLS.ClientCAs.AddCert(clientCert)
+	webhook.StartTLS()
+	defer webhook.Close()
+	caFile := writePEM(t, dir, "ca.crt", "CERTIFICATE", webhook.Certificate().Raw)
+
+	chain, genesis := newTestChain()
+	first, second := verifier.request(t, "+14155550000", 1), verifier.request(t, "+14155550001", 2)
+	block := chain.add(genesis, "", first)
+
+	config := Config{
+		Delivery:        DeliveryWebhook,
+		URL:             webhook.URL,
+		WebhookSecret:   "secret",
+		WebhookCertFile: certFile,
+		WebhookKeyFile:  keyFile,
+		WebhookCAFile:   caFile,
+	}
+	deliverer, err := NewDeliverer(&config)
+	if err != nil {
+		t.Fatalf("failed to create deliverer: %v", err)
+	}
+	o := NewOutbox(ethdb.NewMemDatabase(), verifier.manager, deliverer)
+	o.AddBlocks(chain, block, verifier.address)
+	o.process()
+	if received, rejected := webhook.received(); len(received) != 1 || rejected != 0 || received[0]["phoneNumber"] != "+14155550000" {
+		t.Fatalf("messages mismatch: have %v, %d rejected", received, rejected)
+	}
+
+	// Requests signed with another secret are rejected, and retried
+	config.WebhookSecret = "other"
+	if o.deliverer, err = NewDeliverer(&config); err != nil {
+		t.Fatalf("failed to create deliverer: %v", err)
+	}
+	o.AddBlocks(chain, chain.add(block, "", second), verifier.address)
+	o.process()
+	if received, rejected := webhook.received(); len(received) != 1 || rejected != 1 {
+		t.Fatalf("messages mismatch: have %d accepted, %d rejected, want 1, 1", len(received), rejected)
+	}
+	if a := attestationState(o, second); a.State != StateDelivered {
+		t.Errorf("state mismatch: have %v, want %v", a.State, StateDelivered)
+	}
+
+	// Without the client certificate, the TLS handshake fails
+	config.WebhookCertFile, config.WebhookKeyFile = "", ""
+	if o.deliverer, err = NewDeliverer(&config); err != nil {
+		t.Fatalf("failed to create deliverer: %v", err)
+	}
+	advance(retryBase)
+	o.process()
+	if a := attestationState(o, second); a.State != StateSigned || a.Attempts != 2 {
+		t.Errorf("attestation mismatch: have state %v, %d attempts, want %v, 2", a.State, a.Attempts, StateSigned)
+	}
+}
+
+func TestFileDelivery(t *testing.T) {
+	_, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+
+	dir, err := ioutil.TempDir("", "abe-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "attestations.jsonl")
+
+	chain, genesis := newTestChain()
+	block := chain.add(genesis, "", verifier.request(t, "+14155550000", 1), verifier.request(t, "+14155550001", 2))
+
+	deliverer, err := NewDeliverer(&Config{Delivery: DeliveryFile, File: file})
+	if err != nil {
+		t.Fatalf("failed to create deliverer: %v", err)
+	}
+	o := NewOutbox(ethdb.NewMemDatabase(), verifier.manager, deliverer)
+	o.AddBlocks(chain, block, verifier.address)
+	o.process()
+
+	// Stopping the outbox closes the file
+	o.Start()
+	o.Stop()
+	if err := deliverer.(*fileDeliverer).closer.(*os.File).Close(); err == nil {
+		t.Errorf("file not closed")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines mismatch: have %d, want 2", len(lines))
+	}
+	phoneNumbers := make(map[string]bool)
+	for _, line := range lines {
+		var message map[string]string
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("failed to decode %q: %v", line, err)
+		}
+		phoneNumbers[message["phoneNumber"]] = true
+	}
+	if !phoneNumbers["+14155550000"] || !phoneNumbers["+14155550001"] {
+		t.Errorf("phone numbers mismatch: have %v", phoneNumbers)
+	}
+}
+
+func TestNewDeliverer(t *testing.T) {
+	tests := []struct {
+		config Config
+		err    error
+	}{
+		{Config{Delivery: DeliveryHTTP}, errMissingDeliveryURL},
+		{Config{Delivery: DeliveryWebhook, URL: "https://localhost"}, errMissingWebhookSecret},
+		{Config{Delivery: DeliveryWebhook, URL: "https://localhost", WebhookSecret: "secret", WebhookCertFile: "client.crt"}, errMissingWebhookKeyPair},
+		{Config{Delivery: "sms"}, errUnknownDeliveryBackend},
+		{DefaultConfig, nil},
+	}
+	for i, tt := range tests {
+		if _, err := NewDeliverer(&tt.config); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
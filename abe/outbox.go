@@ -0,0 +1,523 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package abe
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	dbKeyAttestationPrefix = "abe-attestation-"
+	dbKeyAttestationIndex  = "abe-attestations"
+	dbKeyLastBlock         = "abe-last-block"
+
+	processInterval = time.Second      // Interval to check for attestations due for delivery
+	retryBase       = 5 * time.Second  // Delay before the first retry of a failed delivery
+	retryMax        = 30 * time.Minute // Maximum delay between retries
+)
+
+// now is the clock of the outbox, which tests override.
+var now = time.Now
+
+// AttestationState is how far the last delivery attempt of an attestation got.
+type AttestationState uint8
+
+const (
+	StatePending      AttestationState = iota // Not attempted yet, or the phone number couldn't be decrypted
+	StateDecrypted                            // The phone number was decrypted, but the message couldn't be signed
+	StateSigned                               // The message was signed, but couldn't be sent
+	StateDelivered                            // The message was sent, but the recipient didn't accept it
+	StateAcknowledged                         // The recipient accepted the message
+	StateFailed                               // The request can't be attested
+	StateExpired                              // The request expired before the message was accepted
+)
+
+func (s AttestationState) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateDecrypted:
+		return "decrypted"
+	case StateSigned:
+		return "signed"
+	case StateDelivered:
+		return "delivered"
+	case StateAcknowledged:
+		return "acknowledged"
+	case StateFailed:
+		return "failed"
+	case StateExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s AttestationState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// final returns whether no more delivery is attempted in the state.
+func (s AttestationState) final() bool {
+	return s >= StateAcknowledged
+}
+
+// ChainReader is the chain the attestation requests are read from.
+type ChainReader interface {
+	GetBlock(hash common.Hash, number uint64) *types.Block
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+}
+
+// attestation is an attestation request addressed to the node, and the state of
+// its delivery.
+type attestation struct {
+	Request     types.AttestationRequest
+	Issuer      common.Address
+	BlockHash   common.Hash
+	BlockNumber uint64
+	BlockTime   uint64
+	State       AttestationState
+	Attempts    uint64
+	NextAttempt uint64 // Unix time of the next delivery attempt
+	LastError   string
+}
+
+// expired returns whether a request of a block with the given time is too old to
+// be attested.
+func expired(blockTime uint64) bool {
+	return blockTime+params.AttestationExpirySeconds < uint64(now().Unix())
+}
+
+// retryDelay returns the delay before retrying a delivery that failed the given
+// number of times.
+func retryDelay(attempts uint64) time.Duration {
+	delay := retryBase
+	for i := uint64(1); i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	if delay > retryMax {
+		delay = retryMax
+	}
+	return delay
+}
+
+// requestHash identifies an attestation request, whichever block it is included in.
+func requestHash(request *types.AttestationRequest) common.Hash {
+	data, _ := rlp.EncodeToBytes(request)
+	return crypto.Keccak256Hash(data)
+}
+
+func attestationKey(hash common.Hash) []byte {
+	return append([]byte(dbKeyAttestationPrefix), hash[:]...)
+}
+
+// blockRef is the last block the attestation requests were read from.
+type blockRef struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// Outbox stores the attestation requests addressed to the node in the database,
+// and delivers the attestation messages through the deliverer until it accepts
+// them, retrying with exponential backoff. Requests are identified by
+// their content, so that they are delivered once across restarts and reorgs.
+type Outbox struct {
+	db             ethdb.Database
+	accountManager *accounts.Manager
+	deliverer      Deliverer
+
+	mu           sync.Mutex
+	attestations map[common.Hash]*attestation
+	lastBlock    *blockRef
+
+	wake chan struct{}
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewOutbox creates an outbox, loading the attestations stored in the database.
+func NewOutbox(db ethdb.Database, accountManager *accounts.Manager, deliverer Deliverer) *Outbox {
+	o := &Outbox{
+		db:             db,
+		accountManager: accountManager,
+		deliverer:      deliverer,
+		attestations:   make(map[common.Hash]*attestation),
+		wake:           make(chan struct{}, 1),
+	}
+	if err := o.load(); err != nil {
+		log.Error("[Celo] Failed to load attestations", "err", err)
+	}
+	return o
+}
+
+// load reads the attestations and the last block they were read from.
+func (o *Outbox) load() error {
+	if blob, err := o.db.Get([]byte(dbKeyLastBlock)); err == nil {
+		lastBlock := new(blockRef)
+		if err := rlp.DecodeBytes(blob, lastBlock); err != nil {
+			return err
+		}
+		o.lastBlock = lastBlock
+	}
+
+	blob, err := o.db.Get([]byte(dbKeyAttestationIndex))
+	if err != nil {
+		// Nothing has been stored yet
+		return nil
+	}
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(blob, &hashes); err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		blob, err := o.db.Get(attestationKey(hash))
+		if err != nil {
+			return err
+		}
+		a := new(attestation)
+		if err := rlp.DecodeBytes(blob, a); err != nil {
+			return err
+		}
+		o.attestations[hash] = a
+	}
+	return nil
+}
+
+// storeIndex writes the hashes of the attestations. It must be called with the
+// lock held.
+func (o *Outbox) storeIndex(batch ethdb.Batch) error {
+	hashes := make([]common.Hash, 0, len(o.attestations))
+	for hash := range o.attestations {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	blob, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		return err
+	}
+	return batch.Put([]byte(dbKeyAttestationIndex), blob)
+}
+
+// store writes an attestation.
+func (o *Outbox) store(batch ethdb.Batch, hash common.Hash, a *attestation) error {
+	blob, err := rlp.EncodeToBytes(a)
+	if err != nil {
+		return err
+	}
+	return batch.Put(attestationKey(hash), blob)
+}
+
+// Start starts delivering the attestations.
+func (o *Outbox) Start() {
+	o.quit = make(chan struct{})
+	o.wg.Add(1)
+	go o.loop()
+}
+
+// Stop stops delivering the attestations, and closes the deliverer.
+func (o *Outbox) Stop() {
+	if o.quit == nil {
+		return
+	}
+	close(o.quit)
+	o.wg.Wait()
+	o.quit = nil
+
+	if closer, ok := o.deliverer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Error("[Celo] Failed to close attestation deliverer", "err", err)
+		}
+	}
+}
+
+func (o *Outbox) loop() {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(processInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			o.process()
+		case <-o.wake:
+			o.process()
+		case <-o.quit:
+			return
+		}
+	}
+}
+
+// AddBlocks stores the attestation requests to the given verifier in the blocks
+// of the chain of the given head that weren't read yet. After a reorg, the blocks
+// of the new chain are read down to the common ancestor with the last one. Blocks
+// too old for their requests to be attested are skipped.
+func (o *Outbox) AddBlocks(chain ChainReader, head *types.Block, verifier common.Address) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	parent := func(block *types.Block) *types.Block {
+		if block.NumberU64() == 0 {
+			return nil
+		}
+		return chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	}
+
+	var last *types.Block
+	if o.lastBlock != nil {
+		last = chain.GetBlock(o.lastBlock.Hash, o.lastBlock.Number)
+	}
+	var blocks []*types.Block
+	for block := head; block != nil && !expired(block.Time().Uint64()); block = parent(block) {
+		for last != nil && last.NumberU64() > block.NumberU64() {
+			last = parent(last)
+		}
+		if last != nil && last.Hash() == block.Hash() {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+
+	batch := o.db.NewBatch()
+	added := 0
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		for _, receipt := range chain.GetReceiptsByHash(block.Hash()) {
+			for _, request := range receipt.AttestationRequests {
+				if request.Verifier != verifier {
+					continue
+				}
+				hash := requestHash(&request)
+				if _, ok := o.attestations[hash]; ok {
+					continue
+				}
+				a := &attestation{
+					Request:     request,
+					Issuer:      verifier,
+					BlockHash:   block.Hash(),
+					BlockNumber: block.NumberU64(),
+					BlockTime:   block.Time().Uint64(),
+					State:       StatePending,
+					NextAttempt: uint64(now().Unix()),
+				}
+				o.attestations[hash] = a
+				if err := o.store(batch, hash, a); err != nil {
+					log.Error("[Celo] Failed to store attestation", "hash", hash, "err", err)
+				}
+				added++
+			}
+		}
+	}
+
+	o.lastBlock = &blockRef{Number: head.NumberU64(), Hash: head.Hash()}
+	if blob, err := rlp.EncodeToBytes(o.lastBlock); err == nil {
+		batch.Put([]byte(dbKeyLastBlock), blob)
+	}
+	if added > 0 {
+		if err := o.storeIndex(batch); err != nil {
+			log.Error("[Celo] Failed to store attestation index", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("[Celo] Failed to store attestations", "err", err)
+	}
+
+	if added > 0 {
+		log.Debug("[Celo] Added attestation requests", "count", added, "number", head.NumberU64())
+		select {
+		case o.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// process attempts the deliveries that are due, and forgets the attestations
+// that are done with and too old to be requested again.
+func (o *Outbox) process() {
+	o.mu.Lock()
+	batch := o.db.NewBatch()
+	pruned := false
+	var due []common.Hash
+	for hash, a := range o.attestations {
+		switch {
+		case a.State.final() && expired(a.BlockTime):
+			delete(o.attestations, hash)
+			batch.Delete(attestationKey(hash))
+			pruned = true
+		case a.State.final():
+		case expired(a.BlockTime):
+			a.State = StateExpired
+			o.store(batch, hash, a)
+		case a.NextAttempt <= uint64(now().Unix()):
+			due = append(due, hash)
+		}
+	}
+	if pruned {
+		o.storeIndex(batch)
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("[Celo] Failed to store attestations", "err", err)
+	}
+
+	// Attempt the oldest requests first
+	sort.Slice(due, func(i, j int) bool {
+		return o.attestations[due[i]].BlockNumber < o.attestations[due[j]].BlockNumber
+	})
+	attempts := make([]attestation, len(due))
+	for i, hash := range due {
+		attempts[i] = *o.attestations[hash]
+	}
+	o.mu.Unlock()
+
+	for i, hash := range due {
+		a := &attempts[i]
+		o.attempt(a)
+
+		o.mu.Lock()
+		if _, ok := o.attestations[hash]; ok {
+			o.attestations[hash] = a
+			batch := o.db.NewBatch()
+			o.store(batch, hash, a)
+			if err := batch.Write(); err != nil {
+				log.Error("[Celo] Failed to store attestation", "hash", hash, "err", err)
+			}
+		}
+		o.mu.Unlock()
+	}
+}
+
+// attempt decrypts the phone number of the request, signs the attestation
+// message and hands it to the deliverer, recording how far it got.
+func (o *Outbox) attempt(a *attestation) {
+	a.Attempts++
+	a.State = StatePending
+	logger := log.New("account", a.Request.Account, "number", a.BlockNumber, "attempts", a.Attempts)
+
+	retry := func(err error) {
+		a.LastError = err.Error()
+		a.NextAttempt = uint64(now().Add(retryDelay(a.Attempts)).Unix())
+		logger.Warn("[Celo] Failed to deliver attestation, retrying", "state", a.State, "next", a.NextAttempt, "err", err)
+	}
+
+	account := accounts.Account{Address: a.Issuer}
+	if o.accountManager == nil {
+		retry(accounts.ErrUnknownAccount)
+		return
+	}
+	wallet, err := o.accountManager.Find(account)
+	if err != nil {
+		retry(err)
+		return
+	}
+	phoneNumber, err := decryptPhoneNumber(a.Request, account, wallet)
+	if err == errInvalidPhoneNumber {
+		a.State, a.LastError = StateFailed, err.Error()
+		logger.Error("[Celo] Failed to decrypt phone number", "err", err)
+		return
+	} else if err != nil {
+		retry(err)
+		return
+	}
+	a.State = StateDecrypted
+
+	message, err := createAttestationMessage(a.Request, account, wallet)
+	if err != nil {
+		retry(err)
+		return
+	}
+	a.State = StateSigned
+
+	err = o.deliverer.Deliver(&Attestation{
+		PhoneNumber: phoneNumber,
+		Message:     message,
+		Account:     a.Request.Account,
+		Issuer:      a.Issuer,
+	})
+	if err != nil {
+		if _, ok := err.(*notAcknowledgedError); ok {
+			a.State = StateDelivered
+		}
+		retry(err)
+		return
+	}
+	a.State, a.LastError = StateAcknowledged, ""
+	logger.Info("[Celo] Delivered attestation")
+}
+
+// AttestationStatus is the state of the delivery of an attestation.
+type AttestationStatus struct {
+	Hash        common.Hash      `json:"hash"`
+	Account     common.Address   `json:"account"`
+	Issuer      common.Address   `json:"issuer"`
+	PhoneHash   common.Hash      `json:"phoneHash"`
+	BlockNumber uint64           `json:"blockNumber"`
+	BlockHash   common.Hash      `json:"blockHash"`
+	State       AttestationState `json:"state"`
+	Attempts    uint64           `json:"attempts"`
+	NextAttempt uint64           `json:"nextAttempt"`
+	LastError   string           `json:"lastError,omitempty"`
+}
+
+// Status is the state of the outbox.
+type Status struct {
+	LastBlock    uint64               `json:"lastBlock"`
+	Counts       map[string]int       `json:"counts"` // Number of attestations in each state
+	Attestations []*AttestationStatus `json:"attestations"`
+}
+
+// Status returns the state of the attestations, the oldest first.
+func (o *Outbox) Status() *Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	status := &Status{Counts: make(map[string]int), Attestations: make([]*AttestationStatus, 0, len(o.attestations))}
+	if o.lastBlock != nil {
+		status.LastBlock = o.lastBlock.Number
+	}
+	for hash, a := range o.attestations {
+		status.Counts[a.State.String()]++
+		status.Attestations = append(status.Attestations, &AttestationStatus{
+			Hash:        hash,
+			Account:     a.Request.Account,
+			Issuer:      a.Issuer,
+			PhoneHash:   a.Request.PhoneHash,
+			BlockNumber: a.BlockNumber,
+			BlockHash:   a.BlockHash,
+			State:       a.State,
+			Attempts:    a.Attempts,
+			NextAttempt: a.NextAttempt,
+			LastError:   a.LastError,
+		})
+	}
+	sort.Slice(status.Attestations, func(i, j int) bool {
+		return status.Attestations[i].BlockNumber < status.Attestations[j].BlockNumber
+	})
+	return status
+}
@@ -0,0 +1,298 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package abe
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+type testChain struct {
+	blocks   map[common.Hash]*types.Block
+	receipts map[common.Hash]types.Receipts
+}
+
+func newTestChain() (*testChain, *types.Block) {
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Time: big.NewInt(now().Unix())})
+	chain := &testChain{blocks: make(map[common.Hash]*types.Block), receipts: make(map[common.Hash]types.Receipts)}
+	chain.blocks[genesis.Hash()] = genesis
+	return chain, genesis
+}
+
+// add creates a child block of the given parent with the given requests. The
+// extra data tells apart the blocks of different forks.
+func (c *testChain) add(parent *types.Block, extra string, requests ...types.AttestationRequest) *types.Block {
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Time:       big.NewInt(now().Unix()),
+		Extra:      []byte(extra),
+	})
+	c.blocks[block.Hash()] = block
+	c.receipts[block.Hash()] = types.Receipts{{AttestationRequests: requests}}
+	return block
+}
+
+func (c *testChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return c.blocks[hash]
+}
+
+func (c *testChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return c.receipts[hash]
+}
+
+// testVerifier is a validator account with an unlocked key, which attestation
+// requests are addressed to.
+type testVerifier struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	manager *accounts.Manager
+	dir     string
+}
+
+func newTestVerifier(t *testing.T) *testVerifier {
+	dir, err := ioutil.TempDir("", "abe-keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	key, _ := crypto.GenerateKey()
+	account, err := ks.ImportECDSA(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatal(err)
+	}
+	return &testVerifier{key: key, address: account.Address, manager: accounts.NewManager(ks), dir: dir}
+}
+
+func (v *testVerifier) close() {
+	v.manager.Close()
+	os.RemoveAll(v.dir)
+}
+
+func (v *testVerifier) request(t *testing.T, phoneNumber string, code byte) types.AttestationRequest {
+	encrypted, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(&v.key.PublicKey), []byte(phoneNumber), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to encrypt phone number: %v", err)
+	}
+	return types.AttestationRequest{
+		PhoneHash:      crypto.Keccak256Hash([]byte(phoneNumber)),
+		CodeHash:       crypto.Keccak256Hash([]byte{code}),
+		Account:        common.BytesToAddress([]byte{code}),
+		Verifier:       v.address,
+		EncryptedPhone: encrypted,
+	}
+}
+
+// testService is a verification service stub, which responds with the given
+// status code.
+type testService struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	messages []map[string]string
+}
+
+func newTestService() *testService {
+	s := &testService{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]string
+		json.NewDecoder(r.Body).Decode(&message)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.messages = append(s.messages, message)
+		w.WriteHeader(s.status)
+	}))
+	return s
+}
+
+func (s *testService) respond(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *testService) received() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+func newHTTPDeliverer(t *testing.T, url string) Deliverer {
+	deliverer, err := NewDeliverer(&Config{Delivery: DeliveryHTTP, URL: url})
+	if err != nil {
+		t.Fatalf("failed to create deliverer: %v", err)
+	}
+	return deliverer
+}
+
+func attestationState(o *Outbox, request types.AttestationRequest) *attestation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.attestations[requestHash(&request)]
+}
+
+func useTestClock(t time.Time) (advance func(time.Duration), restore func()) {
+	clock := t
+	now = func() time.Time { return clock }
+	return func(d time.Duration) { clock = clock.Add(d) }, func() { now = time.Now }
+}
+
+func TestOutboxDelivery(t *testing.T) {
+	advance, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+	service := newTestService()
+	defer service.Close()
+
+	chain, genesis := newTestChain()
+	request := verifier.request(t, "+14155550000", 1)
+	other := request
+	other.Verifier = common.HexToAddress("0x1")
+	block := chain.add(genesis, "", request, other)
+
+	o := NewOutbox(ethdb.NewMemDatabase(), verifier.manager, newHTTPDeliverer(t, service.URL))
+	o.AddBlocks(chain, block, verifier.address)
+	if status := o.Status(); len(status.Attestations) != 1 || status.LastBlock != 1 {
+		t.Fatalf("status mismatch: have %d attestations at block %d, want 1 at block 1", len(status.Attestations), status.LastBlock)
+	}
+
+	// The message is retried until the verification service accepts it
+	service.respond(http.StatusInternalServerError)
+	o.process()
+	a := attestationState(o, request)
+	if a.State != StateDelivered || a.Attempts != 1 || a.NextAttempt != uint64(now().Add(retryBase).Unix()) {
+		t.Errorf("attestation mismatch: have state %v, %d attempts, next at %d", a.State, a.Attempts, a.NextAttempt)
+	}
+	o.process()
+	if received := service.received(); len(received) != 1 {
+		t.Errorf("messages mismatch: have %d, want 1 before the backoff elapsed", len(received))
+	}
+
+	advance(retryBase)
+	service.respond(http.StatusOK)
+	o.process()
+	if a := attestationState(o, request); a.State != StateAcknowledged || a.Attempts != 2 {
+		t.Errorf("attestation mismatch: have state %v, %d attempts, want %v, 2", a.State, a.Attempts, StateAcknowledged)
+	}
+	received := service.received()
+	if len(received) != 2 || received[1]["phoneNumber"] != "+14155550000" {
+		t.Errorf("messages mismatch: have %v", received)
+	}
+	o.process()
+	if len(service.received()) != 2 {
+		t.Errorf("acknowledged attestation was delivered again")
+	}
+}
+
+func TestOutboxDeduplication(t *testing.T) {
+	_, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+
+	chain, genesis := newTestChain()
+	first, second := verifier.request(t, "+14155550000", 1), verifier.request(t, "+14155550001", 2)
+	block := chain.add(genesis, "", first)
+
+	db, deliverer := ethdb.NewMemDatabase(), newHTTPDeliverer(t, "http://127.0.0.1:0")
+	NewOutbox(db, verifier.manager, deliverer).AddBlocks(chain, block, verifier.address)
+
+	// The requests are read once across restarts
+	o := NewOutbox(db, verifier.manager, deliverer)
+	o.AddBlocks(chain, chain.add(block, ""), verifier.address)
+	if status := o.Status(); len(status.Attestations) != 1 || status.LastBlock != 2 {
+		t.Fatalf("status mismatch: have %d attestations at block %d, want 1 at block 2", len(status.Attestations), status.LastBlock)
+	}
+
+	// After a reorg, the requests of the new chain are read, the ones included
+	// again are not
+	fork := chain.add(genesis, "fork", second, first)
+	o.AddBlocks(chain, chain.add(fork, "fork"), verifier.address)
+	status := o.Status()
+	if len(status.Attestations) != 2 || status.Counts[StatePending.String()] != 2 {
+		t.Fatalf("status mismatch: have %d attestations, counts %v, want 2 pending", len(status.Attestations), status.Counts)
+	}
+	if a := attestationState(o, first); a.BlockHash != block.Hash() {
+		t.Errorf("block mismatch: have %x, want %x", a.BlockHash, block.Hash())
+	}
+}
+
+func TestOutboxFailures(t *testing.T) {
+	advance, restore := useTestClock(time.Unix(1560000000, 0))
+	defer restore()
+	verifier := newTestVerifier(t)
+	defer verifier.close()
+
+	chain, genesis := newTestChain()
+	invalid, unreachable := verifier.request(t, "not a number", 1), verifier.request(t, "+14155550000", 2)
+	block := chain.add(genesis, "", invalid, unreachable)
+
+	o := NewOutbox(ethdb.NewMemDatabase(), verifier.manager, newHTTPDeliverer(t, "http://127.0.0.1:0"))
+	o.AddBlocks(chain, block, verifier.address)
+	o.process()
+
+	// Requests with an invalid phone number are never delivered
+	if a := attestationState(o, invalid); a.State != StateFailed {
+		t.Errorf("state mismatch: have %v, want %v", a.State, StateFailed)
+	}
+	a := attestationState(o, unreachable)
+	if a.State != StateSigned || a.LastError == "" {
+		t.Errorf("attestation mismatch: have state %v, error %q, want %v", a.State, a.LastError, StateSigned)
+	}
+
+	// The delay between retries doubles
+	for i, want := range []time.Duration{retryBase, 2 * retryBase, 4 * retryBase} {
+		if a := attestationState(o, unreachable); a.NextAttempt != uint64(now().Add(want).Unix()) {
+			t.Errorf("retry %d: next attempt mismatch: have %d, want %d", i, a.NextAttempt, now().Add(want).Unix())
+		}
+		advance(want)
+		o.process()
+	}
+
+	// Requests expire, and are then forgotten
+	advance(24 * time.Hour)
+	o.process()
+	if a := attestationState(o, unreachable); a.State != StateExpired {
+		t.Errorf("state mismatch: have %v, want %v", a.State, StateExpired)
+	}
+	o.process()
+	if status := o.Status(); len(status.Attestations) != 0 {
+		t.Errorf("attestations mismatch: have %d, want 0", len(status.Attestations))
+	}
+}
@@ -39,6 +39,7 @@ const (
 	LangGo Lang = iota
 	LangJava
 	LangObjC
+	LangGoInternal // Go bindings operating through the node's own EVM
 )
 
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
@@ -140,13 +141,14 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
+		"internalcall":  newTmplInternalCall,
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
 		return "", err
 	}
 	// For Go bindings pass the code through gofmt to clean it up
-	if lang == LangGo {
+	if lang == LangGo || lang == LangGoInternal {
 		code, err := format.Source(buffer.Bytes())
 		if err != nil {
 			return "", fmt.Errorf("%v\n%s", err, buffer)
@@ -160,8 +162,9 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type) string{
-	LangGo:   bindTypeGo,
-	LangJava: bindTypeJava,
+	LangGo:         bindTypeGo,
+	LangJava:       bindTypeJava,
+	LangGoInternal: bindTypeGo,
 }
 
 // Helper function for the binding generators.
@@ -311,8 +314,9 @@ func bindUnnestedTypeJava(stringKind string) (int, string) {
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type) string{
-	LangGo:   bindTopicTypeGo,
-	LangJava: bindTopicTypeJava,
+	LangGo:         bindTopicTypeGo,
+	LangJava:       bindTopicTypeJava,
+	LangGoInternal: bindTopicTypeGo,
 }
 
 // bindTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -338,8 +342,9 @@ func bindTopicTypeJava(kind abi.Type) string {
 // namedType is a set of functions that transform language specific types to
 // named versions that my be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
-	LangGo:   func(string, abi.Type) string { panic("this shouldn't be needed") },
-	LangJava: namedTypeJava,
+	LangGo:         func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangJava:       namedTypeJava,
+	LangGoInternal: func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // namedTypeJava converts some primitive data types to named variants that can
@@ -381,8 +386,9 @@ func namedTypeJava(javaKind string, solKind abi.Type) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming concentions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo:   abi.ToCamelCase,
-	LangJava: decapitalise,
+	LangGo:         abi.ToCamelCase,
+	LangJava:       decapitalise,
+	LangGoInternal: abi.ToCamelCase,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
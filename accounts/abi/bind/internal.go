@@ -0,0 +1,91 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// InternalEVMHandler defines the methods needed to operate with a contract through
+// the node's own EVM, rather than through a ContractBackend. It is implemented
+// by core.InternalEVMHandler.
+type InternalEVMHandler interface {
+	// MakeStaticCall executes a read only contract call at the given header and
+	// state, unpacking the return value into returnObj.
+	MakeStaticCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state *state.StateDB) (uint64, error)
+	// MakeCall executes a state modifying contract call at the given header and
+	// state, unpacking the return value into returnObj.
+	MakeCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int, header *types.Header, state *state.StateDB) (uint64, error)
+}
+
+// InternalCallOpts is the collection of options to fine tune a contract call
+// made through an InternalEVMHandler.
+type InternalCallOpts struct {
+	Gas    uint64         // Gas allowance of the call
+	Value  *big.Int       // Funds to transfer along the call (nil = 0), ignored for read only calls
+	Header *types.Header  // Header to execute the call at (nil = current head)
+	State  *state.StateDB // State to execute the call on (nil = state of the current head)
+}
+
+// value returns the funds to transfer along the call.
+func (opts *InternalCallOpts) value() *big.Int {
+	if opts.Value == nil {
+		return new(big.Int)
+	}
+	return opts.Value
+}
+
+// BoundInternalContract is the base wrapper object that reflects a contract on
+// the node's own EVM. It contains a collection of methods that are used by the
+// higher level contract bindings to operate.
+type BoundInternalContract struct {
+	address common.Address     // Deployment address of the contract on the Ethereum blockchain
+	abi     abi.ABI            // Reflect based ABI to access the correct Ethereum methods
+	handler InternalEVMHandler // EVM handler to execute the calls with
+}
+
+// NewBoundInternalContract creates a low level contract interface through which
+// calls can be made on the node's own EVM.
+func NewBoundInternalContract(address common.Address, abi abi.ABI, handler InternalEVMHandler) *BoundInternalContract {
+	return &BoundInternalContract{
+		address: address,
+		abi:     abi,
+		handler: handler,
+	}
+}
+
+// Address returns the address the contract is bound to.
+func (c *BoundInternalContract) Address() common.Address {
+	return c.address
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. It returns the gas left over after the call.
+func (c *BoundInternalContract) Call(opts *InternalCallOpts, result interface{}, method string, params ...interface{}) (uint64, error) {
+	return c.handler.MakeStaticCall(c.address, c.abi, method, params, result, opts.Gas, opts.Header, opts.State)
+}
+
+// Transact invokes the (paid) contract method with params as input values and
+// sets the output to result. It returns the gas left over after the call.
+func (c *BoundInternalContract) Transact(opts *InternalCallOpts, result interface{}, method string, params ...interface{}) (uint64, error) {
+	return c.handler.MakeCall(c.address, c.abi, method, params, result, opts.Gas, opts.value(), opts.Header, opts.State)
+}
@@ -49,11 +49,25 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplInternalCall contains the data needed to generate the body of a method of
+// a binding operated through the node's own EVM.
+type tmplInternalCall struct {
+	Type   string      // Type name of the contract binding the method belongs to
+	Kind   string      // Low level call to make, either Call or Transact
+	Method *tmplMethod // Method to generate the body of
+}
+
+// newTmplInternalCall bundles the arguments of the internal call sub-template.
+func newTmplInternalCall(typ string, kind string, method *tmplMethod) *tmplInternalCall {
+	return &tmplInternalCall{Type: typ, Kind: kind, Method: method}
+}
+
 // tmplSource is language to template mapping containing all the supported
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
-	LangGo:   tmplSourceGo,
-	LangJava: tmplSourceJava,
+	LangGo:         tmplSourceGo,
+	LangJava:       tmplSourceJava,
+	LangGoInternal: tmplSourceGoInternal,
 }
 
 // tmplSourceGo is the Go source template use to generate the contract binding
@@ -443,6 +457,95 @@ var (
 {{end}}
 `
 
+// tmplSourceGoInternal is the Go source template used to generate the contract
+// binding of a system contract invoked through the node's own EVM.
+const tmplSourceGoInternal = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+{{range $contract := .Contracts}}
+	// {{.Type}}ABI is the input ABI used to generate the binding from.
+	const {{.Type}}ABI = "{{.InputABI}}"
+
+	// parsed{{.Type}}ABI is {{.Type}}ABI parsed once, and shared by all the {{.Type}} bindings.
+	var parsed{{.Type}}ABI, parsed{{.Type}}ABIErr = abi.JSON(strings.NewReader({{.Type}}ABI))
+
+	// {{.Type}} is an auto generated Go binding around an Ethereum contract,
+	// operated through the node's own EVM.
+	type {{.Type}} struct {
+	  contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+	}
+
+	// New{{.Type}} creates a new instance of {{.Type}}, bound to a specific deployed contract.
+	func New{{.Type}}(address common.Address, handler bind.InternalEVMHandler) (*{{.Type}}, error) {
+	  if parsed{{.Type}}ABIErr != nil {
+	    return nil, parsed{{.Type}}ABIErr
+	  }
+	  return &{{.Type}}{contract: bind.NewBoundInternalContract(address, parsed{{.Type}}ABI, handler)}, nil
+	}
+
+	// Address returns the address the {{.Type}} binding is bound to.
+	func (_{{$contract.Type}} *{{$contract.Type}}) Address() common.Address {
+		return _{{$contract.Type}}.contract.Address()
+	}
+
+	{{range .Calls}}
+		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.Id}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(opts *bind.InternalCallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type}},{{end}}{{end}} error) {
+			{{- template "internalcall" (internalcall $contract.Type "Call" .)}}
+		}
+	{{end}}
+
+	{{range .Transacts}}
+		// {{.Normalized.Name}} is a state modifying call binding the contract method 0x{{printf "%x" .Original.Id}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(opts *bind.InternalCallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type}},{{end}}{{end}} error) {
+			{{- template "internalcall" (internalcall $contract.Type "Transact" .)}}
+		}
+	{{end}}
+{{end}}
+
+{{define "internalcall"}}
+	{{- with .Method}}
+	{{- if .Structured}}
+	ret := new(struct{
+		{{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}}
+		{{end}}
+	})
+	{{- else}}
+	{{- range $i, $_ := .Normalized.Outputs}}
+	ret{{$i}} := new({{bindtype .Type}})
+	{{- end}}
+	{{- end}}
+	{{- end}}
+	_, err := _{{.Type}}.contract.{{.Kind}}(opts, {{with .Method}}{{if .Structured}}ret{{else if eq (len .Normalized.Outputs) 0}}nil{{else if eq (len .Normalized.Outputs) 1}}ret0{{else}}&[]interface{}{ {{range $i, $_ := .Normalized.Outputs}}ret{{$i}}, {{end}} }{{end}}{{end}}, "{{.Method.Original.Name}}" {{range .Method.Normalized.Inputs}}, {{.Name}}{{end}})
+	return {{with .Method}}{{if .Structured}}*ret,{{else}}{{range $i, $_ := .Normalized.Outputs}}*ret{{$i}},{{end}}{{end}}{{end}} err
+{{- end}}
+`
+
 // tmplSourceJava is the Java source template use to generate the contract binding
 // based on.
 const tmplSourceJava = `
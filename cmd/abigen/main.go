@@ -39,7 +39,7 @@ var (
 
 	pkgFlag  = flag.String("pkg", "", "Package name to generate the binding into")
 	outFlag  = flag.String("out", "", "Output file for the generated binding (default = stdout)")
-	langFlag = flag.String("lang", "go", "Destination language for the bindings (go, java, objc)")
+	langFlag = flag.String("lang", "go", "Destination language for the bindings (go, go-internal, java, objc)")
 )
 
 func main() {
@@ -61,6 +61,8 @@ func main() {
 	switch *langFlag {
 	case "go":
 		lang = bind.LangGo
+	case "go-internal":
+		lang = bind.LangGoInternal
 	case "java":
 		lang = bind.LangJava
 	case "objc":
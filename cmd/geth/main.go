@@ -82,6 +82,9 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAccountLimitFlag,
+		utils.TxPoolCurrencySlotsFlag,
+		utils.TxPoolCurrencyLimitsFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
 		utils.LightServFlag,
@@ -111,6 +114,12 @@ var (
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
 		utils.MinerVerificationServiceUrlFlag,
+		utils.AttestationDeliveryFlag,
+		utils.AttestationWebhookSecretFlag,
+		utils.AttestationWebhookCertFlag,
+		utils.AttestationWebhookKeyFlag,
+		utils.AttestationWebhookCAFlag,
+		utils.AttestationFileFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -130,6 +139,8 @@ var (
 		utils.RPCVirtualHostsFlag,
 		utils.EthStatsURLFlag,
 		utils.MetricsEnabledFlag,
+		utils.GpoBlocksFlag,
+		utils.GpoPercentileFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.EWASMInterpreterFlag,
@@ -137,6 +148,10 @@ var (
 		configFileFlag,
 		utils.IstanbulRequestTimeoutFlag,
 		utils.IstanbulBlockPeriodFlag,
+		utils.IstanbulWALFlag,
+		utils.IstanbulBLSKeyFlag,
+		utils.IstanbulSentriesFlag,
+		utils.IstanbulProxiedValidatorFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -190,6 +205,8 @@ func init() {
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
+		// See randomnesscmd.go:
+		randomnessCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
@@ -0,0 +1,111 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	randomnessCommand = cli.Command{
+		Name:     "randomness",
+		Usage:    "Manage the validator randomness preimages",
+		Category: "ACCOUNT COMMANDS",
+		Description: `
+A validator commits to a random value in every block it proposes and has to
+reveal it in the next one. The preimages of these commitments are kept in a
+randomness database next to, but separate from, the chain data, encrypted with
+the validator key.
+
+If the preimage of the validator's last commitment is lost, the validator cannot
+propose blocks until the preimage is restored. Export the preimages before moving
+a validator to another host and import them there before it starts mining:
+
+    geth randomness export <dumpfile>
+    geth randomness import <datafile>
+
+Exported preimages stay encrypted and only the validator key that made the
+commitments can decrypt them.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the randomness preimages into an RLP stream",
+				ArgsUsage: "<dumpfile>",
+				Action:    utils.MigrateFlags(exportRandomness),
+				Category:  "ACCOUNT COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+				},
+				Description: `
+The export command exports the encrypted randomness preimages to an RLP encoded
+stream. If the file ends with .gz, the output is gzipped.`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import randomness preimages from an RLP stream",
+				ArgsUsage: "<datafile>",
+				Action:    utils.MigrateFlags(importRandomness),
+				Category:  "ACCOUNT COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+				},
+				Description: `
+The import command imports encrypted randomness preimages from an RLP encoded
+stream, as written by the export command. If the file ends with .gz, the input
+is gunzipped.`,
+			},
+		},
+	}
+)
+
+// exportRandomness dumps the randomness preimages to the specified file.
+func exportRandomness(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack := makeFullNode(ctx)
+	store := utils.MakeRandomnessStore(ctx, stack)
+	defer store.Close()
+
+	start := time.Now()
+	if err := utils.ExportRandomness(store, ctx.Args().First()); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
+// importRandomness adds the randomness preimages of the specified file to the store.
+func importRandomness(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack := makeFullNode(ctx)
+	store := utils.MakeRandomnessStore(ctx, stack)
+	defer store.Close()
+
+	start := time.Now()
+	if err := utils.ImportRandomness(store, ctx.Args().First()); err != nil {
+		utils.Fatalf("Import error: %v\n", err)
+	}
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
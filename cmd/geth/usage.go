@@ -128,6 +128,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolAccountLimitFlag,
+			utils.TxPoolCurrencySlotsFlag,
+			utils.TxPoolCurrencyLimitsFlag,
 		},
 	},
 	{
@@ -200,6 +203,24 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MinerVerificationServiceUrlFlag,
 		},
 	},
+	{
+		Name: "ATTESTATION",
+		Flags: []cli.Flag{
+			utils.AttestationDeliveryFlag,
+			utils.AttestationWebhookSecretFlag,
+			utils.AttestationWebhookCertFlag,
+			utils.AttestationWebhookKeyFlag,
+			utils.AttestationWebhookCAFlag,
+			utils.AttestationFileFlag,
+		},
+	},
+	{
+		Name: "GAS PRICE ORACLE",
+		Flags: []cli.Flag{
+			utils.GpoBlocksFlag,
+			utils.GpoPercentileFlag,
+		},
+	},
 	{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
@@ -248,6 +269,10 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.IstanbulRequestTimeoutFlag,
 			utils.IstanbulBlockPeriodFlag,
+			utils.IstanbulWALFlag,
+			utils.IstanbulBLSKeyFlag,
+			utils.IstanbulSentriesFlag,
+			utils.IstanbulProxiedValidatorFlag,
 		},
 	},
 }
@@ -310,3 +310,54 @@ func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
 	log.Info("Exported preimages", "file", fn)
 	return nil
 }
+
+// ImportRandomness imports a batch of exported randomness preimages into the
+// randomness store.
+func ImportRandomness(store *core.RandomnessStore, fn string) error {
+	log.Info("Importing randomness preimages", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	imported, err := store.Import(reader)
+	if err != nil {
+		return err
+	}
+	log.Info("Imported randomness preimages", "file", fn, "count", imported)
+	return nil
+}
+
+// ExportRandomness exports all randomness preimages of the randomness store into
+// the specified file. The preimages stay encrypted with the validator key.
+func ExportRandomness(store *core.RandomnessStore, fn string) error {
+	log.Info("Exporting randomness preimages", "file", fn)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	exported, err := store.Export(writer)
+	if err != nil {
+		return err
+	}
+	log.Info("Exported randomness preimages", "file", fn, "count", exported)
+	return nil
+}
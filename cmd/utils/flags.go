@@ -28,6 +28,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/abe"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
@@ -42,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/dashboard"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/les"
@@ -306,6 +308,20 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolAccountLimitFlag = cli.Uint64Flag{
+		Name:  "txpool.accountlimit",
+		Usage: "Maximum number of executable and non-executable transactions permitted per account",
+		Value: eth.DefaultConfig.TxPool.AccountLimit,
+	}
+	TxPoolCurrencySlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.currencyslots",
+		Usage: "Maximum number of transactions permitted per non-gold gas currency",
+		Value: eth.DefaultConfig.TxPool.CurrencySlots,
+	}
+	TxPoolCurrencyLimitsFlag = cli.StringFlag{
+		Name:  "txpool.currencylimits",
+		Usage: "Comma separated currency=limit pairs of gas currencies permitted a different number of transactions (<currency address>=<limit>)",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -396,7 +412,33 @@ var (
 	MinerVerificationServiceUrlFlag = cli.StringFlag{
 		Name:  "miner.verificationpool",
 		Usage: "URL to the verification service to be used by the miner to attest users' phone numbers",
-		Value: eth.DefaultConfig.MinerVerificationServiceUrl,
+		Value: eth.DefaultConfig.Attestation.URL,
+	}
+	// Attestation settings
+	AttestationDeliveryFlag = cli.StringFlag{
+		Name:  "attestation.delivery",
+		Usage: `Attestation delivery backend ("http", "webhook" or "file")`,
+		Value: eth.DefaultConfig.Attestation.Delivery,
+	}
+	AttestationWebhookSecretFlag = cli.StringFlag{
+		Name:  "attestation.webhooksecret",
+		Usage: "File containing the secret the attestation webhook requests are signed with",
+	}
+	AttestationWebhookCertFlag = cli.StringFlag{
+		Name:  "attestation.webhookcert",
+		Usage: "TLS client certificate file presented to the attestation webhook",
+	}
+	AttestationWebhookKeyFlag = cli.StringFlag{
+		Name:  "attestation.webhookkey",
+		Usage: "TLS client key file presented to the attestation webhook",
+	}
+	AttestationWebhookCAFlag = cli.StringFlag{
+		Name:  "attestation.webhookca",
+		Usage: "CA certificates file the attestation webhook is verified with (default = system CAs)",
+	}
+	AttestationFileFlag = cli.StringFlag{
+		Name:  "attestation.file",
+		Usage: "File the attestations are appended to by the file backend (default = stdout)",
 	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
@@ -562,6 +604,18 @@ var (
 		Value: ".",
 	}
 
+	// Gas price oracle settings
+	GpoBlocksFlag = cli.IntFlag{
+		Name:  "gpoblocks",
+		Usage: "Number of recent blocks to check for gas prices",
+		Value: eth.DefaultConfig.GPO.Blocks,
+	}
+	GpoPercentileFlag = cli.IntFlag{
+		Name:  "gpopercentile",
+		Usage: "Suggested gas price is the given percentile of a set of recent transaction gas prices, normalized across gas currencies",
+		Value: eth.DefaultConfig.GPO.Percentile,
+	}
+
 	WhisperEnabledFlag = cli.BoolFlag{
 		Name:  "shh",
 		Usage: "Enable Whisper",
@@ -642,6 +696,26 @@ var (
 		Usage: "Default minimum difference between two consecutive block's timestamps in seconds",
 		Value: eth.DefaultConfig.Istanbul.BlockPeriod,
 	}
+	IstanbulWALFlag = cli.StringFlag{
+		Name:  "istanbul.wal",
+		Usage: "Write-ahead log of signed consensus messages (relative to the data directory)",
+		Value: "istanbul.wal",
+	}
+	IstanbulBLSKeyFlag = cli.StringFlag{
+		Name:  "istanbul.blskey",
+		Usage: "BLS key to sign aggregated committed seals with, generated if missing (relative to the data directory)",
+		Value: "blskey",
+	}
+	IstanbulSentriesFlag = cli.StringFlag{
+		Name:  "istanbul.sentries",
+		Usage: "Comma separated enode URLs of the sentry nodes the validator exclusively connects to",
+		Value: "",
+	}
+	IstanbulProxiedValidatorFlag = cli.StringFlag{
+		Name:  "istanbul.proxiedvalidator",
+		Usage: "Enode URL of the validator this node is a sentry of, relaying its consensus messages",
+		Value: "",
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -982,6 +1056,14 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.GlobalIsSet(IstanbulSentriesFlag.Name) {
+		// A validator behind sentries only connects to them, which the consensus
+		// engine adds as validator peers.
+		cfg.MaxPeers = 0
+		cfg.NoDiscovery = true
+		cfg.DiscoveryV5 = false
+	}
+
 	if ctx.GlobalBool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1028,6 +1110,15 @@ func setDataDir(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
+	if ctx.GlobalIsSet(GpoBlocksFlag.Name) {
+		cfg.Blocks = ctx.GlobalInt(GpoBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(GpoPercentileFlag.Name) {
+		cfg.Percentile = ctx.GlobalInt(GpoPercentileFlag.Name)
+	}
+}
+
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLocalsFlag.Name) {
 		locals := strings.Split(ctx.GlobalString(TxPoolLocalsFlag.Name), ",")
@@ -1069,6 +1160,26 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolAccountLimitFlag.Name) {
+		cfg.AccountLimit = ctx.GlobalUint64(TxPoolAccountLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolCurrencySlotsFlag.Name) {
+		cfg.CurrencySlots = ctx.GlobalUint64(TxPoolCurrencySlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolCurrencyLimitsFlag.Name) {
+		cfg.CurrencyLimits = make(map[common.Address]uint64)
+		for _, entry := range strings.Split(ctx.GlobalString(TxPoolCurrencyLimitsFlag.Name), ",") {
+			parts := strings.Split(strings.TrimSpace(entry), "=")
+			if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+				Fatalf("Invalid currency limit entry in --txpool.currencylimits: %s", entry)
+			}
+			limit, err := strconv.ParseUint(parts[1], 0, 64)
+			if err != nil {
+				Fatalf("Invalid currency limit %s: %v", parts[1], err)
+			}
+			cfg.CurrencyLimits[common.HexToAddress(parts[0])] = limit
+		}
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1115,6 +1226,34 @@ func setWhitelist(ctx *cli.Context, cfg *eth.Config) {
 	}
 }
 
+func setAttestation(ctx *cli.Context, cfg *abe.Config) {
+	if ctx.GlobalIsSet(AttestationDeliveryFlag.Name) {
+		cfg.Delivery = ctx.GlobalString(AttestationDeliveryFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerVerificationServiceUrlFlag.Name) {
+		cfg.URL = ctx.GlobalString(MinerVerificationServiceUrlFlag.Name)
+	}
+	if ctx.GlobalIsSet(AttestationWebhookSecretFlag.Name) {
+		secret, err := ioutil.ReadFile(ctx.GlobalString(AttestationWebhookSecretFlag.Name))
+		if err != nil {
+			Fatalf("Failed to read attestation webhook secret file: %v", err)
+		}
+		cfg.WebhookSecret = strings.TrimSpace(string(secret))
+	}
+	if ctx.GlobalIsSet(AttestationWebhookCertFlag.Name) {
+		cfg.WebhookCertFile = ctx.GlobalString(AttestationWebhookCertFlag.Name)
+	}
+	if ctx.GlobalIsSet(AttestationWebhookKeyFlag.Name) {
+		cfg.WebhookKeyFile = ctx.GlobalString(AttestationWebhookKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(AttestationWebhookCAFlag.Name) {
+		cfg.WebhookCAFile = ctx.GlobalString(AttestationWebhookCAFlag.Name)
+	}
+	if ctx.GlobalIsSet(AttestationFileFlag.Name) {
+		cfg.File = ctx.GlobalString(AttestationFileFlag.Name)
+	}
+}
+
 func setIstanbul(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(IstanbulRequestTimeoutFlag.Name) {
 		cfg.Istanbul.RequestTimeout = ctx.GlobalUint64(IstanbulRequestTimeoutFlag.Name)
@@ -1122,6 +1261,28 @@ func setIstanbul(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(IstanbulBlockPeriodFlag.Name) {
 		cfg.Istanbul.BlockPeriod = ctx.GlobalUint64(IstanbulBlockPeriodFlag.Name)
 	}
+	if ctx.GlobalIsSet(IstanbulWALFlag.Name) {
+		cfg.Istanbul.WAL = ctx.GlobalString(IstanbulWALFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulBLSKeyFlag.Name) {
+		cfg.Istanbul.BLSKey = ctx.GlobalString(IstanbulBLSKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulSentriesFlag.Name) {
+		cfg.Istanbul.Sentries = nil
+		for _, url := range strings.Split(ctx.GlobalString(IstanbulSentriesFlag.Name), ",") {
+			if _, err := enode.ParseV4(url); err != nil {
+				Fatalf("Option %q: invalid enode %q: %v", IstanbulSentriesFlag.Name, url, err)
+			}
+			cfg.Istanbul.Sentries = append(cfg.Istanbul.Sentries, url)
+		}
+	}
+	if ctx.GlobalIsSet(IstanbulProxiedValidatorFlag.Name) {
+		url := ctx.GlobalString(IstanbulProxiedValidatorFlag.Name)
+		if _, err := enode.ParseV4(url); err != nil {
+			Fatalf("Option %q: invalid enode %q: %v", IstanbulProxiedValidatorFlag.Name, url, err)
+		}
+		cfg.Istanbul.ProxiedValidator = url
+	}
 }
 
 // checkExclusive verifies that only a single isntance of the provided flags was
@@ -1186,10 +1347,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	setEtherbase(ctx, ks, cfg)
+	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
 	setWhitelist(ctx, cfg)
 	setIstanbul(ctx, cfg)
+	setAttestation(ctx, &cfg.Attestation)
 
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1252,9 +1415,6 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.MinerNoverify = ctx.Bool(MinerNoVerfiyFlag.Name)
 	}
-	if ctx.GlobalIsSet(MinerVerificationServiceUrlFlag.Name) {
-		cfg.MinerVerificationServiceUrl = ctx.GlobalString(MinerVerificationServiceUrlFlag.Name)
-	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
@@ -1444,6 +1604,15 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	return chainDb
 }
 
+// MakeRandomnessStore opens the store holding the validator's randomness preimages.
+func MakeRandomnessStore(ctx *cli.Context, stack *node.Node) *core.RandomnessStore {
+	db, err := stack.OpenDatabase("randomness", 0, 0)
+	if err != nil {
+		Fatalf("Could not open randomness database: %v", err)
+	}
+	return core.NewRandomnessStore(db)
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -136,6 +136,10 @@ type Handler interface {
 
 	// SetBroadcaster sets the broadcaster to send message to peers
 	SetBroadcaster(Broadcaster)
+
+	// ConnectSentryPeers connects a validator behind sentries to its sentries,
+	// and a sentry to its validator, once the p2p server is running
+	ConnectSentryPeers()
 }
 
 // PoW is a consensus engine based on proof-of-work.
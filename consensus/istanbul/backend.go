@@ -50,9 +50,10 @@ type Backend interface {
 	// Gossip sends a message to all validators (exclude self)
 	Gossip(valSet ValidatorSet, payload []byte, msgCode uint64, ignoreCache bool) error
 
-	// Commit delivers an approved proposal to backend.
+	// Commit delivers an approved proposal to backend, along with the committed
+	// seals of the given committers.
 	// The delivered proposal will be put into blockchain.
-	Commit(proposal Proposal, seals [][]byte) error
+	Commit(proposal Proposal, committers []common.Address, seals [][]byte) error
 
 	// Verify verifies the proposal. If a consensus.ErrFutureBlock error is returned,
 	// the time difference of the proposal and current time is also returned.
@@ -61,10 +62,20 @@ type Backend interface {
 	// Sign signs input data with the backend's private key
 	Sign([]byte) ([]byte, error)
 
+	// SignCommittedSeal signs the committed seal of the proposal with the given
+	// number, sealed by the given validator set, with the key committed seals are
+	// verified against, which is the BLS key if the proposal's committed seals are
+	// aggregated
+	SignCommittedSeal(seal []byte, number *big.Int, valSet ValidatorSet) ([]byte, error)
+
 	// CheckSignature verifies the signature by checking if it's signed by
 	// the given validator
 	CheckSignature(data []byte, addr common.Address, sig []byte) error
 
+	// CheckCommittedSeal verifies that the committed seal of the proposal with
+	// the given number is signed by the given validator
+	CheckCommittedSeal(seal []byte, validator Validator, committedSeal []byte, number *big.Int) error
+
 	// LastProposal retrieves latest committed proposal and the address of proposer
 	LastProposal() (Proposal, common.Address)
 
@@ -80,6 +91,9 @@ type Backend interface {
 	// HasBadProposal returns whether the block with the hash is a bad block
 	HasBadProposal(hash common.Hash) bool
 
+	// ReportEquivocation records the evidence of a validator signing conflicting messages
+	ReportEquivocation(evidence *Equivocation)
+
 	// AddValidatorPeer adds a validator peer
 	AddValidatorPeer(enodeURL string)
 
@@ -94,4 +108,7 @@ type Backend interface {
 
 	// Authorize injects a private key into the consensus engine.
 	Authorize(address common.Address, signFn SignerFn)
+
+	// Clock returns the clock the timers of the consensus run on
+	Clock() Clock
 }
@@ -17,6 +17,8 @@
 package backend
 
 import (
+	"crypto/ecdsa"
+	crand "crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -25,6 +27,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -33,15 +37,27 @@ import (
 //
 // define the istanbul announce message
 
+type encryptedEnodeURL struct {
+	DestAddress       common.Address
+	EncryptedEnodeURL []byte
+}
+
+func (ee *encryptedEnodeURL) String() string {
+	return fmt.Sprintf("{DestAddress: %s, EncryptedEnodeURL: %v}", ee.DestAddress.String(), hex.EncodeToString(ee.EncryptedEnodeURL))
+}
+
+// The enode URLs of the validator, or of its sentries if it is behind sentries,
+// are encrypted with the public key of each of the registered validators, so
+// that only they learn their IP addresses.
 type announceMessage struct {
-	Address   common.Address
-	EnodeURL  string
-	View      *istanbul.View
-	Signature []byte
+	Address            common.Address
+	EncryptedEnodeURLs []*encryptedEnodeURL
+	View               *istanbul.View
+	Signature          []byte
 }
 
 func (am *announceMessage) String() string {
-	return fmt.Sprintf("{Address: %s, View: %v, EnodeURL: %v, Signature: %v}", am.Address.String(), am.View, am.EnodeURL, hex.EncodeToString(am.Signature))
+	return fmt.Sprintf("{Address: %s, View: %v, EncryptedEnodeURLs: %v, Signature: %v}", am.Address.String(), am.View, am.EncryptedEnodeURLs, hex.EncodeToString(am.Signature))
 }
 
 // ==============================================
@@ -50,22 +66,22 @@ func (am *announceMessage) String() string {
 
 // EncodeRLP serializes am into the Ethereum RLP format.
 func (am *announceMessage) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{am.Address, am.EnodeURL, am.View, am.Signature})
+	return rlp.Encode(w, []interface{}{am.Address, am.EncryptedEnodeURLs, am.View, am.Signature})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the am fields from a RLP stream.
 func (am *announceMessage) DecodeRLP(s *rlp.Stream) error {
 	var msg struct {
-		Address   common.Address
-		EnodeURL  string
-		View      *istanbul.View
-		Signature []byte
+		Address            common.Address
+		EncryptedEnodeURLs []*encryptedEnodeURL
+		View               *istanbul.View
+		Signature          []byte
 	}
 
 	if err := s.Decode(&msg); err != nil {
 		return err
 	}
-	am.Address, am.EnodeURL, am.View, am.Signature = msg.Address, msg.EnodeURL, msg.View, msg.Signature
+	am.Address, am.EncryptedEnodeURLs, am.View, am.Signature = msg.Address, msg.EncryptedEnodeURLs, msg.View, msg.Signature
 	return nil
 }
 
@@ -82,13 +98,17 @@ func (am *announceMessage) Payload() ([]byte, error) {
 	return rlp.EncodeToBytes(am)
 }
 
-func (am *announceMessage) Sign(signingFn func(data []byte) ([]byte, error)) error {
+func (am *announceMessage) payloadNoSig() ([]byte, error) {
 	// Construct and encode a message with no signature
-	var payloadNoSig []byte
-	payloadNoSig, err := rlp.EncodeToBytes(&announceMessage{Address: am.Address,
-		EnodeURL:  am.EnodeURL,
-		View:      am.View,
-		Signature: []byte{}})
+	return rlp.EncodeToBytes(&announceMessage{
+		Address:            am.Address,
+		EncryptedEnodeURLs: am.EncryptedEnodeURLs,
+		View:               am.View,
+		Signature:          []byte{}})
+}
+
+func (am *announceMessage) Sign(signingFn func(data []byte) ([]byte, error)) error {
+	payloadNoSig, err := am.payloadNoSig()
 	if err != nil {
 		return err
 	}
@@ -96,24 +116,22 @@ func (am *announceMessage) Sign(signingFn func(data []byte) ([]byte, error)) err
 	return err
 }
 
-func (am *announceMessage) VerifySig() error {
-	// Construct and encode a message with no signature
-	var payloadNoSig []byte
-	payloadNoSig, err := rlp.EncodeToBytes(&announceMessage{
-		Address:   am.Address,
-		EnodeURL:  am.EnodeURL,
-		View:      am.View,
-		Signature: []byte{}})
+// PublicKey recovers the public key the message is signed with.
+func (am *announceMessage) PublicKey() (*ecdsa.PublicKey, error) {
+	payloadNoSig, err := am.payloadNoSig()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return crypto.SigToPub(crypto.Keccak256(payloadNoSig), am.Signature)
+}
 
-	sigAddr, err := istanbul.GetSignatureAddress(payloadNoSig, am.Signature)
+func (am *announceMessage) VerifySig() error {
+	pubKey, err := am.PublicKey()
 	if err != nil {
 		return err
 	}
 
-	if sigAddr != am.Address {
+	if sigAddr := crypto.PubkeyToAddress(*pubKey); sigAddr != am.Address {
 		log.Error("Address in the message is different than the address that signed it",
 			"sigAddr", sigAddr.Hex(),
 			"msg.Address", am.Address.Hex())
@@ -145,19 +163,38 @@ func (sb *Backend) sendAnnounceMsgs() {
 	}
 }
 
+// announcedEnodeURLs returns the enode URLs the validator announces: the ones of
+// all of its sentries if it is behind sentries, or its own.
+func (sb *Backend) announcedEnodeURLs() []string {
+	var enodeURLs []string
+	if sb.isProxied() {
+		for _, sentry := range sb.sentries {
+			enodeURLs = append(enodeURLs, sentry.String())
+		}
+	} else if enode := sb.Enode(); enode != nil {
+		enodeURLs = append(enodeURLs, enode.String())
+	}
+	return enodeURLs
+}
+
 func (sb *Backend) sendIstAnnounce() error {
-	enode := sb.Enode()
-	if enode == nil {
+	enodeURLs := sb.announcedEnodeURLs()
+	if len(enodeURLs) == 0 {
 		sb.logger.Error("Enode is nil in sendIstAnnounce")
 		return nil
 	}
 
-	enodeUrl := enode.String()
 	view := sb.core.CurrentView()
 
+	encryptedEnodeURLs, err := sb.encryptEnodeURLs(enodeURLs)
+	if err != nil {
+		sb.logger.Error("Error in encrypting the enode URL for the registered validators", "err", err)
+		return err
+	}
+
 	msg := &announceMessage{Address: sb.Address(),
-		EnodeURL: enodeUrl,
-		View:     view}
+		EncryptedEnodeURLs: encryptedEnodeURLs,
+		View:               view}
 
 	// Sign the announce message
 	if err := msg.Sign(sb.Sign); err != nil {
@@ -189,6 +226,10 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 		sb.logger.Error("Error in decoding received Istanbul Announce message", "err", err, "payload", hex.EncodeToString(payload))
 		return err
 	}
+	if msg.View == nil || msg.View.Round == nil || msg.View.Sequence == nil {
+		sb.logger.Error("Received an Istanbul Announce message without a view", "payload", hex.EncodeToString(payload))
+		return errDecodeFailed
+	}
 
 	// Verify message signature
 	if err := msg.VerifySig(); err != nil {
@@ -203,20 +244,8 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 	}
 
 	// If the message is not within the registered validator set, then ignore it
-	regVals, err := sb.retrieveRegisteredValidators()
-
-	// The validator contract may not be deployed yet.
-	// Even if it is deployed, it may not have any registered validators yet.
-	if err == errValidatorsContractNotRegistered || len(regVals) == 0 {
-		sb.logger.Trace("Can't retrieve the registered validators.  Only allowing the initial validator set to send announce messages", "err", err, "regVals", regVals)
-		block := sb.currentBlock()
-		valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
-
-		regVals = make(map[common.Address]bool)
-		for _, val := range valSet.List() {
-			regVals[val.Address()] = true
-		}
-	} else if err != nil {
+	regVals, err := sb.retrieveAnnounceValidators()
+	if err != nil {
 		sb.logger.Error("Error in retrieving the registered validators", "err", err)
 		return err
 	}
@@ -226,42 +255,163 @@ func (sb *Backend) handleIstAnnounce(payload []byte) error {
 		return errUnauthorizedAnnounceMessage
 	}
 
-	// Save in the valEnodeTable if mining
-	if sb.coreStarted {
-		block := sb.currentBlock()
-		valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
+	// Remember the public key of the validator to encrypt our enode URL for it
+	pubKey, _ := msg.PublicKey()
+	sb.valPublicKeysMu.Lock()
+	sb.valPublicKeys[msg.Address] = pubKey
+	sb.valPublicKeysMu.Unlock()
 
-		newValEnode := &validatorEnode{enodeURL: msg.EnodeURL, view: msg.View}
-		if err := sb.valEnodeTable.upsert(msg.Address, newValEnode, valSet, sb.Address()); err != nil {
-			sb.logger.Error("Error in upserting a valenode entry", "AnnounceMsg", msg, "error", err)
-			return err
+	// Save in the valEnodeTable if mining and the enode URL is encrypted for us
+	if sb.coreStarted {
+		enodeURLs, err := sb.decryptEnodeURLs(msg)
+		if err != nil {
+			sb.logger.Warn("Error in decrypting the enode URLs of an Istanbul Announce message", "AnnounceMsg", msg, "err", err)
+		} else if len(enodeURLs) > 0 {
+			if err := sb.upsertValEnode(msg.Address, enodeURLs, msg.View); err != nil {
+				sb.logger.Error("Error in upserting a valenode entry", "AnnounceMsg", msg, "error", err)
+				return err
+			}
+			// Sentries can't decrypt the enode URLs, so they are shared with them
+			if sb.isProxied() {
+				sb.shareValEnode(msg.Address, enodeURLs, msg.View)
+			}
 		}
 	}
 
-	// If we gossiped this address/enodeURL within the last 60 seconds, then don't regossip
-	if lastGossipTs, ok := sb.lastAnnounceGossiped[msg.Address]; ok {
-		if lastGossipTs.enodeURL == msg.EnodeURL && time.Since(lastGossipTs.timestamp) < time.Minute {
-			sb.logger.Trace("Already regossiped the msg within the last minute, so not regossiping.", "AnnounceMsg", msg)
-			return nil
-		}
+	if !sb.markAnnounceGossiped(msg.Address, msg.View) {
+		sb.logger.Trace("Already regossiped an announce msg of this view, so not regossiping.", "AnnounceMsg", msg)
+		return nil
 	}
 
 	sb.logger.Trace("Regossiping the istanbul announce message", "AnnounceMsg", msg)
 	sb.Gossip(nil, payload, istanbulAnnounceMsg, true)
 
-	sb.lastAnnounceGossiped[msg.Address] = &AnnounceGossipTimestamp{enodeURL: msg.EnodeURL, timestamp: time.Now()}
-
 	// prune non registered validator entries in the valEnodeTable, reverseValEnodeTable, and lastAnnounceGossiped tables about 5% of the times that an announce msg is handled
 	if (rand.Int() % 100) <= 5 {
+		sb.lastAnnounceGossipedMu.Lock()
 		for remoteAddress := range sb.lastAnnounceGossiped {
 			if !regVals[remoteAddress] {
 				log.Trace("Deleting entry from the lastAnnounceGossiped table", "address", remoteAddress, "gossip timestamp", sb.lastAnnounceGossiped[remoteAddress])
 				delete(sb.lastAnnounceGossiped, remoteAddress)
 			}
 		}
+		sb.lastAnnounceGossipedMu.Unlock()
 
 		sb.valEnodeTable.pruneEntries(regVals)
+
+		sb.valPublicKeysMu.Lock()
+		for remoteAddress := range sb.valPublicKeys {
+			if !regVals[remoteAddress] {
+				delete(sb.valPublicKeys, remoteAddress)
+			}
+		}
+		sb.valPublicKeysMu.Unlock()
 	}
 
 	return nil
 }
+
+// markAnnounceGossiped returns whether the announce message of the given address
+// and view is to be regossiped, and if so records it as gossiped. Announce
+// messages of a later view are regossiped right away, the ones of the same view
+// at most once a minute, and the ones of an earlier view never.
+func (sb *Backend) markAnnounceGossiped(address common.Address, view *istanbul.View) bool {
+	sb.lastAnnounceGossipedMu.Lock()
+	defer sb.lastAnnounceGossipedMu.Unlock()
+
+	if last, ok := sb.lastAnnounceGossiped[address]; ok {
+		if cmp := view.Cmp(last.view); cmp < 0 || (cmp == 0 && time.Since(last.timestamp) < time.Minute) {
+			return false
+		}
+	}
+	sb.lastAnnounceGossiped[address] = &AnnounceGossipTimestamp{view: view, timestamp: time.Now()}
+	return true
+}
+
+// retrieveAnnounceValidators returns the validators allowed to send announce
+// messages: the registered validators, or the current validator set if there
+// are none yet.
+func (sb *Backend) retrieveAnnounceValidators() (map[common.Address]bool, error) {
+	regVals, err := sb.retrieveRegisteredValidators()
+
+	// The validator contract may not be deployed yet.
+	// Even if it is deployed, it may not have any registered validators yet.
+	if err == errValidatorsContractNotRegistered || len(regVals) == 0 {
+		sb.logger.Trace("Can't retrieve the registered validators.  Only allowing the initial validator set to send announce messages", "err", err, "regVals", regVals)
+		block := sb.currentBlock()
+		valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
+
+		regVals = make(map[common.Address]bool)
+		for _, val := range valSet.List() {
+			regVals[val.Address()] = true
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return regVals, nil
+}
+
+// encryptEnodeURLs encrypts the enode URLs for each of the registered validators
+// whose public key is known from their own announce messages. A validator
+// learns the public keys of the others from their first announce messages, so
+// its enode URLs reach them from its next announce message on.
+func (sb *Backend) encryptEnodeURLs(enodeURLs []string) ([]*encryptedEnodeURL, error) {
+	regVals, err := sb.retrieveAnnounceValidators()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := rlp.EncodeToBytes(enodeURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	sb.valPublicKeysMu.RLock()
+	defer sb.valPublicKeysMu.RUnlock()
+
+	var encryptedEnodeURLs []*encryptedEnodeURL
+	for address := range regVals {
+		pubKey := sb.valPublicKeys[address]
+		if pubKey == nil || address == sb.Address() {
+			continue
+		}
+		encrypted, err := ecies.Encrypt(crand.Reader, ecies.ImportECDSAPublic(pubKey), plaintext, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		encryptedEnodeURLs = append(encryptedEnodeURLs, &encryptedEnodeURL{DestAddress: address, EncryptedEnodeURL: encrypted})
+	}
+	return encryptedEnodeURLs, nil
+}
+
+// decryptEnodeURLs decrypts the enode URLs encrypted for this validator in the
+// message, returning none if there are none. The node key is used to decrypt,
+// which is the key of the validator.
+func (sb *Backend) decryptEnodeURLs(msg *announceMessage) ([]string, error) {
+	for _, encrypted := range msg.EncryptedEnodeURLs {
+		if encrypted.DestAddress != sb.Address() {
+			continue
+		}
+		if sb.nodeKey == nil || crypto.PubkeyToAddress(sb.nodeKey.PublicKey) != sb.Address() {
+			return nil, errInvalidNodeKey
+		}
+		plaintext, err := ecies.ImportECDSA(sb.nodeKey).Decrypt(encrypted.EncryptedEnodeURL, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var enodeURLs []string
+		if err := rlp.DecodeBytes(plaintext, &enodeURLs); err != nil {
+			return nil, err
+		}
+		return enodeURLs, nil
+	}
+	return nil, nil
+}
+
+// upsertValEnode saves the enode URLs of the validator in the valEnodeTable.
+func (sb *Backend) upsertValEnode(address common.Address, enodeURLs []string, view *istanbul.View) error {
+	block := sb.currentBlock()
+	valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
+
+	newValEnode := &validatorEnode{enodeURLs: enodeURLs, view: view}
+	return sb.valEnodeTable.upsert(address, newValEnode, valSet, sb.validatorAddress())
+}
@@ -0,0 +1,183 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/rand"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestAnnounceMessage(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	msg := &announceMessage{
+		Address:            crypto.PubkeyToAddress(key.PublicKey),
+		EncryptedEnodeURLs: []*encryptedEnodeURL{{DestAddress: common.HexToAddress("0x1"), EncryptedEnodeURL: []byte("encrypted")}},
+		View:               &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)},
+	}
+	if err := msg.Sign(func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }); err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	payload, err := msg.Payload()
+	if err != nil {
+		t.Fatalf("failed to encode message: %v", err)
+	}
+
+	decoded := new(announceMessage)
+	if err := decoded.FromPayload(payload); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	if err := decoded.VerifySig(); err != nil {
+		t.Errorf("failed to verify signature: %v", err)
+	}
+	if pubKey, err := decoded.PublicKey(); err != nil || crypto.PubkeyToAddress(*pubKey) != msg.Address {
+		t.Errorf("public key mismatch: have %v, err %v", pubKey, err)
+	}
+	if len(decoded.EncryptedEnodeURLs) != 1 || decoded.EncryptedEnodeURLs[0].DestAddress != common.HexToAddress("0x1") {
+		t.Errorf("encrypted enode URLs mismatch: have %v", decoded.EncryptedEnodeURLs)
+	}
+
+	// The signature covers the encrypted enode URLs
+	decoded.EncryptedEnodeURLs[0].EncryptedEnodeURL = []byte("tampered")
+	if err := decoded.VerifySig(); err == nil {
+		t.Errorf("expected tampered message to fail verification")
+	}
+}
+
+func TestDecryptEnodeURLs(t *testing.T) {
+	_, b := newBlockChain(1, true)
+	enodeURLs := []string{
+		"enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303",
+		"enode://3f1d12044546b76342d59d4a05532c14b85aa669704bfe1f864fe079415aa2c02d743e03218e57a33fb94523adb54032871a6c51b2cc5514cb7c7e35b3ed0a99@13.93.211.84:30303",
+	}
+
+	encrypt := func(dest common.Address) *encryptedEnodeURL {
+		plaintext, _ := rlp.EncodeToBytes(enodeURLs)
+		encrypted, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(&b.nodeKey.PublicKey), plaintext, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to encrypt enode URLs: %v", err)
+		}
+		return &encryptedEnodeURL{DestAddress: dest, EncryptedEnodeURL: encrypted}
+	}
+	other := common.HexToAddress("0x1")
+
+	msg := &announceMessage{EncryptedEnodeURLs: []*encryptedEnodeURL{encrypt(other), encrypt(b.Address())}}
+	if decrypted, err := b.decryptEnodeURLs(msg); err != nil || !reflect.DeepEqual(decrypted, enodeURLs) {
+		t.Errorf("enode URLs mismatch: have %q, err %v, want %q", decrypted, err, enodeURLs)
+	}
+
+	// Enode URLs encrypted for other validators are not known
+	msg = &announceMessage{EncryptedEnodeURLs: []*encryptedEnodeURL{encrypt(other)}}
+	if decrypted, err := b.decryptEnodeURLs(msg); err != nil || len(decrypted) != 0 {
+		t.Errorf("enode URLs mismatch: have %q, err %v, want none", decrypted, err)
+	}
+
+	// The enode URLs are only encrypted for the other validators with known keys
+	b.valPublicKeys[b.Address()] = &b.nodeKey.PublicKey
+	if encrypted, err := b.encryptEnodeURLs(enodeURLs); err != nil || len(encrypted) != 0 {
+		t.Errorf("encrypted enode URLs mismatch: have %v, err %v, want none", encrypted, err)
+	}
+
+	b.nodeKey, _ = crypto.GenerateKey()
+	msg = &announceMessage{EncryptedEnodeURLs: []*encryptedEnodeURL{encrypt(b.Address())}}
+	if _, err := b.decryptEnodeURLs(msg); err != errInvalidNodeKey {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidNodeKey)
+	}
+}
+
+func TestValEnodeTableUpsert(t *testing.T) {
+	var added, removed []string
+	vet := newValidatorEnodeTable(
+		func(enodeURL string) { added = append(added, enodeURL) },
+		func(enodeURL string) { removed = append(removed, enodeURL) },
+	)
+	local, remote := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	valSet := validator.NewSet([]common.Address{local, remote}, istanbul.RoundRobin)
+	view := func(sequence int64) *istanbul.View {
+		return &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(sequence)}
+	}
+
+	// All the sentries of a validator are connected to
+	if err := vet.upsert(remote, &validatorEnode{enodeURLs: []string{"a", "b"}, view: view(1)}, valSet, local); err != nil {
+		t.Fatalf("failed to insert entry: %v", err)
+	}
+	if !reflect.DeepEqual(added, []string{"a", "b"}) {
+		t.Errorf("added peers mismatch: have %v, want %v", added, []string{"a", "b"})
+	}
+	if address, _ := vet.getUsingEnodeURL("b"); address != remote {
+		t.Errorf("address mismatch: have %v, want %v", address, remote)
+	}
+
+	// Sentries no longer announced are disconnected from
+	if err := vet.upsert(remote, &validatorEnode{enodeURLs: []string{"b", "c"}, view: view(2)}, valSet, local); err != nil {
+		t.Fatalf("failed to update entry: %v", err)
+	}
+	if !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Errorf("removed peers mismatch: have %v, want %v", removed, []string{"a"})
+	}
+	if address, _ := vet.getUsingEnodeURL("a"); address != common.ZeroAddress {
+		t.Errorf("address of removed enode URL mismatch: have %v, want none", address)
+	}
+	if err := vet.upsert(remote, &validatorEnode{enodeURLs: []string{"d"}, view: view(1)}, valSet, local); err != errOldAnnounceMessage {
+		t.Errorf("error mismatch: have %v, want %v", err, errOldAnnounceMessage)
+	}
+
+	vet.pruneEntries(map[common.Address]bool{local: true})
+	if address, _ := vet.getUsingEnodeURL("c"); address != common.ZeroAddress {
+		t.Errorf("address of pruned enode URL mismatch: have %v, want none", address)
+	}
+}
+
+func TestMarkAnnounceGossiped(t *testing.T) {
+	b := New(istanbul.DefaultConfig, ethdb.NewMemDatabase(), nil).(*Backend)
+	address, other := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	view := func(sequence int64) *istanbul.View {
+		return &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(sequence)}
+	}
+
+	for i, test := range []struct {
+		address common.Address
+		view    *istanbul.View
+		want    bool
+	}{
+		{address, view(2), true},
+		{address, view(2), false}, // same view within a minute
+		{address, view(1), false}, // earlier view
+		{address, view(3), true},  // later view
+		{other, view(1), true},
+	} {
+		if have := b.markAnnounceGossiped(test.address, test.view); have != test.want {
+			t.Errorf("test %d: regossip mismatch: have %v, want %v", i, have, test.want)
+		}
+	}
+
+	// Announce messages of the same view are regossiped after a minute
+	b.lastAnnounceGossiped[address].timestamp = time.Now().Add(-time.Minute)
+	if !b.markAnnounceGossiped(address, view(3)) {
+		t.Errorf("announce message of the same view not regossiped after a minute")
+	}
+}
@@ -17,8 +17,13 @@
 package backend
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -86,3 +91,124 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 	}
 	return snap.validators(), nil
 }
+
+// GetValidatorUptime retrieves how many blocks of the given epoch each validator
+// committed to and missed. The uptime of the current epoch covers the blocks up to
+// the returned last block.
+func (api *API) GetValidatorUptime(epoch uint64) (*EpochUptime, error) {
+	uptime, err := loadEpochUptime(api.istanbul.db, epoch)
+	if err != nil {
+		return nil, errUnknownEpochUptime
+	}
+	return uptime, nil
+}
+
+// EquivocationResult is the evidence of a validator signing conflicting messages
+// for the same view.
+type EquivocationResult struct {
+	Hash     common.Hash     `json:"hash"`
+	Signer   common.Address  `json:"signer"`
+	Code     uint64          `json:"code"`
+	Sequence *hexutil.Big    `json:"sequence"`
+	Round    *hexutil.Big    `json:"round"`
+	Messages []hexutil.Bytes `json:"messages"`
+}
+
+// GetEquivocations retrieves the evidence of the validators that signed
+// conflicting messages, ordered by the view they were signed in.
+func (api *API) GetEquivocations() ([]*EquivocationResult, error) {
+	evidences, err := loadEquivocations(api.istanbul.db)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*EquivocationResult, 0, len(evidences))
+	for _, evidence := range evidences {
+		messages := make([]hexutil.Bytes, len(evidence.Messages))
+		for i, msg := range evidence.Messages {
+			messages[i] = msg
+		}
+		results = append(results, &EquivocationResult{
+			Hash:     evidence.Hash(),
+			Signer:   evidence.Signer,
+			Code:     evidence.Code,
+			Sequence: (*hexutil.Big)(evidence.View.Sequence),
+			Round:    (*hexutil.Big)(evidence.View.Round),
+			Messages: messages,
+		})
+	}
+	return results, nil
+}
+
+// ExportEquivocation returns the RLP encoded evidence with the given hash, to be
+// submitted to the slashing contract.
+func (api *API) ExportEquivocation(hash common.Hash) (hexutil.Bytes, error) {
+	if _, err := loadEquivocation(api.istanbul.db, hash); err != nil {
+		return nil, errUnknownEquivocation
+	}
+	return api.istanbul.db.Get(equivocationKey(hash))
+}
+
+// BLSPublicKeyResult is the BLS public key of the local validator, together with
+// the proof of possession of its private key it has to be registered with.
+type BLSPublicKeyResult struct {
+	PublicKey         hexutil.Bytes `json:"publicKey"`
+	ProofOfPossession hexutil.Bytes `json:"proofOfPossession"`
+}
+
+// GetBLSPublicKey returns the BLS public key the local validator signs aggregated
+// committed seals with, for registering it in the Validators contract.
+func (api *API) GetBLSPublicKey() (*BLSPublicKeyResult, error) {
+	key, err := api.istanbul.getBLSKey()
+	if err != nil {
+		return nil, err
+	}
+	return &BLSPublicKeyResult{
+		PublicKey:         key.PublicKey().Marshal(),
+		ProofOfPossession: key.ProvePossession().Marshal(),
+	}, nil
+}
+
+// statusEventChanSize is the size of the channel of a status event subscription,
+// so that the consensus isn't held up by the subscriber.
+const statusEventChanSize = 100
+
+// Status returns the current view and state of the consensus, with the messages
+// received from each validator in it.
+func (api *API) Status() (*istanbulCore.Status, error) {
+	status := api.istanbul.core.Status()
+	if status == nil {
+		return nil, istanbul.ErrStoppedEngine
+	}
+	return status, nil
+}
+
+// StatusEvents creates a subscription that fires each time the consensus moves to
+// a new round, sequence or state.
+func (api *API) StatusEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan istanbulCore.StatusEvent, statusEventChanSize)
+		eventsSub := api.istanbul.core.SubscribeStatusEvents(events)
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				eventsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				eventsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
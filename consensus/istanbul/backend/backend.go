@@ -17,13 +17,18 @@
 package backend
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
 	"errors"
 	"math/big"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
@@ -32,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bls"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -51,16 +57,20 @@ var (
 
 // Entries for the recent announce messages
 type AnnounceGossipTimestamp struct {
-	enodeURL  string
+	view      *istanbul.View // View of the last regossiped announce message
 	timestamp time.Time
 }
 
-// New creates an Ethereum backend for Istanbul core engine.
-func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
+// New creates an Ethereum backend for Istanbul core engine. The node key is used
+// to decrypt the enode URLs of the other validators, which are encrypted for
+// the key of this validator.
+func New(config *istanbul.Config, db ethdb.Database, nodeKey *ecdsa.PrivateKey) consensus.Istanbul {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	proposerSets, _ := lru.NewARC(inmemoryProposerSets)
+	epochStakes, _ := lru.NewARC(inmemoryEpochStakes)
 	backend := &Backend{
 		config:               config,
 		istanbulEventMux:     new(event.TypeMux),
@@ -71,9 +81,20 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		coreStarted:          false,
 		recentMessages:       recentMessages,
 		knownMessages:        knownMessages,
+		proposerSets:         proposerSets,
+		epochStakes:          epochStakes,
 		announceWg:           new(sync.WaitGroup),
 		announceQuit:         make(chan struct{}),
 		lastAnnounceGossiped: make(map[common.Address]*AnnounceGossipTimestamp),
+		nodeKey:              nodeKey,
+		valPublicKeys:        make(map[common.Address]*ecdsa.PublicKey),
+		sentries:             parseEnodes(config.Sentries),
+		clock:                istanbul.SystemClock,
+	}
+	if config.ProxiedValidator != "" {
+		if nodes := parseEnodes([]string{config.ProxiedValidator}); len(nodes) > 0 {
+			backend.proxiedValidator = nodes[0]
+		}
 	}
 	backend.core = istanbulCore.New(backend, backend.config)
 	backend.valEnodeTable = newValidatorEnodeTable(backend.AddValidatorPeer, backend.RemoveValidatorPeer)
@@ -88,9 +109,11 @@ type Backend struct {
 
 	address  common.Address    // Ethereum address of the signing key
 	signFn   istanbul.SignerFn // Signer function to authorize hashes with
+	blsKey   *bls.PrivateKey   // Key to sign aggregated committed seals with, loaded on first use
 	signFnMu sync.RWMutex      // Protects the signer fields
 
 	core         istanbulCore.Engine
+	clock        istanbul.Clock // Clock of the block timestamps and the consensus timers
 	logger       log.Logger
 	db           ethdb.Database
 	chain        consensus.ChainReader
@@ -111,6 +134,9 @@ type Backend struct {
 	// Snapshots for recent blocks to speed up reorgs
 	recents *lru.ARCCache
 
+	proposerSets *lru.ARCCache // Validator sets with the inputs of the proposer policy, by block hash
+	epochStakes  *lru.ARCCache // Bonded stakes of the validators, by the hash of the block they were elected in
+
 	// event subscription for ChainHeadEvent event
 	broadcaster consensus.Broadcaster
 
@@ -121,10 +147,24 @@ type Backend struct {
 	regAdd consensus.ConsensusRegAdd
 	gpm    consensus.ConsensusGasPriceMinimum
 
-	lastAnnounceGossiped map[common.Address]*AnnounceGossipTimestamp
+	lastAnnounceGossiped   map[common.Address]*AnnounceGossipTimestamp
+	lastAnnounceGossipedMu sync.Mutex
+
+	nodeKey         *ecdsa.PrivateKey                   // Key of the node, to decrypt the enode URLs announced to it
+	valPublicKeys   map[common.Address]*ecdsa.PublicKey // Public keys of the validators, recovered from their announce messages
+	valPublicKeysMu sync.RWMutex
 
 	valEnodeTable *validatorEnodeTable
 
+	sentries         []*enode.Node // Sentries of the validator, the only nodes it connects to
+	proxiedValidator *enode.Node   // Validator this node is a sentry of
+
+	uptime    *EpochUptime       // Uptime of the epoch of the last accounted block
+	uptimeMu  sync.Mutex         // Serializes the accounting of blocks for uptime
+	uptimeSub event.Subscription // Subscription to the chain heads accounted for uptime
+
+	equivocationMu sync.Mutex // Serializes the storage of equivocation evidence
+
 	announceWg   *sync.WaitGroup
 	announceQuit chan struct{}
 }
@@ -144,7 +184,15 @@ func (sb *Backend) Address() common.Address {
 	return sb.address
 }
 
+// Clock implements istanbul.Backend.Clock
+func (sb *Backend) Clock() istanbul.Clock {
+	return sb.clock
+}
+
 func (sb *Backend) Close() error {
+	if sb.uptimeSub != nil {
+		sb.uptimeSub.Unsubscribe()
+	}
 	return nil
 }
 
@@ -167,24 +215,39 @@ func (sb *Backend) Broadcast(valSet istanbul.ValidatorSet, payload []byte) error
 
 // Gossip implements istanbul.Backend.Gossip
 func (sb *Backend) Gossip(valSet istanbul.ValidatorSet, payload []byte, msgCode uint64, ignoreCache bool) error {
-	var hash common.Hash
-	if !ignoreCache {
-		hash = istanbul.RLPHash(payload)
-		sb.knownMessages.Add(hash, true)
-	}
-
 	var targets map[common.Address]bool = nil
 
-	if valSet != nil {
+	if sb.isProxied() {
+		// A validator behind sentries sends everything through them
+		targets = sb.sentryAddresses()
+	} else if valSet != nil {
 		targets = make(map[common.Address]bool)
 		for _, val := range valSet.List() {
 			if val.Address() != sb.Address() {
 				targets[val.Address()] = true
 			}
 		}
+		// Validators behind sentries are reached through their sentries, which
+		// are connected to as validator peers
+		if len(targets) > 0 {
+			for _, addr := range sb.validatorPeerAddresses() {
+				targets[addr] = true
+			}
+		}
+	}
+	return sb.gossipTo(targets, payload, msgCode, ignoreCache)
+}
+
+// gossipTo sends the message to the peers with the given addresses, or to all
+// peers if targets is nil.
+func (sb *Backend) gossipTo(targets map[common.Address]bool, payload []byte, msgCode uint64, ignoreCache bool) error {
+	var hash common.Hash
+	if !ignoreCache {
+		hash = istanbul.RLPHash(payload)
+		sb.knownMessages.Add(hash, true)
 	}
 
-	if sb.broadcaster != nil && ((valSet == nil) || (len(targets) > 0)) {
+	if sb.broadcaster != nil && ((targets == nil) || (len(targets) > 0)) {
 		ps := sb.broadcaster.FindPeers(targets)
 
 		for addr, p := range ps {
@@ -220,7 +283,7 @@ func (sb *Backend) Enode() *enode.Node {
 }
 
 // Commit implements istanbul.Backend.Commit
-func (sb *Backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
+func (sb *Backend) Commit(proposal istanbul.Proposal, committers []common.Address, seals [][]byte) error {
 	// Check if the proposal is a valid block
 	block := &types.Block{}
 	block, ok := proposal.(*types.Block)
@@ -231,8 +294,15 @@ func (sb *Backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
 
 	h := block.Header()
 	// Append seals into extra-data
-	err := writeCommittedSeals(h, seals)
-	if err != nil {
+	if valSet := sb.ParentValidators(proposal); sb.isAggregatedSeal(proposal.Number().Uint64(), valSet) {
+		aggregatedSeal, err := aggregateCommittedSeals(block.Hash(), valSet, committers, seals)
+		if err != nil {
+			return err
+		}
+		if err := writeAggregatedSeal(h, aggregatedSeal); err != nil {
+			return err
+		}
+	} else if err := writeCommittedSeals(h, seals); err != nil {
 		return err
 	}
 	// update block's header
@@ -296,7 +366,7 @@ func (sb *Backend) Verify(proposal istanbul.Proposal, src istanbul.Validator) (t
 	// ignore errEmptyCommittedSeals error because we don't have the committed seals yet
 	if err != nil && err != errEmptyCommittedSeals {
 		if err == consensus.ErrFutureBlock {
-			return time.Unix(block.Header().Time.Int64(), 0).Sub(now()), consensus.ErrFutureBlock
+			return time.Unix(block.Header().Time.Int64(), 0).Sub(sb.clock.Now()), consensus.ErrFutureBlock
 		} else {
 			return 0, err
 		}
@@ -354,18 +424,23 @@ func (sb *Backend) verifyValSetDiff(proposal istanbul.Proposal, block *types.Blo
 			return errInvalidValidatorSetDiff
 		}
 	} else {
-		parentValidators := sb.ParentValidators(proposal)
-		oldValSet := make([]common.Address, 0, parentValidators.Size())
-
-		for _, val := range parentValidators.List() {
-			oldValSet = append(oldValSet, val.Address())
+		addedValidators, removedValidators, publicKeys, err := sb.getValSetDiff(header, state, sb.ParentValidators(proposal), newValSet)
+		if err != nil {
+			log.Error("Istanbul.verifyValSetDiff - Error in retrieving the BLS public keys. Verifying val set diff empty.", "err", err)
+			addedValidators, removedValidators, publicKeys = nil, nil, nil
 		}
 
-		addedValidators, removedValidators := istanbul.ValidatorSetDiff(oldValSet, newValSet)
-
 		if !istanbul.CompareValidatorSlices(addedValidators, istExtra.AddedValidators) || !istanbul.CompareValidatorSlices(removedValidators, istExtra.RemovedValidators) {
 			return errInvalidValidatorSetDiff
 		}
+		if len(publicKeys) != len(istExtra.AddedValidatorsPublicKeys) {
+			return errInvalidValidatorSetDiff
+		}
+		for i := range publicKeys {
+			if !bytes.Equal(publicKeys[i], istExtra.AddedValidatorsPublicKeys[i]) {
+				return errInvalidValidatorSetDiff
+			}
+		}
 	}
 
 	return nil
@@ -382,6 +457,82 @@ func (sb *Backend) Sign(data []byte) ([]byte, error) {
 	return sb.signFn(accounts.Account{Address: sb.address}, hashData)
 }
 
+// SignCommittedSeal implements istanbul.Backend.SignCommittedSeal
+func (sb *Backend) SignCommittedSeal(seal []byte, number *big.Int, valSet istanbul.ValidatorSet) ([]byte, error) {
+	if !sb.isAggregatedSeal(number.Uint64(), valSet) {
+		return sb.Sign(seal)
+	}
+	key, err := sb.getBLSKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.Sign(seal).Marshal(), nil
+}
+
+// CheckCommittedSeal implements istanbul.Backend.CheckCommittedSeal
+func (sb *Backend) CheckCommittedSeal(seal []byte, validator istanbul.Validator, committedSeal []byte, number *big.Int) error {
+	// The validators of a set aggregating committed seals all have a BLS public key
+	if !sb.config.IsAggregatedSeal(number.Uint64()) || len(validator.BLSPublicKey()) == 0 {
+		return sb.CheckSignature(seal, validator.Address(), committedSeal)
+	}
+	publicKey, err := bls.UnmarshalPublicKey(validator.BLSPublicKey())
+	if err != nil {
+		return err
+	}
+	signature, err := bls.UnmarshalSignature(committedSeal)
+	if err != nil {
+		return err
+	}
+	if !publicKey.Verify(seal, signature) {
+		return errInvalidCommittedSeals
+	}
+	return nil
+}
+
+// isAggregatedSeal returns whether the committed seals of the block with the given
+// number, sealed by the given validator set, are aggregated BLS signatures. They
+// are from the epoch of the AggregatedSealBlock on, once a validator set with BLS
+// public keys is elected.
+func (sb *Backend) isAggregatedSeal(number uint64, valSet istanbul.ValidatorSet) bool {
+	return sb.config.IsAggregatedSeal(number) && hasBLSPublicKeys(valSet)
+}
+
+// getBLSKey returns the key to sign aggregated committed seals with. It is loaded
+// from the configured key file, which is generated if missing. Ephemeral nodes
+// and tests without a key file use a new key for as long as they run, which no
+// Validators contract knows of, so the seals they sign can't be verified.
+func (sb *Backend) getBLSKey() (*bls.PrivateKey, error) {
+	sb.signFnMu.Lock()
+	defer sb.signFnMu.Unlock()
+
+	if sb.blsKey != nil {
+		return sb.blsKey, nil
+	}
+	if sb.config.BLSKey == "" {
+		key, err := bls.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		sb.logger.Error("No BLS key file configured, signing aggregated committed seals with an unregistered ephemeral key", "publicKey", hexutil.Encode(key.PublicKey().Marshal()))
+		sb.blsKey = key
+		return key, nil
+	}
+	key, err := bls.LoadKey(sb.config.BLSKey)
+	if os.IsNotExist(err) {
+		if key, err = bls.GenerateKey(rand.Reader); err != nil {
+			return nil, err
+		}
+		if err = bls.SaveKey(sb.config.BLSKey, key); err != nil {
+			return nil, err
+		}
+		sb.logger.Warn("Generated a new BLS key, register its public key to sign aggregated committed seals", "file", sb.config.BLSKey, "publicKey", hexutil.Encode(key.PublicKey().Marshal()))
+	} else if err != nil {
+		return nil, err
+	}
+	sb.blsKey = key
+	return key, nil
+}
+
 // CheckSignature implements istanbul.Backend.CheckSignature
 func (sb *Backend) CheckSignature(data []byte, address common.Address, sig []byte) error {
 	signer, err := istanbul.GetSignatureAddress(data, sig)
@@ -423,6 +574,14 @@ func (sb *Backend) getValidators(number uint64, hash common.Hash) istanbul.Valid
 	if err != nil {
 		return validator.NewSet(nil, sb.config.ProposerPolicy)
 	}
+	if needsProposerSelectionInputs(sb.config.ProposerPolicy) {
+		valSet, err := sb.withProposerSelectionInputs(snap.ValSet, number, hash)
+		if err != nil {
+			sb.logger.Error("Failed to retrieve proposer selection inputs", "number", number, "hash", hash, "err", err)
+			return validator.NewSet(nil, sb.config.ProposerPolicy)
+		}
+		return valSet
+	}
 	return snap.ValSet
 }
 
@@ -451,7 +610,8 @@ func (sb *Backend) HasBadProposal(hash common.Hash) bool {
 }
 
 func (sb *Backend) AddValidatorPeer(enodeURL string) {
-	if sb.broadcaster != nil {
+	// A validator behind sentries doesn't connect to other validators
+	if sb.broadcaster != nil && !sb.isProxied() {
 		sb.broadcaster.AddValidatorPeer(enodeURL)
 	}
 }
@@ -478,10 +638,21 @@ func (sb *Backend) GetValidatorPeers() []string {
 func (sb *Backend) RefreshValPeers(valset istanbul.ValidatorSet) {
 	sb.logger.Trace("Called RefreshValPeers", "valset length", valset.Size())
 
-	currentValPeers := sb.GetValidatorPeers()
+	// A validator behind sentries keeps the connections to them only
+	if sb.isProxied() {
+		return
+	}
+
+	// The connection of a sentry to its validator is kept
+	var currentValPeers []string
+	for _, peerEnodeURL := range sb.GetValidatorPeers() {
+		if !sb.isSentry() || peerEnodeURL != sb.proxiedValidator.String() {
+			currentValPeers = append(currentValPeers, peerEnodeURL)
+		}
+	}
 
 	// Disconnect all validator peers if this node is not in the valset
-	if _, val := valset.GetByAddress(sb.Address()); val == nil {
+	if _, val := valset.GetByAddress(sb.validatorAddress()); val == nil {
 		for _, peerEnodeURL := range currentValPeers {
 			sb.RemoveValidatorPeer(peerEnodeURL)
 		}
@@ -19,6 +19,10 @@ package backend
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -28,8 +32,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bls"
 )
 
 func TestSign(t *testing.T) {
@@ -154,7 +160,7 @@ func TestCommit(t *testing.T) {
 		}()
 
 		backend.proposedBlockHash = expBlock.Hash()
-		if err := backend.Commit(expBlock, test.expectedSignature); err != nil {
+		if err := backend.Commit(expBlock, nil, test.expectedSignature); err != nil {
 			if err != test.expectedErr {
 				t.Errorf("error mismatch: have %v, want %v", err, test.expectedErr)
 			}
@@ -244,3 +250,91 @@ func newBackend() (b *Backend) {
 	b.Authorize(crypto.PubkeyToAddress(key.PublicKey), signerFn)
 	return
 }
+
+func TestCheckCommittedSeal(t *testing.T) {
+	b := newBackend()
+	config := *b.config
+	config.Epoch = 10
+	config.AggregatedSealBlock = big.NewInt(11)
+	b.config = &config
+	seal := []byte("Here is a seal....")
+
+	// Committed seals are signed by the signing key before aggregated seals activate
+	unkeyed := validator.NewSet([]common.Address{b.Address(), getInvalidAddress()}, istanbul.RoundRobin)
+	committedSeal, err := b.SignCommittedSeal(seal, big.NewInt(10), unkeyed)
+	if err != nil {
+		t.Fatalf("failed to sign committed seal: %v", err)
+	}
+	signer := validator.New(b.Address())
+	if err := b.CheckCommittedSeal(seal, signer, committedSeal, big.NewInt(10)); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	if err := b.CheckCommittedSeal(seal, validator.New(getInvalidAddress()), committedSeal, big.NewInt(10)); err != errInvalidSignature {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidSignature)
+	}
+	// and after the fork block, as long as the validators have no BLS public keys
+	committedSeal, err = b.SignCommittedSeal(seal, big.NewInt(11), unkeyed)
+	if err != nil {
+		t.Fatalf("failed to sign committed seal: %v", err)
+	}
+	if err := b.CheckCommittedSeal(seal, signer, committedSeal, big.NewInt(11)); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+
+	// and by the BLS key once they do
+	dir, err := ioutil.TempDir("", "istanbul-bls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.BLSKey = filepath.Join(dir, "blskey")
+
+	key, err := b.getBLSKey()
+	if err != nil {
+		t.Fatalf("failed to generate BLS key: %v", err)
+	}
+	valSet := validator.NewSet([]common.Address{b.Address(), getInvalidAddress()}, istanbul.RoundRobin)
+	other, _ := bls.GenerateKey(nil)
+	valSet.SetBLSPublicKeys([]common.Address{b.Address(), getInvalidAddress()}, [][]byte{key.PublicKey().Marshal(), other.PublicKey().Marshal()})
+
+	committedSeal, err = b.SignCommittedSeal(seal, big.NewInt(11), valSet)
+	if err != nil {
+		t.Fatalf("failed to sign committed seal: %v", err)
+	}
+	if loaded, err := bls.LoadKey(config.BLSKey); err != nil || !bytes.Equal(loaded.PublicKey().Marshal(), key.PublicKey().Marshal()) {
+		t.Fatalf("failed to load generated BLS key: %v", err)
+	}
+	_, signer = valSet.GetByAddress(b.Address())
+	if err := b.CheckCommittedSeal(seal, signer, committedSeal, big.NewInt(11)); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	_, signer = valSet.GetByAddress(getInvalidAddress())
+	if err := b.CheckCommittedSeal(seal, signer, committedSeal, big.NewInt(11)); err != errInvalidCommittedSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
+	}
+}
+
+func TestProposerSelectionInputs(t *testing.T) {
+	chain, b := newBlockChain(4, true)
+	config := *b.config
+	config.ProposerPolicy = istanbul.StakeWeighted
+	b.config = &config
+	genesis := chain.Genesis()
+
+	// Without the contracts deployed, all nodes select proposers by round robin
+	if valSet := b.getValidators(0, genesis.Hash()); valSet.Size() != 4 {
+		t.Errorf("validator set size mismatch: have %d, want %d", valSet.Size(), 4)
+	}
+
+	// Inputs that cannot be read yield no validator set rather than a fallback
+	b.proposerSets.Purge()
+	stateAt := b.stateAt
+	b.stateAt = func(common.Hash) (*state.StateDB, error) { return nil, errUnknownBlock }
+	if valSet := b.getValidators(0, genesis.Hash()); valSet.Size() != 0 {
+		t.Errorf("validator set size mismatch: have %d, want %d", valSet.Size(), 0)
+	}
+	b.stateAt = stateAt
+	if valSet := b.getValidators(0, genesis.Hash()); valSet.Size() != 4 {
+		t.Errorf("validator set size mismatch: have %d, want %d", valSet.Size(), 4)
+	}
+}
@@ -21,18 +21,19 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/bls"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -47,67 +48,7 @@ const (
 	inmemoryMessages              = 1024
 	mobileAllowedClockSkew uint64 = 5
 
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/Validators.json
-	getValidatorsABI = `[{"constant": true,
-		              "inputs": [],
-			      "name": "getValidators",
-			      "outputs": [
-				   {
-				        "name": "",
-					"type": "address[]"
-				   }
-			      ],
-			      "payable": false,
-			      "stateMutability": "view",
-			      "type": "function"
-			     }]`
-
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/BondedDeposits.json
-	setCumulativeRewardWeightABI = `[{"constant": false,
-                                          "inputs": [
-                                            {
-                                              "name": "blockReward",
-                                              "type": "uint256"
-                                            }
-                                          ],
-                                          "name": "setCumulativeRewardWeight",
-                                          "outputs": [],
-                                          "payable": false,
-                                          "stateMutability": "nonpayable",
-                                          "type": "function"
-                                        }]`
-
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/GoldToken.json
-	increaseSupplyABI = `[{
-		"constant": false,
-		"inputs": [
-		  {
-			"name": "amount",
-			"type": "uint256"
-		  }
-		],
-		"name": "increaseSupply",
-		"outputs": [],
-		"payable": false,
-		"stateMutability": "nonpayable",
-		"type": "function"
-				 }]`
-
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/GoldToken.json
-	totalSupplyABI = `[{
-		"constant": true,
-		"inputs": [],
-		"name": "totalSupply",
-		"outputs": [
-		  {
-			"name": "",
-			"type": "uint256"
-		  }
-		],
-		"payable": false,
-		"stateMutability": "view",
-		"type": "function"
-	  }]`
+	maxGasForGetValidatorBlsKey uint64 = 1000000 // Gas allowance for looking up a validator's BLS key
 )
 
 var (
@@ -154,21 +95,30 @@ var (
 	// errUnauthorizedAnnounceMessage is returned when the received announce message is from
 	// an unregistered validator
 	errUnauthorizedAnnounceMessage = errors.New("unauthorized announce message")
+	// errInvalidNodeKey is returned when the enode URL announced to the validator can't
+	// be decrypted, because the node key isn't the key of the validator.
+	errInvalidNodeKey = errors.New("node key is not the validator key")
+	// errInvalidProofOfPossession is returned if a validator's BLS public key comes without
+	// a valid proof of possession of its private key.
+	errInvalidProofOfPossession = errors.New("invalid bls proof of possession")
+	// errUnknownEpochUptime is returned when the uptime of an epoch is requested that
+	// hasn't been tracked by the node.
+	errUnknownEpochUptime = errors.New("unknown epoch uptime")
+	// errUnknownEquivocation is returned when equivocation evidence is requested
+	// that the node hasn't detected.
+	errUnknownEquivocation = errors.New("unknown equivocation evidence")
+	// errNoProposerSelectionInputs is returned when the proposer policy needs chain
+	// state the backend has no access to.
+	errNoProposerSelectionInputs = errors.New("no state to read proposer selection inputs from")
 )
 
 var (
 	defaultDifficulty = big.NewInt(1)
 	nilUncleHash      = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
 	emptyNonce        = types.BlockNonce{}
-	now               = time.Now
 
 	inmemoryAddresses  = 20 // Number of recent addresses from ecrecover
 	recentAddresses, _ = lru.NewARC(inmemoryAddresses)
-
-	getValidatorsFuncABI, _             = abi.JSON(strings.NewReader(getValidatorsABI))
-	increaseSupplyFuncABI, _            = abi.JSON(strings.NewReader(increaseSupplyABI))
-	totalSupplyFuncABI, _               = abi.JSON(strings.NewReader(totalSupplyABI))
-	setCumulativeRewardWeightFuncABI, _ = abi.JSON(strings.NewReader(setCumulativeRewardWeightABI))
 )
 
 // Author retrieves the Ethereum address of the account that minted the given
@@ -196,7 +146,7 @@ func (sb *Backend) verifyHeader(chain consensus.ChainReader, header *types.Heade
 
 	// If the full chain isn't available (as on mobile devices), don't reject future blocks
 	// This is due to potential clock skew
-	var allowedFutureBlockTime = big.NewInt(now().Unix())
+	var allowedFutureBlockTime = big.NewInt(sb.clock.Now().Unix())
 	if !chain.Config().FullHeaderChainAvailable {
 		allowedFutureBlockTime = new(big.Int).Add(allowedFutureBlockTime, new(big.Int).SetUint64(mobileAllowedClockSkew))
 	}
@@ -341,6 +291,9 @@ func (sb *Backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	if err != nil {
 		return err
 	}
+	if sb.isAggregatedSeal(number, snap.ValSet) {
+		return verifyAggregatedSeal(header, extra.AggregatedSeal, snap.ValSet)
+	}
 	// The length of Committed seals should be larger than 0
 	if len(extra.CommittedSeal) == 0 {
 		return errEmptyCommittedSeals
@@ -410,8 +363,8 @@ func (sb *Backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 
 	// set header's timestamp
 	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(sb.config.BlockPeriod))
-	if header.Time.Int64() < time.Now().Unix() {
-		header.Time = big.NewInt(time.Now().Unix())
+	if header.Time.Int64() < sb.clock.Now().Unix() {
+		header.Time = big.NewInt(sb.clock.Now().Unix())
 	}
 
 	return nil
@@ -427,11 +380,14 @@ func (sb *Backend) getValSet(header *types.Header, state *state.StateDB) ([]comm
 		log.Error(err.Error())
 		return newValSet, err
 	} else {
+		validators, err := contracts.NewValidators(*validatorsAddress, sb.iEvmH)
+		if err != nil {
+			return newValSet, err
+		}
 		// Get the new epoch's validator set
 		maxGasForGetValidators := uint64(10000000)
 		// TODO(asa) - Once the validator election smart contract is completed, then a more accurate gas value should be used.
-		_, err := sb.iEvmH.MakeStaticCall(*validatorsAddress, getValidatorsFuncABI, "getValidators", []interface{}{}, &newValSet, maxGasForGetValidators, header, state)
-		return newValSet, err
+		return validators.GetValidators(&bind.InternalCallOpts{Gas: maxGasForGetValidators, Header: header, State: state})
 	}
 }
 
@@ -451,12 +407,16 @@ func (sb *Backend) UpdateValSetDiff(chain consensus.ChainReader, header *types.H
 			}
 
 			// add validators in snapshot to extraData's validators section
-			extra, err := assembleExtra(header, snap.validators(), newValSet)
-			if err != nil {
-				return err
+			addedValidators, removedValidators, publicKeys, err := sb.getValSetDiff(header, state, snap.ValSet, newValSet)
+			if err == nil {
+				extra, err := assembleExtra(header, addedValidators, removedValidators)
+				if err != nil {
+					return err
+				}
+				header.Extra = extra
+				return writeAddedValidatorsPublicKeys(header, publicKeys)
 			}
-			header.Extra = extra
-			return nil
+			log.Error("Istanbul.Finalize - Error in retrieving the BLS public keys. Using the previous epoch's validator set", "err", err)
 		}
 	}
 	// If it's not the last block or we were unable to pull the new validator set, then the validator set diff should be empty
@@ -468,6 +428,143 @@ func (sb *Backend) UpdateValSetDiff(chain consensus.ChainReader, header *types.H
 	return nil
 }
 
+// getValSetDiff returns the validators added and removed at the end of the epoch
+// of the given block, and once committed seals are aggregated, the BLS public keys
+// of the validators added, retrieved from the Validators contract.
+func (sb *Backend) getValSetDiff(header *types.Header, state *state.StateDB, oldValSet istanbul.ValidatorSet, newValSet []common.Address) ([]common.Address, []common.Address, [][]byte, error) {
+	if !sb.config.IsAggregatedSeal(header.Number.Uint64() + 1) {
+		addedValidators, removedValidators := istanbul.ValidatorSetDiff(validatorAddresses(oldValSet), newValSet)
+		return addedValidators, removedValidators, nil, nil
+	}
+	blsPublicKey, err := sb.getBLSPublicKeyFn(header, state)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	addedValidators, removedValidators, publicKeys := sb.validatorSetDiff(header.Number.Uint64(), oldValSet, newValSet, blsPublicKey)
+	return addedValidators, removedValidators, publicKeys, nil
+}
+
+// validatorSetDiff returns the validators added and removed at the end of the
+// epoch of the given block, and once committed seals are aggregated, the BLS
+// public keys of the validators added. The elected validators whose key can't be
+// retrieved are left out of the validator set then.
+//
+// Aggregation starts once the whole validator set is replaced by validators with
+// keys, from the epoch of the AggregatedSealBlock on. As long as too few of the
+// elected validators have a key to make a quorum, the validator set keeps on
+// signing committed seals with their signing keys for another epoch.
+func (sb *Backend) validatorSetDiff(number uint64, oldValSet istanbul.ValidatorSet, newValSet []common.Address, blsPublicKey func(common.Address) ([]byte, error)) ([]common.Address, []common.Address, [][]byte) {
+	addedValidators, removedValidators := istanbul.ValidatorSetDiff(validatorAddresses(oldValSet), newValSet)
+	if !sb.config.IsAggregatedSeal(number + 1) {
+		return addedValidators, removedValidators, nil
+	}
+	activated := hasBLSPublicKeys(oldValSet)
+	candidates := addedValidators
+	if !activated {
+		candidates = newValSet
+	}
+	var (
+		keyedValidators []common.Address
+		publicKeys      [][]byte
+	)
+	for _, val := range candidates {
+		publicKey, err := blsPublicKey(val)
+		if err != nil {
+			log.Warn("Leaving out validator without a valid BLS public key", "number", number, "validator", val, "err", err)
+			continue
+		}
+		keyedValidators = append(keyedValidators, val)
+		publicKeys = append(publicKeys, publicKey)
+	}
+	if activated {
+		return keyedValidators, removedValidators, publicKeys
+	}
+	if quorum := 2*validator.NewSet(newValSet, sb.config.ProposerPolicy).F() + 1; len(keyedValidators) < quorum {
+		log.Warn("Too few validators with a BLS public key to aggregate committed seals", "number", number, "validators", len(keyedValidators), "quorum", quorum)
+		return addedValidators, removedValidators, nil
+	}
+	return keyedValidators, validatorAddresses(oldValSet), publicKeys
+}
+
+// getBLSPublicKeyFn returns a function retrieving the BLS public key a validator
+// registered in the Validators contract. Every key must come with a valid proof
+// of possession, which keeps validators from registering keys that cancel out
+// the keys of others in the aggregated committed seals.
+func (sb *Backend) getBLSPublicKeyFn(header *types.Header, state *state.StateDB) (func(common.Address) ([]byte, error), error) {
+	validatorsAddress, err := sb.regAdd.GetRegisteredAddressAtStateAndHeader(params.ValidatorsRegistryId, state, header)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := contracts.NewValidators(*validatorsAddress, sb.iEvmH)
+	if err != nil {
+		return nil, err
+	}
+	return func(validator common.Address) ([]byte, error) {
+		key, err := contract.GetValidatorBlsKey(&bind.InternalCallOpts{Gas: maxGasForGetValidatorBlsKey, Header: header, State: state}, validator)
+		if err != nil {
+			return nil, err
+		}
+		return checkBLSPublicKey(key.PublicKey, key.ProofOfPossession)
+	}, nil
+}
+
+// checkBLSPublicKey returns the given BLS public key if it comes with a valid
+// proof of possession.
+func checkBLSPublicKey(publicKey []byte, proofOfPossession []byte) ([]byte, error) {
+	key, err := bls.UnmarshalPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	pop, err := bls.UnmarshalSignature(proofOfPossession)
+	if err != nil || !key.VerifyPossession(pop) {
+		return nil, errInvalidProofOfPossession
+	}
+	return publicKey, nil
+}
+
+// writeAddedValidatorsPublicKeys writes the BLS public keys of the validators added
+// in the header into its extra-data field.
+func writeAddedValidatorsPublicKeys(h *types.Header, publicKeys [][]byte) error {
+	if len(publicKeys) == 0 {
+		return nil
+	}
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+	istanbulExtra.AddedValidatorsPublicKeys = publicKeys
+
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// validatorAddresses returns the addresses of the validators of the set.
+func validatorAddresses(valSet istanbul.ValidatorSet) []common.Address {
+	addresses := make([]common.Address, 0, valSet.Size())
+	for _, val := range valSet.List() {
+		addresses = append(addresses, val.Address())
+	}
+	return addresses
+}
+
+// hasBLSPublicKeys returns whether the validators of the set all have a BLS public
+// key, which is the case once their committed seals are aggregated.
+func hasBLSPublicKeys(valSet istanbul.ValidatorSet) bool {
+	if valSet.Size() == 0 {
+		return false
+	}
+	for _, val := range valSet.List() {
+		if len(val.BLSPublicKey()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // TODO(brice): This needs a comment.
 func (sb *Backend) IsLastBlockOfEpoch(header *types.Header) bool {
 	return istanbul.IsLastBlockOfEpoch(header.Number.Uint64(), sb.config.Epoch)
@@ -520,7 +617,10 @@ func (sb *Backend) Finalize(chain consensus.ChainReader, header *types.Header, s
 		if bondedDepositsAddress != nil {
 			state.AddBalance(*bondedDepositsAddress, stakerBlockReward)
 			totalBlockRewards.Add(totalBlockRewards, stakerBlockReward)
-			_, err := sb.iEvmH.MakeCall(*bondedDepositsAddress, setCumulativeRewardWeightFuncABI, "setCumulativeRewardWeight", []interface{}{stakerBlockReward}, nil, 1000000, common.Big0, header, state)
+			bondedDeposits, err := contracts.NewBondedDeposits(*bondedDepositsAddress, sb.iEvmH)
+			if err == nil {
+				err = bondedDeposits.SetCumulativeRewardWeight(&bind.InternalCallOpts{Gas: 1000000, Value: common.Big0, Header: header, State: state}, stakerBlockReward)
+			}
 			if err != nil {
 				log.Error("Unable to send block rewards to bonded deposits", "err", err)
 				return nil, err
@@ -529,8 +629,12 @@ func (sb *Backend) Finalize(chain consensus.ChainReader, header *types.Header, s
 
 		// Update totalSupply of GoldToken.
 		if totalBlockRewards.Cmp(common.Big0) > 0 {
-			var totalSupply *big.Int
-			if _, err := sb.iEvmH.MakeStaticCall(*goldTokenAddress, totalSupplyFuncABI, "totalSupply", []interface{}{}, &totalSupply, 1000000, header, state); err != nil || totalSupply == nil {
+			goldToken, err := contracts.NewGoldToken(*goldTokenAddress, sb.iEvmH)
+			if err != nil {
+				return nil, err
+			}
+			totalSupply, err := goldToken.TotalSupply(&bind.InternalCallOpts{Gas: 1000000, Header: header, State: state})
+			if err != nil || totalSupply == nil {
 				log.Error("Unable to retrieve total supply from the Gold token smart contract", "err", err)
 				return nil, err
 			}
@@ -544,7 +648,7 @@ func (sb *Backend) Finalize(chain consensus.ChainReader, header *types.Header, s
 				genesisSupply.SetBytes(data)
 				totalBlockRewards.Add(totalBlockRewards, genesisSupply)
 			}
-			if _, err := sb.iEvmH.MakeCall(*goldTokenAddress, increaseSupplyFuncABI, "increaseSupply", []interface{}{totalBlockRewards}, nil, 1000000, common.Big0, header, state); err != nil {
+			if err := goldToken.IncreaseSupply(&bind.InternalCallOpts{Gas: 1000000, Value: common.Big0, Header: header, State: state}, totalBlockRewards); err != nil {
 				log.Error("Unable to increment goldTotalSupply for block reward", "err", err)
 				return nil, err
 			}
@@ -584,10 +688,13 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, results
 	}
 
 	// wait for the timestamp of header, use this to adjust the block period
-	delay := time.Unix(block.Header().Time.Int64(), 0).Sub(now())
+	delay := time.Unix(block.Header().Time.Int64(), 0).Sub(sb.clock.Now())
+	timeout := make(chan struct{})
+	timer := sb.clock.AfterFunc(delay, func() { close(timeout) })
 	select {
-	case <-time.After(delay):
+	case <-timeout:
 	case <-stop:
+		timer.Stop()
 		return nil
 	}
 
@@ -679,6 +786,13 @@ func (sb *Backend) SetGasPriceMinimum(gpm consensus.ConsensusGasPriceMinimum) {
 func (sb *Backend) SetChain(chain consensus.ChainReader, currentBlock func() *types.Block) {
 	sb.chain = chain
 	sb.currentBlock = currentBlock
+
+	if subscriber, ok := chain.(chainHeadSubscriber); ok {
+		if sb.uptimeSub != nil {
+			sb.uptimeSub.Unsubscribe()
+		}
+		sb.trackUptime(subscriber)
+	}
 }
 
 // Start implements consensus.Istanbul.Start
@@ -818,7 +932,12 @@ func (sb *Backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 			return nil, errInvalidValidatorSetDiff
 		}
 
-		snap = newSnapshot(sb.config.Epoch, 0, genesis.Hash(), validator.NewSet(istanbulExtra.AddedValidators, sb.config.ProposerPolicy))
+		valSet := validator.NewSet(istanbulExtra.AddedValidators, sb.config.ProposerPolicy)
+		if len(istanbulExtra.AddedValidatorsPublicKeys) > 0 && !valSet.SetBLSPublicKeys(istanbulExtra.AddedValidators, istanbulExtra.AddedValidatorsPublicKeys) {
+			log.Error("Genesis block has a mismatching AddedValidatorsPublicKeys set")
+			return nil, errInvalidValidatorSetDiff
+		}
+		snap = newSnapshot(sb.config.Epoch, 0, genesis.Hash(), valSet)
 
 		if err := snap.store(sb.db); err != nil {
 			log.Error("Unable to store snapshot", "err", err)
@@ -914,8 +1033,8 @@ func ecrecover(header *types.Header) (common.Address, error) {
 	return addr, nil
 }
 
-// assembleExtra returns a extra-data of the given header and validators
-func assembleExtra(header *types.Header, oldValSet []common.Address, newValSet []common.Address) ([]byte, error) {
+// assembleExtra returns a extra-data of the given header and validator set diff
+func assembleExtra(header *types.Header, addedValidators []common.Address, removedValidators []common.Address) ([]byte, error) {
 	var buf bytes.Buffer
 
 	// compensate the lack bytes if header.Extra is not enough IstanbulExtraVanity bytes.
@@ -924,10 +1043,8 @@ func assembleExtra(header *types.Header, oldValSet []common.Address, newValSet [
 	}
 	buf.Write(header.Extra[:types.IstanbulExtraVanity])
 
-	addedValidators, removedValidators := istanbul.ValidatorSetDiff(oldValSet, newValSet)
-
 	if len(addedValidators) > 0 || len(removedValidators) > 0 {
-		log.Debug("Setting istanbul header validator fields", "oldValSet", common.ConvertToStringSlice(oldValSet), "newValSet", common.ConvertToStringSlice(newValSet),
+		log.Debug("Setting istanbul header validator fields",
 			"addedValidators", common.ConvertToStringSlice(addedValidators), "removedValidators", common.ConvertToStringSlice(removedValidators))
 	}
 
@@ -995,3 +1112,93 @@ func writeCommittedSeals(h *types.Header, committedSeals [][]byte) error {
 	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
 	return nil
 }
+
+// writeAggregatedSeal writes the extra-data field of a block header with the given
+// aggregated committed seal.
+func writeAggregatedSeal(h *types.Header, aggregatedSeal *types.IstanbulAggregatedSeal) error {
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.AggregatedSeal = aggregatedSeal
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// aggregateCommittedSeals aggregates the BLS committed seals of the given
+// committers to the block with the given hash. Seals that don't verify against
+// their committer's public key are left out, so that a faulty validator cannot
+// spoil the aggregated seal.
+func aggregateCommittedSeals(hash common.Hash, valSet istanbul.ValidatorSet, committers []common.Address, seals [][]byte) (*types.IstanbulAggregatedSeal, error) {
+	proposalSeal := istanbulCore.PrepareCommittedSeal(hash)
+
+	bitmap := new(big.Int)
+	signatures := make([]*bls.Signature, 0, len(seals))
+	for i, committer := range committers {
+		index, v := valSet.GetByAddress(committer)
+		if v == nil || bitmap.Bit(index) == 1 {
+			continue
+		}
+		publicKey, err := bls.UnmarshalPublicKey(v.BLSPublicKey())
+		if err != nil {
+			log.Warn("Committer has no valid BLS public key", "address", committer, "err", err)
+			continue
+		}
+		signature, err := bls.UnmarshalSignature(seals[i])
+		if err != nil || !publicKey.Verify(proposalSeal, signature) {
+			log.Warn("Invalid committed seal", "address", committer)
+			continue
+		}
+		bitmap.SetBit(bitmap, index, 1)
+		signatures = append(signatures, signature)
+	}
+	if len(signatures) <= 2*valSet.F() {
+		return nil, errInvalidCommittedSeals
+	}
+	return &types.IstanbulAggregatedSeal{
+		Bitmap:    bitmap,
+		Signature: bls.AggregateSignatures(signatures).Marshal(),
+	}, nil
+}
+
+// verifyAggregatedSeal checks whether the aggregated committed seal is signed by
+// more than two thirds of the parent's validators, which are identified by their
+// index in the bitmap. This needs a single pairing check, however large the
+// validator set.
+func verifyAggregatedSeal(header *types.Header, aggregatedSeal *types.IstanbulAggregatedSeal, valSet istanbul.ValidatorSet) error {
+	if aggregatedSeal == nil || aggregatedSeal.Bitmap == nil || aggregatedSeal.Bitmap.Sign() == 0 {
+		return errEmptyCommittedSeals
+	}
+	if aggregatedSeal.Bitmap.BitLen() > valSet.Size() {
+		return errInvalidCommittedSeals
+	}
+	publicKeys := make([]*bls.PublicKey, 0, valSet.Size())
+	for i, v := range valSet.List() {
+		if aggregatedSeal.Bitmap.Bit(i) == 0 {
+			continue
+		}
+		publicKey, err := bls.UnmarshalPublicKey(v.BLSPublicKey())
+		if err != nil {
+			return errInvalidCommittedSeals
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	// The number of signers should be larger than number of faulty node + 1
+	if len(publicKeys) <= 2*valSet.F() {
+		return errInvalidCommittedSeals
+	}
+	signature, err := bls.UnmarshalSignature(aggregatedSeal.Signature)
+	if err != nil {
+		return errInvalidSignature
+	}
+	if !bls.VerifyAggregate(publicKeys, istanbulCore.PrepareCommittedSeal(header.Hash()), signature) {
+		return errInvalidCommittedSeals
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package backend
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
@@ -29,11 +30,14 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bls"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -51,7 +55,7 @@ func newBlockChain(n int, isFullChain bool) (*core.BlockChain, *Backend) {
 		return crypto.Sign(data, nodeKeys[0])
 	}
 
-	b, _ := New(config, memDB).(*Backend)
+	b, _ := New(config, memDB, nodeKeys[0]).(*Backend)
 	b.Authorize(address, signerFn)
 
 	genesis.MustCommit(memDB)
@@ -131,6 +135,14 @@ func getGenesisAndKeys(n int, isFullChain bool) (*core.Genesis, []*ecdsa.Private
 	return genesis, nodeKeys
 }
 
+// stoppedClock is the system clock, stopped at the given time.
+type stoppedClock struct {
+	istanbul.Clock
+	now time.Time
+}
+
+func (c stoppedClock) Now() time.Time { return c.now }
+
 func makeHeader(parent *types.Block, config *istanbul.Config) *types.Header {
 	header := &types.Header{
 		ParentHash: parent.Hash(),
@@ -235,7 +247,7 @@ func TestSealCommittedOtherHash(t *testing.T) {
 		if !ok {
 			t.Errorf("unexpected event comes: %v", reflect.TypeOf(ev.Data))
 		}
-		engine.Commit(otherBlock, [][]byte{})
+		engine.Commit(otherBlock, []common.Address{}, [][]byte{})
 		eventSub.Unsubscribe()
 	}
 	go eventLoop()
@@ -332,7 +344,7 @@ func TestVerifyHeader(t *testing.T) {
 	// future block
 	block = makeBlockWithoutSeal(chain, engine, chain.Genesis())
 	header = block.Header()
-	header.Time = new(big.Int).Add(big.NewInt(now().Unix()), new(big.Int).SetUint64(10))
+	header.Time = new(big.Int).Add(big.NewInt(engine.clock.Now().Unix()), new(big.Int).SetUint64(10))
 	err = engine.VerifyHeader(chain, header, false)
 	if err != consensus.ErrFutureBlock {
 		t.Errorf("error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
@@ -397,9 +409,7 @@ func TestVerifyHeaders(t *testing.T) {
 		blocks = append(blocks, b)
 		headers = append(headers, blocks[i].Header())
 	}
-	now = func() time.Time {
-		return time.Unix(headers[size-1].Time.Int64(), 0)
-	}
+	engine.clock = stoppedClock{istanbul.SystemClock, time.Unix(headers[size-1].Time.Int64(), 0)}
 	_, results := engine.VerifyHeaders(chain, headers, nil)
 	const timeoutDura = 2 * time.Second
 	timeout := time.NewTimer(timeoutDura)
@@ -483,7 +493,7 @@ func TestVerifyHeaderWithoutFullChain(t *testing.T) {
 	// allow future block without full chain available
 	block := makeBlockWithoutSeal(chain, engine, chain.Genesis())
 	header := block.Header()
-	header.Time = new(big.Int).Add(big.NewInt(now().Unix()), new(big.Int).SetUint64(3))
+	header.Time = new(big.Int).Add(big.NewInt(engine.clock.Now().Unix()), new(big.Int).SetUint64(3))
 	err := engine.VerifyHeader(chain, header, false)
 	if err != errEmptyCommittedSeals {
 		t.Errorf("error mismatch: have %v, want %v", err, errEmptyCommittedSeals)
@@ -492,7 +502,7 @@ func TestVerifyHeaderWithoutFullChain(t *testing.T) {
 	// reject future block without full chain available
 	block = makeBlockWithoutSeal(chain, engine, chain.Genesis())
 	header = block.Header()
-	header.Time = new(big.Int).Add(big.NewInt(now().Unix()), new(big.Int).SetUint64(10))
+	header.Time = new(big.Int).Add(big.NewInt(engine.clock.Now().Unix()), new(big.Int).SetUint64(10))
 	err = engine.VerifyHeader(chain, header, false)
 	if err != consensus.ErrFutureBlock {
 		t.Errorf("error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
@@ -514,7 +524,8 @@ func TestPrepareExtra(t *testing.T) {
 		Extra: vanity,
 	}
 
-	payload, err := assembleExtra(h, oldValidators, newValidators)
+	addedValidators, removedValidators := istanbul.ValidatorSetDiff(oldValidators, newValidators)
+	payload, err := assembleExtra(h, addedValidators, removedValidators)
 	if err != nil {
 		t.Errorf("error mismatch: have %v, want: nil", err)
 	}
@@ -525,7 +536,7 @@ func TestPrepareExtra(t *testing.T) {
 	// append useless information to extra-data
 	h.Extra = append(vanity, make([]byte, 15)...)
 
-	payload, err = assembleExtra(h, oldValidators, newValidators)
+	payload, err = assembleExtra(h, addedValidators, removedValidators)
 	if !reflect.DeepEqual(payload, expectedResult) {
 		t.Errorf("payload mismatch: have %v, want %v", payload, expectedResult)
 	}
@@ -620,3 +631,156 @@ func TestWriteCommittedSeals(t *testing.T) {
 		t.Errorf("error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
 	}
 }
+
+func TestAggregatedSeal(t *testing.T) {
+	valSet, _ := newTestValidatorSet(4)
+	addresses := make([]common.Address, valSet.Size())
+	blsKeys := make([]*bls.PrivateKey, valSet.Size())
+	publicKeys := make([][]byte, valSet.Size())
+	for i, v := range valSet.List() {
+		addresses[i] = v.Address()
+		blsKeys[i], _ = bls.GenerateKey(nil)
+		publicKeys[i] = blsKeys[i].PublicKey().Marshal()
+	}
+	if !valSet.SetBLSPublicKeys(addresses, publicKeys) {
+		t.Fatalf("failed to set BLS public keys")
+	}
+	header := &types.Header{Number: big.NewInt(1)}
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+
+	// The last committer signs another block, and is left out of the aggregate
+	seals := make([][]byte, valSet.Size())
+	for i, key := range blsKeys {
+		seals[i] = key.Sign(proposalSeal).Marshal()
+	}
+	seals[3] = blsKeys[3].Sign(istanbulCore.PrepareCommittedSeal(common.HexToHash("0x01"))).Marshal()
+
+	aggregatedSeal, err := aggregateCommittedSeals(header.Hash(), valSet, addresses, seals)
+	if err != nil {
+		t.Fatalf("failed to aggregate committed seals: %v", err)
+	}
+	if aggregatedSeal.Bitmap.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("bitmap mismatch: have %b, want %b", aggregatedSeal.Bitmap, 7)
+	}
+	if err := verifyAggregatedSeal(header, aggregatedSeal, valSet); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+
+	// Claiming another signer invalidates the seal
+	forged := &types.IstanbulAggregatedSeal{Bitmap: big.NewInt(15), Signature: aggregatedSeal.Signature}
+	if err := verifyAggregatedSeal(header, forged, valSet); err != errInvalidCommittedSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
+	}
+	// Too few valid seals cannot be aggregated
+	if _, err := aggregateCommittedSeals(header.Hash(), valSet, addresses[2:], seals[2:]); err != errInvalidCommittedSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
+	}
+	if err := verifyAggregatedSeal(header, nil, valSet); err != errEmptyCommittedSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errEmptyCommittedSeals)
+	}
+}
+
+// testBLSPublicKeys serves the BLS public keys of the validators with a key, and
+// rejects the ones without a valid proof of possession.
+type testBLSPublicKeys map[common.Address][2][]byte
+
+func (keys testBLSPublicKeys) add(validator common.Address, key, possessor *bls.PrivateKey) {
+	keys[validator] = [2][]byte{key.PublicKey().Marshal(), possessor.ProvePossession().Marshal()}
+}
+
+func (keys testBLSPublicKeys) get(validator common.Address) ([]byte, error) {
+	key, ok := keys[validator]
+	if !ok {
+		return nil, errors.New("no bls public key")
+	}
+	return checkBLSPublicKey(key[0], key[1])
+}
+
+func TestValidatorSetDiffAtAggregatedSealFork(t *testing.T) {
+	b := &Backend{config: &istanbul.Config{Epoch: 10, AggregatedSealBlock: big.NewInt(5), ProposerPolicy: istanbul.RoundRobin}}
+	var validators []common.Address
+	for i := 1; i <= 5; i++ {
+		validators = append(validators, common.BytesToAddress([]byte{byte(i)}))
+	}
+	oldValidators, newValidators := validators[:2], validators[1:]
+	oldValSet := validator.NewSet(oldValidators, istanbul.RoundRobin)
+
+	keys := make(testBLSPublicKeys)
+	for _, v := range newValidators {
+		key, _ := bls.GenerateKey(nil)
+		keys.add(v, key, key)
+	}
+	apply := func(valSet istanbul.ValidatorSet, added, removed []common.Address, publicKeys [][]byte) istanbul.ValidatorSet {
+		valSet = valSet.Copy()
+		if !valSet.RemoveValidators(removed) || !valSet.AddValidators(added) {
+			t.Fatalf("failed to apply the validator set diff")
+		}
+		if len(publicKeys) > 0 && !valSet.SetBLSPublicKeys(added, publicKeys) {
+			t.Fatalf("failed to set the BLS public keys")
+		}
+		return valSet
+	}
+
+	// Before the fork, the diff only carries the validators that changed
+	added, removed, publicKeys := b.validatorSetDiff(0, oldValSet, newValidators, keys.get)
+	if !reflect.DeepEqual(added, newValidators[1:]) || !reflect.DeepEqual(removed, oldValidators[:1]) || publicKeys != nil {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v no keys", added, removed, len(publicKeys), newValidators[1:], oldValidators[:1])
+	}
+
+	// The epoch before aggregated seals activate replaces the whole validator set
+	added, removed, publicKeys = b.validatorSetDiff(10, oldValSet, newValidators, keys.get)
+	if !reflect.DeepEqual(added, newValidators) || !reflect.DeepEqual(removed, oldValidators) || len(publicKeys) != len(newValidators) {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v %d keys", added, removed, len(publicKeys), newValidators, oldValidators, len(newValidators))
+	}
+	if valSet := apply(oldValSet, added, removed, publicKeys); valSet.Size() != len(newValidators) || !b.isAggregatedSeal(11, valSet) {
+		t.Errorf("validator set mismatch: have %d validators, aggregated %v, want %d, true", valSet.Size(), b.isAggregatedSeal(11, valSet), len(newValidators))
+	}
+
+	// leaving out the validators whose key is missing, as long as a quorum is left
+	delete(keys, validators[4])
+	added, removed, publicKeys = b.validatorSetDiff(10, oldValSet, newValidators, keys.get)
+	if !reflect.DeepEqual(added, newValidators[:3]) || !reflect.DeepEqual(removed, oldValidators) || len(publicKeys) != 3 {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v 3 keys", added, removed, len(publicKeys), newValidators[:3], oldValidators)
+	}
+	if valSet := apply(oldValSet, added, removed, publicKeys); !b.isAggregatedSeal(11, valSet) {
+		t.Errorf("validator set not aggregating committed seals")
+	}
+
+	// Without a quorum of valid keys, the validator set keeps signing its seals
+	forger, _ := bls.GenerateKey(nil)
+	victim, _ := bls.GenerateKey(nil)
+	keys.add(validators[3], victim, forger)
+	added, removed, publicKeys = b.validatorSetDiff(10, oldValSet, newValidators, keys.get)
+	if !reflect.DeepEqual(added, newValidators[1:]) || !reflect.DeepEqual(removed, oldValidators[:1]) || publicKeys != nil {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v no keys", added, removed, len(publicKeys), newValidators[1:], oldValidators[:1])
+	}
+	unkeyed := apply(oldValSet, added, removed, publicKeys)
+	if b.isAggregatedSeal(11, unkeyed) {
+		t.Errorf("validator set without keys aggregating committed seals")
+	}
+
+	// and the whole validator set is replaced an epoch later
+	key, _ := bls.GenerateKey(nil)
+	keys.add(validators[3], key, key)
+	added, removed, publicKeys = b.validatorSetDiff(20, unkeyed, newValidators, keys.get)
+	if !reflect.DeepEqual(added, newValidators[:3]) || !reflect.DeepEqual(removed, validatorAddresses(unkeyed)) || len(publicKeys) != 3 {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v 3 keys", added, removed, len(publicKeys), newValidators[:3], validatorAddresses(unkeyed))
+	}
+	keyed := apply(unkeyed, added, removed, publicKeys)
+	if !b.isAggregatedSeal(21, keyed) {
+		t.Errorf("validator set not aggregating committed seals")
+	}
+
+	// Later epochs only carry the validators that changed, leaving out the
+	// validators elected without a valid key
+	later := []common.Address{validators[1], validators[2], validators[4], validators[0]}
+	key, _ = bls.GenerateKey(nil)
+	keys.add(validators[0], key, key)
+	added, removed, publicKeys = b.validatorSetDiff(30, keyed, later, keys.get)
+	if !reflect.DeepEqual(added, validators[:1]) || !reflect.DeepEqual(removed, validators[3:4]) || len(publicKeys) != 1 {
+		t.Errorf("diff mismatch: have +%v -%v %d keys, want +%v -%v 1 key", added, removed, len(publicKeys), validators[:1], validators[3:4])
+	}
+	if valSet := apply(keyed, added, removed, publicKeys); valSet.Size() != 3 || !b.isAggregatedSeal(31, valSet) {
+		t.Errorf("validator set mismatch: have %d validators, aggregated %v, want 3, true", valSet.Size(), b.isAggregatedSeal(31, valSet))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const dbKeyEquivocationPrefix = "istanbul-equivocation"
+
+func equivocationKey(hash common.Hash) []byte {
+	return append([]byte(dbKeyEquivocationPrefix), hash[:]...)
+}
+
+// loadEquivocation loads the evidence with the given hash from the database.
+func loadEquivocation(db ethdb.Database, hash common.Hash) (*istanbul.Equivocation, error) {
+	blob, err := db.Get(equivocationKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	evidence := new(istanbul.Equivocation)
+	if err := rlp.DecodeBytes(blob, evidence); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
+// loadEquivocations loads the stored evidence, ordered by the view it was signed
+// in, by walking the keys of the evidence in the database.
+func loadEquivocations(db ethdb.Database) ([]*istanbul.Equivocation, error) {
+	keys, err := ethdb.KeysWithPrefix(db, []byte(dbKeyEquivocationPrefix))
+	if err != nil {
+		return nil, err
+	}
+	evidences := make([]*istanbul.Equivocation, 0, len(keys))
+	for _, key := range keys {
+		if len(key) != len(dbKeyEquivocationPrefix)+common.HashLength {
+			continue
+		}
+		evidence, err := loadEquivocation(db, common.BytesToHash(key[len(dbKeyEquivocationPrefix):]))
+		if err != nil {
+			return nil, err
+		}
+		evidences = append(evidences, evidence)
+	}
+	sort.Slice(evidences, func(i, j int) bool {
+		if cmp := evidences[i].View.Cmp(evidences[j].View); cmp != 0 {
+			return cmp < 0
+		}
+		if evidences[i].Code != evidences[j].Code {
+			return evidences[i].Code < evidences[j].Code
+		}
+		return bytes.Compare(evidences[i].Signer[:], evidences[j].Signer[:]) < 0
+	})
+	return evidences, nil
+}
+
+// ReportEquivocation implements istanbul.Backend.ReportEquivocation
+func (sb *Backend) ReportEquivocation(evidence *istanbul.Equivocation) {
+	logger := sb.logger.New("signer", evidence.Signer, "code", evidence.Code, "view", evidence.View)
+	if err := istanbulCore.VerifyEquivocation(evidence); err != nil {
+		logger.Warn("Discarding invalid equivocation evidence", "err", err)
+		return
+	}
+
+	sb.equivocationMu.Lock()
+	defer sb.equivocationMu.Unlock()
+
+	hash := evidence.Hash()
+	if has, _ := sb.db.Has(equivocationKey(hash)); has {
+		return
+	}
+	blob, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		logger.Error("Failed to encode equivocation evidence", "err", err)
+		return
+	}
+	if err := sb.db.Put(equivocationKey(hash), blob); err != nil {
+		logger.Error("Failed to store equivocation evidence", "err", err)
+		return
+	}
+	logger.Warn("Stored equivocation evidence", "hash", hash)
+}
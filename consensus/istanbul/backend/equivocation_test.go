@@ -0,0 +1,109 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// msgCommit is the code of istanbul COMMIT messages
+const msgCommit uint64 = 2
+
+// newSignedCommit returns the payload of a COMMIT message for the given digest,
+// as the istanbul core encodes and signs it.
+func newSignedCommit(view *istanbul.View, digest common.Hash, key *ecdsa.PrivateKey) []byte {
+	subject, _ := rlp.EncodeToBytes(&istanbul.Subject{View: view, Digest: digest})
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	data, _ := rlp.EncodeToBytes([]interface{}{msgCommit, subject, address, []byte{}, []byte{}})
+	sig, _ := crypto.Sign(crypto.Keccak256(data), key)
+	payload, _ := rlp.EncodeToBytes([]interface{}{msgCommit, subject, address, sig, []byte{}})
+	return payload
+}
+
+func TestReportEquivocation(t *testing.T) {
+	_, engine := newBlockChain(1, true)
+	api := &API{istanbul: engine}
+
+	key, _ := crypto.GenerateKey()
+	view := &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}
+	evidence := &istanbul.Equivocation{
+		Signer: crypto.PubkeyToAddress(key.PublicKey),
+		Code:   msgCommit,
+		View:   view,
+		Messages: [][]byte{
+			newSignedCommit(view, common.HexToHash("0x1"), key),
+			newSignedCommit(view, common.HexToHash("0x2"), key),
+		},
+	}
+
+	// Invalid evidence is discarded
+	engine.ReportEquivocation(&istanbul.Equivocation{Signer: evidence.Signer, Code: msgCommit, View: view, Messages: evidence.Messages[:1]})
+	if results, _ := api.GetEquivocations(); len(results) != 0 {
+		t.Fatalf("equivocations mismatch: have %d, want 0", len(results))
+	}
+
+	// Evidence is only stored once
+	engine.ReportEquivocation(evidence)
+	engine.ReportEquivocation(evidence)
+	results, err := api.GetEquivocations()
+	if err != nil {
+		t.Fatalf("failed to get equivocations: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("equivocations mismatch: have %d, want 1", len(results))
+	}
+	if result := results[0]; result.Hash != evidence.Hash() || result.Signer != evidence.Signer || result.Sequence.ToInt().Cmp(view.Sequence) != 0 || len(result.Messages) != 2 {
+		t.Errorf("equivocation mismatch: have %+v", result)
+	}
+
+	exported, err := api.ExportEquivocation(evidence.Hash())
+	if err != nil {
+		t.Fatalf("failed to export equivocation: %v", err)
+	}
+	if encoded, _ := rlp.EncodeToBytes(evidence); !bytes.Equal(exported, encoded) {
+		t.Errorf("exported evidence mismatch: have %x, want %x", exported, encoded)
+	}
+	if _, err := api.ExportEquivocation(common.Hash{}); err != errUnknownEquivocation {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnknownEquivocation)
+	}
+
+	// Evidence is listed in the order of the views it was signed in
+	earlier := &istanbul.View{Round: big.NewInt(2), Sequence: big.NewInt(0)}
+	engine.ReportEquivocation(&istanbul.Equivocation{
+		Signer: evidence.Signer,
+		Code:   msgCommit,
+		View:   earlier,
+		Messages: [][]byte{
+			newSignedCommit(earlier, common.HexToHash("0x1"), key),
+			newSignedCommit(earlier, common.HexToHash("0x2"), key),
+		},
+	})
+	if results, _ = api.GetEquivocations(); len(results) != 2 {
+		t.Fatalf("equivocations mismatch: have %d, want 2", len(results))
+	}
+	if results[0].Sequence.ToInt().Sign() != 0 || results[1].Hash != evidence.Hash() {
+		t.Errorf("equivocations order mismatch: have %v, %v", results[0].Sequence, results[1].Sequence)
+	}
+}
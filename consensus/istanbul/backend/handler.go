@@ -26,9 +26,20 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 )
 
+// Istanbul protocol versions. Peers of different versions cannot decode each
+// other's consensus messages, so only the latest version is advertised.
+const (
+	istanbul64 = 64
+	istanbul65 = 65 // Certificates reference the re-proposed proposal by hash
+	istanbul66 = 66 // Announced enode URLs are encrypted, and shared with sentries by istanbulValEnodeShareMsg
+)
+
 const (
 	istanbulMsg         = 0x11
 	istanbulAnnounceMsg = 0x12
+	// istanbulValEnodeShareMsg is sent by a validator behind sentries to share
+	// with them the enode URLs announced to it
+	istanbulValEnodeShareMsg = 0x13
 )
 
 var (
@@ -40,8 +51,8 @@ var (
 func (sb *Backend) Protocol() consensus.Protocol {
 	return consensus.Protocol{
 		Name:     "istanbul",
-		Versions: []uint{64},
-		Lengths:  []uint64{19},
+		Versions: []uint{istanbul66},
+		Lengths:  []uint64{20},
 		Primary:  true,
 	}
 }
@@ -51,8 +62,8 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
 
-	if (msg.Code == istanbulMsg) || (msg.Code == istanbulAnnounceMsg) {
-		if !sb.coreStarted && (msg.Code == istanbulMsg) {
+	if (msg.Code == istanbulMsg) || (msg.Code == istanbulAnnounceMsg) || (msg.Code == istanbulValEnodeShareMsg) {
+		if !sb.coreStarted && !sb.isSentry() && (msg.Code == istanbulMsg) {
 			return true, istanbul.ErrStoppedEngine
 		}
 
@@ -80,12 +91,16 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		}
 		sb.knownMessages.Add(hash, true)
 
-		if msg.Code == istanbulMsg {
+		if msg.Code == istanbulMsg && sb.isSentry() {
+			go sb.relay(addr, data)
+		} else if msg.Code == istanbulMsg {
 			go sb.istanbulEventMux.Post(istanbul.MessageEvent{
 				Payload: data,
 			})
 		} else if msg.Code == istanbulAnnounceMsg {
 			go sb.handleIstAnnounce(data)
+		} else if msg.Code == istanbulValEnodeShareMsg && sb.isSentry() && addr == sb.validatorAddress() {
+			go sb.handleValEnodeShare(data)
 		}
 
 		return true, nil
@@ -101,7 +116,7 @@ func (sb *Backend) SetBroadcaster(broadcaster consensus.Broadcaster) {
 func (sb *Backend) NewChainHead() error {
 	sb.coreMu.RLock()
 	defer sb.coreMu.RUnlock()
-	if !sb.coreStarted {
+	if !sb.coreStarted && !sb.isSentry() {
 		return istanbul.ErrStoppedEngine
 	}
 
@@ -114,6 +129,11 @@ func (sb *Backend) NewChainHead() error {
 		go sb.RefreshValPeers(sb.getValidators(currentBlock.Number().Uint64(), currentBlock.Hash()))
 	}
 
+	// Sentries only keep their validator peers up to date
+	if !sb.coreStarted {
+		return nil
+	}
+
 	go sb.istanbulEventMux.Post(istanbul.FinalCommittedEvent{})
 	return nil
 }
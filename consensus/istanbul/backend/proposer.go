@@ -0,0 +1,149 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/contracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	inmemoryProposerSets = 128 // Number of recent validator sets with proposer selection inputs to keep in memory
+	inmemoryEpochStakes  = 4   // Number of recent epochs' validator stakes to keep in memory
+
+	maxGasForGetAccountWeight uint64 = 1000000 // Gas allowance for looking up a validator's bonded stake
+	maxGasForRandom           uint64 = 1000000 // Gas allowance for looking up the last revealed randomness
+)
+
+// needsProposerSelectionInputs returns whether the proposer policy draws from
+// chain state besides the validator set.
+func needsProposerSelectionInputs(policy istanbul.ProposerPolicy) bool {
+	return policy == istanbul.StakeWeighted || policy == istanbul.ShuffledRoundRobin
+}
+
+// withProposerSelectionInputs returns a copy of the validator set that proposes
+// the block after the given one, with the validators' bonded stakes at the start
+// of the epoch and the randomness revealed in the given block. Inputs are only
+// left unset, making the policies fall back to round robin, if the given block's
+// state shows the contracts providing them are not deployed, which all nodes
+// agree on. Inputs that cannot be read otherwise, e.g. because the state is not
+// available yet, are an error, as other nodes may select another proposer.
+func (sb *Backend) withProposerSelectionInputs(valSet istanbul.ValidatorSet, number uint64, hash common.Hash) (istanbul.ValidatorSet, error) {
+	if cached, ok := sb.proposerSets.Get(hash); ok {
+		return cached.(istanbul.ValidatorSet), nil
+	}
+	if sb.stateAt == nil || sb.regAdd == nil {
+		return nil, errNoProposerSelectionInputs
+	}
+	header := sb.chain.GetHeader(hash, number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	valSet = valSet.Copy()
+
+	if sb.config.ProposerPolicy == istanbul.StakeWeighted {
+		// Blocks are final once committed, so the canonical chain holds the last
+		// block of the previous epoch, which the epoch's validator set was elected in
+		electionHeader := sb.chain.GetHeaderByNumber(number - number%sb.config.Epoch)
+		if electionHeader == nil {
+			return nil, errUnknownBlock
+		}
+		stakes, err := sb.getEpochStakes(electionHeader, valSet)
+		if err == core.ErrSmartContractNotDeployed {
+			sb.logger.Debug("Selecting proposers without stakes, bonded deposits not deployed", "number", electionHeader.Number)
+		} else if err != nil {
+			return nil, err
+		} else {
+			valSet.SetStakes(stakes.addresses, stakes.stakes)
+		}
+	}
+	randomness, err := sb.getRandomness(header)
+	if err == core.ErrSmartContractNotDeployed {
+		sb.logger.Debug("Selecting proposers without randomness, random not deployed", "number", number)
+	} else if err != nil {
+		return nil, err
+	} else {
+		valSet.SetRandomness(randomness)
+	}
+	sb.proposerSets.Add(hash, valSet)
+	return valSet, nil
+}
+
+// epochStakes are the bonded stakes of an epoch's validators.
+type epochStakes struct {
+	addresses []common.Address
+	stakes    []*big.Int
+}
+
+// getEpochStakes returns the bonded stakes of the given validators in the state
+// of the block their epoch's validator set was elected in.
+func (sb *Backend) getEpochStakes(header *types.Header, valSet istanbul.ValidatorSet) (*epochStakes, error) {
+	if cached, ok := sb.epochStakes.Get(header.Hash()); ok {
+		return cached.(*epochStakes), nil
+	}
+	state, err := sb.stateAt(header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	bondedDepositsAddress, err := sb.regAdd.GetRegisteredAddressAtStateAndHeader(params.BondedDepositsRegistryId, state, header)
+	if err != nil {
+		return nil, err
+	}
+	bondedDeposits, err := contracts.NewBondedDeposits(*bondedDepositsAddress, sb.iEvmH)
+	if err != nil {
+		return nil, err
+	}
+	stakes := &epochStakes{
+		addresses: make([]common.Address, 0, valSet.Size()),
+		stakes:    make([]*big.Int, 0, valSet.Size()),
+	}
+	for _, v := range valSet.List() {
+		stake, err := bondedDeposits.GetAccountWeight(&bind.InternalCallOpts{Gas: maxGasForGetAccountWeight, Header: header, State: state}, v.Address())
+		if err != nil {
+			return nil, err
+		}
+		stakes.addresses = append(stakes.addresses, v.Address())
+		stakes.stakes = append(stakes.stakes, stake)
+	}
+	sb.epochStakes.Add(header.Hash(), stakes)
+	return stakes, nil
+}
+
+// getRandomness returns the randomness last revealed as of the given block.
+func (sb *Backend) getRandomness(header *types.Header) (common.Hash, error) {
+	state, err := sb.stateAt(header.Hash())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	randomAddress, err := sb.regAdd.GetRegisteredAddressAtStateAndHeader(params.RandomRegistryId, state, header)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	random, err := contracts.NewRandom(*randomAddress, sb.iEvmH)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	randomness, err := random.Random(&bind.InternalCallOpts{Gas: maxGasForRandom, Header: header, State: state})
+	return common.Hash(randomness), err
+}
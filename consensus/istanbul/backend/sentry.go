@@ -0,0 +1,177 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// A validator can be run behind sentries, so that its enode is never exposed.
+// The validator connects to its sentries only, and sends its messages to them.
+// The sentries relay the messages of the validator to the other validators and
+// theirs to the validator, and are the endpoints the validator announces.
+// As the announced enode URLs are encrypted for the validators, the validator
+// shares the ones it decrypts with its sentries.
+// Sentries only relay the consensus messages of the current validators, so they
+// can't be used to flood the network.
+
+// sharedValEnode is the enode URLs of a validator, shared by a validator with
+// its sentries.
+type sharedValEnode struct {
+	Address   common.Address
+	EnodeURLs []string
+	View      *istanbul.View
+}
+
+// parseEnodes parses the given enode URLs, skipping the invalid ones.
+func parseEnodes(urls []string) []*enode.Node {
+	var nodes []*enode.Node
+	for _, url := range urls {
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			log.Error("Invalid enode", "enodeURL", url, "err", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// nodeAddress returns the address of the node key of the node, which is the
+// address peers are known by.
+func nodeAddress(node *enode.Node) common.Address {
+	return crypto.PubkeyToAddress(*node.Pubkey())
+}
+
+// isProxied returns whether the node is a validator behind sentries.
+func (sb *Backend) isProxied() bool {
+	return len(sb.sentries) > 0
+}
+
+// isSentry returns whether the node is the sentry of a validator.
+func (sb *Backend) isSentry() bool {
+	return sb.proxiedValidator != nil
+}
+
+// validatorAddress returns the address of the validator the node connects to
+// the other validators for: its own, or the one of the validator it is a
+// sentry of.
+func (sb *Backend) validatorAddress() common.Address {
+	if sb.isSentry() {
+		return nodeAddress(sb.proxiedValidator)
+	}
+	return sb.Address()
+}
+
+// ConnectSentryPeers implements consensus.Handler.ConnectSentryPeers
+func (sb *Backend) ConnectSentryPeers() {
+	if sb.broadcaster == nil {
+		return
+	}
+	for _, node := range sb.sentries {
+		sb.logger.Info("Connecting to sentry", "enode", node)
+		sb.broadcaster.AddValidatorPeer(node.String())
+	}
+	if sb.isSentry() {
+		sb.logger.Info("Connecting to proxied validator", "enode", sb.proxiedValidator)
+		sb.broadcaster.AddValidatorPeer(sb.proxiedValidator.String())
+	}
+}
+
+// sentryAddresses returns the addresses of the sentries of the validator.
+func (sb *Backend) sentryAddresses() map[common.Address]bool {
+	addresses := make(map[common.Address]bool)
+	for _, node := range sb.sentries {
+		addresses[nodeAddress(node)] = true
+	}
+	return addresses
+}
+
+// validatorPeerAddresses returns the addresses of the validator peers, which
+// are validators or their sentries.
+func (sb *Backend) validatorPeerAddresses() []common.Address {
+	var addresses []common.Address
+	for _, url := range sb.GetValidatorPeers() {
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			continue
+		}
+		addresses = append(addresses, nodeAddress(node))
+	}
+	return addresses
+}
+
+// relay forwards an istanbul message received by a sentry: the messages of its
+// validator to the other validators, and the ones of the other validators to
+// its validator. Only messages signed by a validator of the current validator
+// set are relayed, each once, as HandleMsg drops the messages already known.
+func (sb *Backend) relay(from common.Address, payload []byte) {
+	block := sb.currentBlock()
+	valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
+	if _, err := istanbulCore.VerifyMessage(payload, valSet); err != nil {
+		sb.logger.Debug("Not relaying an invalid istanbul message", "from", from, "err", err)
+		return
+	}
+
+	validator := nodeAddress(sb.proxiedValidator)
+
+	targets := make(map[common.Address]bool)
+	if from == validator {
+		for _, addr := range sb.validatorPeerAddresses() {
+			if addr != validator {
+				targets[addr] = true
+			}
+		}
+	} else {
+		targets[validator] = true
+	}
+	sb.gossipTo(targets, payload, istanbulMsg, false)
+}
+
+// shareValEnode sends the enode URLs of a validator to the sentries.
+func (sb *Backend) shareValEnode(address common.Address, enodeURLs []string, view *istanbul.View) {
+	payload, err := rlp.EncodeToBytes(&sharedValEnode{Address: address, EnodeURLs: enodeURLs, View: view})
+	if err != nil {
+		sb.logger.Error("Error in encoding a shared valenode entry", "address", address, "err", err)
+		return
+	}
+	sb.gossipTo(sb.sentryAddresses(), payload, istanbulValEnodeShareMsg, true)
+}
+
+// handleValEnodeShare saves the enode URLs of a validator shared by the validator
+// this node is a sentry of.
+func (sb *Backend) handleValEnodeShare(payload []byte) error {
+	shared := new(sharedValEnode)
+	if err := rlp.DecodeBytes(payload, shared); err != nil {
+		sb.logger.Error("Error in decoding a shared valenode entry", "err", err)
+		return err
+	}
+	if shared.Address == sb.validatorAddress() {
+		return nil
+	}
+	if err := sb.upsertValEnode(shared.Address, shared.EnodeURLs, shared.View); err != nil {
+		sb.logger.Error("Error in upserting a shared valenode entry", "address", shared.Address, "err", err)
+		return err
+	}
+	return nil
+}
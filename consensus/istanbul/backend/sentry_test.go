@@ -0,0 +1,215 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type sentMessage struct {
+	to   common.Address
+	code uint64
+}
+
+type testPeer struct {
+	address common.Address
+	sent    chan sentMessage
+}
+
+func (p *testPeer) Send(msgcode uint64, data interface{}) error {
+	p.sent <- sentMessage{to: p.address, code: msgcode}
+	return nil
+}
+
+// testBroadcaster is connected to the given peers, the validator peers among them
+// being the ones with an enode URL.
+type testBroadcaster struct {
+	peers          map[common.Address]consensus.Peer
+	validatorPeers []string
+	added          []string
+}
+
+func newTestBroadcaster(sent chan sentMessage, nodes []*enode.Node, validatorPeers []*enode.Node) *testBroadcaster {
+	b := &testBroadcaster{peers: make(map[common.Address]consensus.Peer)}
+	for _, node := range nodes {
+		b.peers[nodeAddress(node)] = &testPeer{address: nodeAddress(node), sent: sent}
+	}
+	for _, node := range validatorPeers {
+		b.validatorPeers = append(b.validatorPeers, node.String())
+	}
+	return b
+}
+
+func (b *testBroadcaster) Enqueue(id string, block *types.Block) {}
+
+func (b *testBroadcaster) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	m := make(map[common.Address]consensus.Peer)
+	for addr, p := range b.peers {
+		if targets == nil || targets[addr] {
+			m[addr] = p
+		}
+	}
+	return m
+}
+
+func (b *testBroadcaster) GetLocalNode() *enode.Node { return nil }
+
+func (b *testBroadcaster) AddValidatorPeer(enodeURL string) error {
+	b.added = append(b.added, enodeURL)
+	return nil
+}
+
+func (b *testBroadcaster) RemoveValidatorPeer(enodeURL string) error { return nil }
+
+func (b *testBroadcaster) GetValidatorPeers() []string { return b.validatorPeers }
+
+func newTestNode() *enode.Node {
+	key, _ := crypto.GenerateKey()
+	return enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303)
+}
+
+// receivers returns the peers that were sent a message within a short time.
+func receivers(sent chan sentMessage) map[common.Address]bool {
+	received := make(map[common.Address]bool)
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case msg := <-sent:
+			received[msg.to] = true
+		case <-timeout:
+			return received
+		}
+	}
+}
+
+func TestProxiedValidatorGossip(t *testing.T) {
+	sentry, other := newTestNode(), newTestNode()
+	sent := make(chan sentMessage, 10)
+
+	config := *istanbul.DefaultConfig
+	config.Sentries = []string{sentry.String()}
+	b := New(&config, ethdb.NewMemDatabase(), nil).(*Backend)
+	broadcaster := newTestBroadcaster(sent, []*enode.Node{sentry, other}, nil)
+	b.SetBroadcaster(broadcaster)
+
+	b.ConnectSentryPeers()
+	if len(broadcaster.added) != 1 || broadcaster.added[0] != sentry.String() {
+		t.Errorf("validator peers mismatch: have %v, want %v", broadcaster.added, []string{sentry.String()})
+	}
+
+	// Other validators are neither connected to nor sent messages to directly
+	b.AddValidatorPeer(other.String())
+	if len(broadcaster.added) != 1 {
+		t.Errorf("validator peers mismatch: have %v, want %v", broadcaster.added, []string{sentry.String()})
+	}
+	valSet := validator.NewSet([]common.Address{nodeAddress(other)}, istanbul.RoundRobin)
+	b.Gossip(valSet, []byte("data"), istanbulMsg, false)
+	if received := receivers(sent); len(received) != 1 || !received[nodeAddress(sentry)] {
+		t.Errorf("receivers mismatch: have %v, want %v", received, nodeAddress(sentry))
+	}
+}
+
+// newIstanbulMessage returns the payload of a consensus message signed with the
+// given key.
+func newIstanbulMessage(data []byte, key *ecdsa.PrivateKey) []byte {
+	msg := struct {
+		Code          uint64
+		Msg           []byte
+		Address       common.Address
+		Signature     []byte
+		CommittedSeal []byte
+	}{Msg: data, Address: crypto.PubkeyToAddress(key.PublicKey)}
+	payloadNoSig, _ := rlp.EncodeToBytes(&msg)
+	msg.Signature, _ = crypto.Sign(crypto.Keccak256(payloadNoSig), key)
+	payload, _ := rlp.EncodeToBytes(&msg)
+	return payload
+}
+
+func TestSentryRelay(t *testing.T) {
+	proxied, otherSentry, fullNode := newTestNode(), newTestNode(), newTestNode()
+	sent := make(chan sentMessage, 10)
+
+	// The sentry relays the messages of the validator of the single validator chain
+	_, b := newBlockChain(1, true)
+	b.proxiedValidator = proxied
+	validatorKey := b.nodeKey
+	broadcaster := newTestBroadcaster(sent, []*enode.Node{proxied, otherSentry, fullNode}, []*enode.Node{proxied, otherSentry})
+	b.SetBroadcaster(broadcaster)
+
+	b.ConnectSentryPeers()
+	if len(broadcaster.added) != 1 || broadcaster.added[0] != proxied.String() {
+		t.Errorf("validator peers mismatch: have %v, want %v", broadcaster.added, []string{proxied.String()})
+	}
+
+	// Messages of the validator are relayed to the other validators, once
+	payload := newIstanbulMessage([]byte("data1"), validatorKey)
+	for _, from := range []*enode.Node{proxied, fullNode} {
+		if _, err := b.HandleMsg(nodeAddress(from), makeMsg(istanbulMsg, payload)); err != nil {
+			t.Fatalf("handle message failed: %v", err)
+		}
+	}
+	if received := receivers(sent); len(received) != 1 || !received[nodeAddress(otherSentry)] {
+		t.Errorf("receivers mismatch: have %v, want %v", received, nodeAddress(otherSentry))
+	}
+
+	// Messages of the other validators are relayed to the validator
+	if _, err := b.HandleMsg(nodeAddress(otherSentry), makeMsg(istanbulMsg, newIstanbulMessage([]byte("data2"), validatorKey))); err != nil {
+		t.Fatalf("handle message failed: %v", err)
+	}
+	if received := receivers(sent); len(received) != 1 || !received[nodeAddress(proxied)] {
+		t.Errorf("receivers mismatch: have %v, want %v", received, nodeAddress(proxied))
+	}
+
+	// Messages that aren't signed by a validator are not relayed
+	key, _ := crypto.GenerateKey()
+	for _, payload := range [][]byte{newIstanbulMessage([]byte("data3"), key), []byte("data4")} {
+		if _, err := b.HandleMsg(nodeAddress(otherSentry), makeMsg(istanbulMsg, payload)); err != nil {
+			t.Fatalf("handle message failed: %v", err)
+		}
+	}
+	if received := receivers(sent); len(received) != 0 {
+		t.Errorf("receivers mismatch: have %v, want none", received)
+	}
+}
+
+func TestProxiedValidatorAnnounce(t *testing.T) {
+	sentries := []*enode.Node{newTestNode(), newTestNode()}
+
+	config := *istanbul.DefaultConfig
+	config.Sentries = []string{sentries[0].String(), sentries[1].String()}
+	b := New(&config, ethdb.NewMemDatabase(), nil).(*Backend)
+
+	// The validator announces all of its sentries
+	want := []string{sentries[0].String(), sentries[1].String()}
+	if have := b.announcedEnodeURLs(); !reflect.DeepEqual(have, want) {
+		t.Errorf("announced enode URLs mismatch: have %v, want %v", have, want)
+	}
+}
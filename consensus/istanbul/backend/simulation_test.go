@@ -0,0 +1,762 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// The simulation runs validators with their own backend and blockchain in the
+// same process, connected by a simulated network which can delay, drop and
+// reorder their messages, partition them, and make some of them byzantine.
+// Faults are drawn from a seeded source, and the engines run on a clock the
+// simulation controls, which moves on to the next timer once the network is
+// idle. Block periods, message delays and round change timeouts thus take no
+// wall time, and runs are reproducible up to the scheduling of goroutines.
+
+// Codes of the istanbul core messages the simulation tampers with
+const (
+	simMsgPrepare uint64 = 1
+	simMsgCommit  uint64 = 2
+)
+
+// simMessage mirrors the wire format of the istanbul core messages.
+type simMessage struct {
+	Code          uint64
+	Msg           []byte
+	Address       common.Address
+	Signature     []byte
+	CommittedSeal []byte
+}
+
+// behaviour is how a validator of the simulation deviates from the protocol.
+type behaviour int
+
+const (
+	honest       behaviour = iota
+	silent                 // Sends no messages
+	equivocating           // Signs a conflicting PREPARE and COMMIT for every one it sends
+)
+
+// simIdleTimeout is how long the network has to be idle before the simulated
+// clock moves on to the next timer.
+const simIdleTimeout = 10 * time.Millisecond
+
+// simClock is the clock of the engines and the network in the simulation. It
+// only moves when the simulation fires its timers.
+type simClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*simTimer // Pending timers, in the order they were scheduled
+}
+
+// simTimer is a call scheduled on the simulated clock.
+type simTimer struct {
+	clock *simClock
+	at    time.Time
+	f     func()
+}
+
+func (c *simClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *simClock) AfterFunc(d time.Duration, f func()) istanbul.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &simTimer{clock: c, at: c.now.Add(d), f: f}
+	if d <= 0 {
+		go f()
+	} else {
+		c.timers = append(c.timers, t)
+	}
+	return t
+}
+
+func (t *simTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fireNext moves the clock to the earliest pending timer and fires all the
+// timers due then. If no timer is due by the given time, the clock moves to it
+// instead, and fireNext returns false.
+func (c *simClock) fireNext(until time.Time) bool {
+	c.mu.Lock()
+	next := until
+	for _, t := range c.timers {
+		if t.at.Before(next) {
+			next = t.at
+		}
+	}
+	if next.After(c.now) {
+		c.now = next
+	}
+	var due, pending []*simTimer
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, t := range due {
+		go t.f()
+	}
+	return len(due) > 0
+}
+
+type simNode struct {
+	sim     *simulation
+	index   int
+	key     *ecdsa.PrivateKey
+	address common.Address
+	enode   *enode.Node
+	db      ethdb.Database
+	chain   *core.BlockChain
+	backend *Backend
+
+	behaviour behaviour
+	results   chan *types.Block
+	stop      chan struct{} // Stops the pending seal
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// simPeer is the connection of a node to another one of the simulation.
+type simPeer struct {
+	from, to *simNode
+}
+
+func (p *simPeer) Send(msgcode uint64, data interface{}) error {
+	payload, ok := data.([]byte)
+	if !ok {
+		return errDecodeFailed
+	}
+	switch p.from.behaviourOf() {
+	case silent:
+		return nil
+	case equivocating:
+		// Peers with an odd index also get a conflicting message, so that they
+		// see both
+		if msgcode == istanbulMsg && p.to.index%2 == 1 {
+			if forged := p.from.forge(payload); forged != nil {
+				p.from.sim.send(p.from, p.to, msgcode, forged)
+			}
+		}
+	}
+	p.from.sim.send(p.from, p.to, msgcode, payload)
+	return nil
+}
+
+// simBroadcaster connects a node to all the other nodes of the simulation.
+type simBroadcaster struct {
+	node *simNode
+}
+
+func (b *simBroadcaster) Enqueue(id string, block *types.Block) {
+	go b.node.insert(types.Blocks{block})
+}
+
+func (b *simBroadcaster) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	m := make(map[common.Address]consensus.Peer)
+	for _, node := range b.node.sim.nodes {
+		if node != b.node && (targets == nil || targets[node.address]) {
+			m[node.address] = &simPeer{from: b.node, to: node}
+		}
+	}
+	return m
+}
+
+func (b *simBroadcaster) GetLocalNode() *enode.Node { return b.node.enode }
+
+func (b *simBroadcaster) AddValidatorPeer(enodeURL string) error { return nil }
+
+func (b *simBroadcaster) RemoveValidatorPeer(enodeURL string) error { return nil }
+
+func (b *simBroadcaster) GetValidatorPeers() []string { return nil }
+
+type simulation struct {
+	genesis *core.Genesis
+	config  istanbul.Config
+	clock   *simClock
+	nodes   []*simNode
+
+	activity chan struct{} // Signals messages and blocks moving through the network
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	drop      float64       // Probability of a message to be dropped
+	maxDelay  time.Duration // Messages are delayed by up to maxDelay, which reorders them
+	partition map[common.Address]int
+	closed    bool
+}
+
+// newSimulation starts n validators, connected by a network without faults.
+func newSimulation(t *testing.T, n int, seed int64) *simulation {
+	genesis, keys := getGenesisAndKeys(n, true)
+
+	sim := &simulation{
+		genesis:  genesis,
+		config:   *istanbul.DefaultConfig,
+		clock:    &simClock{now: time.Unix(int64(genesis.Timestamp), 0)},
+		activity: make(chan struct{}, 1),
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+	sim.config.RequestTimeout = 3000
+
+	for i, key := range keys {
+		node, err := sim.newNode(i, key)
+		if err != nil {
+			sim.close()
+			t.Fatalf("failed to create node %d: %v", i, err)
+		}
+		sim.nodes = append(sim.nodes, node)
+	}
+	for _, node := range sim.nodes {
+		if err := node.start(); err != nil {
+			sim.close()
+			t.Fatalf("failed to start node %d: %v", node.index, err)
+		}
+	}
+	return sim
+}
+
+func (sim *simulation) newNode(index int, key *ecdsa.PrivateKey) (*simNode, error) {
+	node := &simNode{
+		sim:     sim,
+		index:   index,
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		enode:   enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303+index, 30303+index),
+		db:      ethdb.NewMemDatabase(),
+		results: make(chan *types.Block, 1),
+		quit:    make(chan struct{}),
+	}
+	sim.genesis.MustCommit(node.db)
+
+	config := sim.config
+	node.backend = New(&config, node.db, key).(*Backend)
+	node.backend.clock = sim.clock
+	node.backend.Authorize(node.address, func(_ accounts.Account, data []byte) ([]byte, error) {
+		return crypto.Sign(data, key)
+	})
+
+	chain, err := core.NewBlockChain(node.db, nil, sim.genesis.Config, node.backend, vm.Config{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	node.chain = chain
+
+	iEvmH := core.NewInternalEVMHandler(chain)
+	regAdd := core.NewRegisteredAddresses(iEvmH)
+	iEvmH.SetRegisteredAddresses(regAdd)
+	node.backend.SetInternalEVMHandler(iEvmH)
+	node.backend.SetRegisteredAddresses(regAdd)
+	node.backend.SetGasPriceMinimum(core.NewGasPriceMinimum(iEvmH, regAdd))
+	node.backend.SetChain(chain, chain.CurrentBlock)
+	node.backend.SetBroadcaster(&simBroadcaster{node: node})
+	return node, nil
+}
+
+func (n *simNode) start() error {
+	chain := n.chain
+	err := n.backend.Start(chain.HasBadBlock,
+		func(parentHash common.Hash) (*state.StateDB, error) {
+			return chain.StateAt(chain.GetHeaderByHash(parentHash).Root)
+		},
+		func(block *types.Block, state *state.StateDB) (types.Receipts, []*types.Log, uint64, error) {
+			return chain.Processor().Process(block, state, *chain.GetVMConfig())
+		},
+		func(block *types.Block, state *state.StateDB, receipts types.Receipts, usedGas uint64) error {
+			return chain.Validator().ValidateState(block, nil, state, receipts, usedGas)
+		})
+	if err != nil {
+		return err
+	}
+	n.wg.Add(1)
+	go n.loop()
+	return nil
+}
+
+// loop seals a block on every new head, as the miner does.
+func (n *simNode) loop() {
+	defer n.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := n.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	n.seal()
+	for {
+		select {
+		case <-heads:
+			n.backend.NewChainHead()
+			n.seal()
+		case block := <-n.results:
+			go n.insert(types.Blocks{block})
+		case <-n.quit:
+			if n.stop != nil {
+				close(n.stop)
+			}
+			return
+		}
+	}
+}
+
+// seal proposes a block on the current head, which the engine holds back until
+// the block period elapsed on the simulated clock.
+func (n *simNode) seal() {
+	if n.stop != nil {
+		close(n.stop)
+	}
+	n.stop = make(chan struct{})
+
+	parent := n.chain.CurrentBlock()
+	block, err := n.makeBlock(parent)
+	if err != nil {
+		n.backend.logger.Error("Failed to make block in simulation", "number", parent.NumberU64()+1, "err", err)
+		return
+	}
+	n.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer n.wg.Done()
+		if err := n.backend.Seal(n.chain, block, n.results, stop); err != nil {
+			n.backend.logger.Error("Failed to seal block in simulation", "number", block.Number(), "err", err)
+		}
+	}(n.stop)
+}
+
+// makeBlock assembles an empty block on the given parent, as the miner does.
+func (n *simNode) makeBlock(parent *types.Block) (*types.Block, error) {
+	header := makeHeader(parent, n.backend.config)
+	if err := n.backend.Prepare(n.chain, header); err != nil {
+		return nil, err
+	}
+	state, err := n.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	if err := n.backend.UpdateValSetDiff(n.chain, header, state); err != nil {
+		return nil, err
+	}
+	return n.backend.Finalize(n.chain, header, state, nil, nil, nil, nil)
+}
+
+// insert imports blocks, and sends them on to the connected nodes.
+func (n *simNode) insert(blocks types.Blocks) {
+	n.sim.touch()
+	defer n.sim.touch()
+
+	if _, err := n.chain.InsertChain(blocks); err != nil {
+		n.backend.logger.Error("Failed to insert blocks in simulation", "number", blocks[0].Number(), "err", err)
+		return
+	}
+	for _, node := range n.sim.nodes {
+		if node != n {
+			n.sim.propagate(n, node)
+		}
+	}
+}
+
+// syncFrom imports the blocks of the given node that are missing, as the
+// downloader would.
+func (n *simNode) syncFrom(src *simNode) {
+	var blocks types.Blocks
+	for block := src.chain.CurrentBlock(); block.NumberU64() > 0 && !n.chain.HasBlock(block.Hash(), block.NumberU64()); {
+		blocks = append(types.Blocks{block}, blocks...)
+		if block = src.chain.GetBlock(block.ParentHash(), block.NumberU64()-1); block == nil {
+			return
+		}
+	}
+	if len(blocks) > 0 {
+		n.insert(blocks)
+	}
+}
+
+func (n *simNode) behaviourOf() behaviour {
+	n.sim.mu.Lock()
+	defer n.sim.mu.Unlock()
+	return n.behaviour
+}
+
+// forge returns the given PREPARE or COMMIT message signed for another digest,
+// or nil for other messages.
+func (n *simNode) forge(payload []byte) []byte {
+	var msg simMessage
+	if err := rlp.DecodeBytes(payload, &msg); err != nil || (msg.Code != simMsgPrepare && msg.Code != simMsgCommit) {
+		return nil
+	}
+	var subject *istanbul.Subject
+	if err := rlp.DecodeBytes(msg.Msg, &subject); err != nil {
+		return nil
+	}
+	subject.Digest = crypto.Keccak256Hash(subject.Digest.Bytes())
+	msg.Msg, _ = rlp.EncodeToBytes(subject)
+	if msg.Code == simMsgCommit {
+		msg.CommittedSeal, _ = crypto.Sign(crypto.Keccak256(istanbulCore.PrepareCommittedSeal(subject.Digest)), n.key)
+	}
+
+	msg.Signature = []byte{}
+	data, _ := rlp.EncodeToBytes(&msg)
+	msg.Signature, _ = crypto.Sign(crypto.Keccak256(data), n.key)
+	forged, _ := rlp.EncodeToBytes(&msg)
+	return forged
+}
+
+// connected returns whether the nodes are in the same partition.
+func (sim *simulation) connected(from, to *simNode) bool {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return !sim.closed && (sim.partition == nil || sim.partition[from.address] == sim.partition[to.address])
+}
+
+// send delivers a message after a random delay, unless it is dropped.
+func (sim *simulation) send(from, to *simNode, code uint64, payload []byte) {
+	if !sim.connected(from, to) {
+		return
+	}
+	sim.mu.Lock()
+	dropped := sim.rand.Float64() < sim.drop
+	delay := time.Duration(0)
+	if sim.maxDelay > 0 {
+		delay = time.Duration(sim.rand.Int63n(int64(sim.maxDelay)))
+	}
+	sim.mu.Unlock()
+	if dropped {
+		return
+	}
+
+	sim.clock.AfterFunc(delay, func() {
+		if sim.connected(from, to) {
+			sim.touch()
+			to.backend.HandleMsg(from.address, makeMsg(code, payload))
+		}
+	})
+}
+
+// touch notes that the network is not idle.
+func (sim *simulation) touch() {
+	select {
+	case sim.activity <- struct{}{}:
+	default:
+	}
+}
+
+// run drives the simulation until the condition holds, or the given duration
+// elapsed on the simulated clock. It returns whether the condition holds.
+func (sim *simulation) run(d time.Duration, cond func() bool) bool {
+	deadline := sim.clock.Now().Add(d)
+	for !cond() {
+		select {
+		case <-sim.activity:
+			continue
+		case <-time.After(simIdleTimeout):
+		}
+		if !sim.clock.fireNext(deadline) && !sim.clock.Now().Before(deadline) {
+			return cond()
+		}
+	}
+	return true
+}
+
+// propagate lets a node catch up with the chain of another one.
+func (sim *simulation) propagate(from, to *simNode) {
+	if sim.connected(from, to) {
+		go to.syncFrom(from)
+	}
+}
+
+// setFaults drops messages with the given probability, and delays them by up to
+// maxDelay.
+func (sim *simulation) setFaults(drop float64, maxDelay time.Duration) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.drop, sim.maxDelay = drop, maxDelay
+}
+
+func (sim *simulation) setBehaviour(index int, b behaviour) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.nodes[index].behaviour = b
+}
+
+// split partitions the nodes in the given groups of indexes. Messages between
+// groups are dropped.
+func (sim *simulation) split(groups ...[]int) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.partition = make(map[common.Address]int)
+	for i, group := range groups {
+		for _, index := range group {
+			sim.partition[sim.nodes[index].address] = i
+		}
+	}
+}
+
+// heal removes the partition, and lets the nodes catch up with each other.
+func (sim *simulation) heal() {
+	sim.mu.Lock()
+	sim.partition = nil
+	sim.mu.Unlock()
+
+	for _, from := range sim.nodes {
+		for _, to := range sim.nodes {
+			if from != to {
+				sim.propagate(from, to)
+			}
+		}
+	}
+}
+
+func (sim *simulation) close() {
+	sim.mu.Lock()
+	sim.closed = true
+	sim.mu.Unlock()
+
+	for _, node := range sim.nodes {
+		close(node.quit)
+		node.wg.Wait()
+		node.backend.Stop()
+		node.chain.Stop()
+	}
+}
+
+// height returns the height of the node with the given index.
+func (sim *simulation) height(index int) uint64 {
+	return sim.nodes[index].chain.CurrentBlock().NumberU64()
+}
+
+// status describes the heights and consensus views of the nodes.
+func (sim *simulation) status() string {
+	var lines []string
+	for i, node := range sim.nodes {
+		line := fmt.Sprintf("node %d: height %d", i, sim.height(i))
+		if status := node.backend.core.Status(); status != nil {
+			line += fmt.Sprintf(", view %v, state %s, waiting for round change %v", status.View, status.State, status.WaitingForRoundChange)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// waitForHeight checks the liveness of the given nodes, which must all reach the
+// given height before the timeout elapses on the simulated clock.
+func (sim *simulation) waitForHeight(t *testing.T, height uint64, timeout time.Duration, indexes ...int) {
+	reached := sim.run(timeout, func() bool {
+		for _, i := range indexes {
+			if sim.height(i) < height {
+				return false
+			}
+		}
+		return true
+	})
+	if !reached {
+		t.Fatalf("nodes %v did not reach height %d in %v:\n%s", indexes, height, timeout, sim.status())
+	}
+}
+
+// assertStalled checks that none of the given nodes commits a block for the
+// given duration of the simulated clock.
+func (sim *simulation) assertStalled(t *testing.T, duration time.Duration, indexes ...int) {
+	heights := make(map[int]uint64)
+	for _, i := range indexes {
+		heights[i] = sim.height(i)
+	}
+	sim.run(duration, func() bool {
+		for _, i := range indexes {
+			if sim.height(i) != heights[i] {
+				return true
+			}
+		}
+		return false
+	})
+	for _, i := range indexes {
+		if sim.height(i) != heights[i] {
+			t.Fatalf("node %d committed blocks while stalled: height %d, want %d", i, sim.height(i), heights[i])
+		}
+	}
+}
+
+// assertSafety checks that the nodes agree on the block of every height, and
+// that the blocks are timestamped by the simulated clock.
+func (sim *simulation) assertSafety(t *testing.T) {
+	committed := make(map[uint64]*types.Block)
+	for i, node := range sim.nodes {
+		for number := uint64(1); number <= node.chain.CurrentBlock().NumberU64(); number++ {
+			block := node.chain.GetBlockByNumber(number)
+			if first, ok := committed[number]; ok && first.Hash() != block.Hash() {
+				t.Fatalf("node %d committed block %x at height %d, another node committed %x", i, block.Hash(), number, first.Hash())
+			}
+			committed[number] = block
+		}
+	}
+	now := sim.clock.Now().Unix()
+	for number, block := range committed {
+		parent := int64(sim.genesis.Timestamp)
+		if number > 1 {
+			parent = committed[number-1].Time().Int64()
+		}
+		if have := block.Time().Int64(); have < parent+int64(sim.config.BlockPeriod) || have > now {
+			t.Errorf("timestamp mismatch at height %d: have %d, want within [%d, %d]", number, have, parent+int64(sim.config.BlockPeriod), now)
+		}
+	}
+}
+
+func TestSimulationLiveness(t *testing.T) {
+	sim := newSimulation(t, 4, 1)
+	defer sim.close()
+
+	sim.waitForHeight(t, 5, 20*time.Second, 0, 1, 2, 3)
+	sim.assertSafety(t)
+}
+
+func TestSimulationDelayedMessages(t *testing.T) {
+	sim := newSimulation(t, 4, 2)
+	defer sim.close()
+
+	// Messages arrive out of order
+	sim.setFaults(0, 100*time.Millisecond)
+	sim.waitForHeight(t, 5, 20*time.Second, 0, 1, 2, 3)
+	sim.assertSafety(t)
+}
+
+func TestSimulationDroppedMessages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lossy network simulation in short mode")
+	}
+	sim := newSimulation(t, 4, 3)
+	defer sim.close()
+
+	// Lost messages are recovered from with round changes
+	sim.setFaults(0.05, 20*time.Millisecond)
+	sim.waitForHeight(t, 3, time.Minute, 0, 1, 2, 3)
+	sim.assertSafety(t)
+}
+
+func TestSimulationSilentValidator(t *testing.T) {
+	sim := newSimulation(t, 4, 4)
+	defer sim.close()
+
+	// The other validators change rounds when the silent one is the proposer
+	sim.setBehaviour(0, silent)
+	sim.waitForHeight(t, 5, 30*time.Second, 1, 2, 3)
+	sim.assertSafety(t)
+}
+
+func TestSimulationEquivocatingValidator(t *testing.T) {
+	sim := newSimulation(t, 4, 5)
+	defer sim.close()
+
+	sim.setBehaviour(0, equivocating)
+	sim.waitForHeight(t, 4, 30*time.Second, 1, 2, 3)
+	sim.assertSafety(t)
+
+	// The validators receiving both messages have the evidence
+	for _, i := range []int{1, 3} {
+		evidences, err := loadEquivocations(sim.nodes[i].db)
+		if err != nil {
+			t.Fatalf("failed to load evidence: %v", err)
+		}
+		if len(evidences) == 0 {
+			t.Fatalf("node %d has no equivocation evidence", i)
+		}
+		for _, evidence := range evidences {
+			if evidence.Signer != sim.nodes[0].address {
+				t.Errorf("signer mismatch: have %v, want %v", evidence.Signer, sim.nodes[0].address)
+			}
+		}
+	}
+}
+
+func TestSimulationPartition(t *testing.T) {
+	sim := newSimulation(t, 4, 6)
+	defer sim.close()
+	sim.waitForHeight(t, 1, 20*time.Second, 0, 1, 2, 3)
+
+	// A quorum stays live without the isolated validator
+	sim.split([]int{0}, []int{1, 2, 3})
+	isolated := sim.height(0)
+	sim.waitForHeight(t, isolated+3, 30*time.Second, 1, 2, 3)
+	if sim.height(0) != isolated {
+		t.Fatalf("isolated node committed blocks: height %d, want %d", sim.height(0), isolated)
+	}
+
+	// The isolated validator catches up once the partition heals
+	sim.heal()
+	sim.waitForHeight(t, sim.height(1)+1, 30*time.Second, 0, 1, 2, 3)
+	sim.assertSafety(t)
+}
+
+func TestSimulationPartitionWithoutQuorum(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping partition simulation in short mode")
+	}
+	sim := newSimulation(t, 4, 7)
+	defer sim.close()
+	sim.waitForHeight(t, 1, 20*time.Second, 0, 1, 2, 3)
+
+	// Neither half has a quorum
+	sim.split([]int{0, 1}, []int{2, 3})
+	sim.run(time.Duration(sim.config.BlockPeriod)*time.Second, func() bool { return false })
+	stalled := sim.height(0)
+	for i := 1; i < 4; i++ {
+		if sim.height(i) > stalled {
+			stalled = sim.height(i)
+		}
+	}
+	sim.assertStalled(t, 30*time.Second, 0, 1, 2, 3)
+
+	sim.heal()
+	sim.waitForHeight(t, stalled+1, 2*time.Minute, 0, 1, 2, 3)
+	sim.assertSafety(t)
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -128,12 +129,18 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database) (*Snapshot,
 			return nil, err
 		}
 
+		// Validators are removed first, as the diff replacing the whole validator
+		// set when aggregated seals activate re-adds the ones that stay
+		if !snap.ValSet.RemoveValidators(istExtra.RemovedValidators) {
+			log.Error("Error in removing the header's RemovedValidators")
+			return nil, errInvalidValidatorSetDiff
+		}
 		if !snap.ValSet.AddValidators(istExtra.AddedValidators) {
 			log.Error("Error in adding the header's AddedValidators")
 			return nil, errInvalidValidatorSetDiff
 		}
-		if !snap.ValSet.RemoveValidators(istExtra.RemovedValidators) {
-			log.Error("Error in removing the header's RemovedValidators")
+		if len(istExtra.AddedValidatorsPublicKeys) > 0 && !snap.ValSet.SetBLSPublicKeys(istExtra.AddedValidators, istExtra.AddedValidatorsPublicKeys) {
+			log.Error("Error in setting the header's AddedValidatorsPublicKeys")
 			return nil, errInvalidValidatorSetDiff
 		}
 
@@ -169,17 +176,32 @@ type snapshotJSON struct {
 	Hash   common.Hash `json:"hash"`
 
 	// for validator set
-	Validators []common.Address        `json:"validators"`
-	Policy     istanbul.ProposerPolicy `json:"policy"`
+	Validators    []common.Address        `json:"validators"`
+	BLSPublicKeys []hexutil.Bytes         `json:"blsPublicKeys,omitempty"`
+	Policy        istanbul.ProposerPolicy `json:"policy"`
 }
 
 func (s *Snapshot) toJSONStruct() *snapshotJSON {
+	validators := s.validators()
+
+	// The BLS public keys are stored in the order of the validators, if any are registered
+	var publicKeys []hexutil.Bytes
+	for i, address := range validators {
+		_, v := s.ValSet.GetByAddress(address)
+		if publicKey := v.BLSPublicKey(); len(publicKey) > 0 {
+			if publicKeys == nil {
+				publicKeys = make([]hexutil.Bytes, len(validators))
+			}
+			publicKeys[i] = publicKey
+		}
+	}
 	return &snapshotJSON{
-		Epoch:      s.Epoch,
-		Number:     s.Number,
-		Hash:       s.Hash,
-		Validators: s.validators(),
-		Policy:     s.ValSet.Policy(),
+		Epoch:         s.Epoch,
+		Number:        s.Number,
+		Hash:          s.Hash,
+		Validators:    validators,
+		BLSPublicKeys: publicKeys,
+		Policy:        s.ValSet.Policy(),
 	}
 }
 
@@ -194,6 +216,15 @@ func (s *Snapshot) UnmarshalJSON(b []byte) error {
 	s.Number = j.Number
 	s.Hash = j.Hash
 	s.ValSet = validator.NewSet(j.Validators, j.Policy)
+	if len(j.BLSPublicKeys) > 0 {
+		publicKeys := make([][]byte, len(j.BLSPublicKeys))
+		for i, publicKey := range j.BLSPublicKeys {
+			publicKeys[i] = publicKey
+		}
+		if !s.ValSet.SetBLSPublicKeys(j.Validators, publicKeys) {
+			return errInvalidValidatorSetDiff
+		}
+	}
 	return nil
 }
 
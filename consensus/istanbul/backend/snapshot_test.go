@@ -214,11 +214,11 @@ func TestValSetChange(t *testing.T) {
 			Config:     params.TestChainConfig,
 		}
 		b := genesis.ToBlock(nil)
-		extra, _ := assembleExtra(b.Header(), []common.Address{}, validators)
+		extra, _ := assembleExtra(b.Header(), validators, []common.Address{})
 		genesis.ExtraData = extra
 		db := ethdb.NewMemDatabase()
 
-		config := istanbul.DefaultConfig
+		config := *istanbul.DefaultConfig
 		if tt.epoch != 0 {
 			config.Epoch = tt.epoch
 		}
@@ -227,7 +227,7 @@ func TestValSetChange(t *testing.T) {
 			headers: make(map[uint64]*types.Header),
 		}
 
-		engine := New(config, db).(*Backend)
+		engine := New(&config, db, nil).(*Backend)
 
 		privateKey := accounts.accounts[tt.validators[0]]
 		address := crypto.PubkeyToAddress(privateKey.PublicKey)
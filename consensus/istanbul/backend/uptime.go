@@ -0,0 +1,292 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	dbKeyUptimePrefix = "istanbul-uptime"
+	dbKeyUptimeHead   = "istanbul-uptime-head"
+
+	chainHeadChanSize = 10 // Size of the channel listening to new chain heads
+)
+
+// chainHeadSubscriber is implemented by chains that notify about new heads, such
+// as core.BlockChain.
+type chainHeadSubscriber interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// ValidatorUptime counts the blocks of an epoch a validator committed to, and the
+// ones it didn't.
+type ValidatorUptime struct {
+	Address              common.Address `json:"address"`
+	Signed               uint64         `json:"signed"`
+	Missed               uint64         `json:"missed"`
+	ConsecutiveMissed    uint64         `json:"consecutiveMissed"`    // Blocks missed since the validator last signed
+	MaxConsecutiveMissed uint64         `json:"maxConsecutiveMissed"` // Longest run of missed blocks in the epoch
+}
+
+// EpochUptime is the uptime of the validators over the blocks of an epoch that
+// were accounted for so far.
+type EpochUptime struct {
+	Epoch      uint64             `json:"epoch"`
+	FirstBlock uint64             `json:"firstBlock"`
+	LastBlock  uint64             `json:"lastBlock"`
+	Validators []*ValidatorUptime `json:"validators"`
+}
+
+func uptimeKey(epoch uint64) []byte {
+	key := make([]byte, len(dbKeyUptimePrefix)+8)
+	copy(key, dbKeyUptimePrefix)
+	binary.BigEndian.PutUint64(key[len(dbKeyUptimePrefix):], epoch)
+	return key
+}
+
+// loadEpochUptime loads the uptime of an epoch from the database.
+func loadEpochUptime(db ethdb.Database, epoch uint64) (*EpochUptime, error) {
+	blob, err := db.Get(uptimeKey(epoch))
+	if err != nil {
+		return nil, err
+	}
+	uptime := new(EpochUptime)
+	if err := json.Unmarshal(blob, uptime); err != nil {
+		return nil, err
+	}
+	return uptime, nil
+}
+
+// store inserts the epoch uptime into the database.
+func (u *EpochUptime) store(db ethdb.Putter) error {
+	blob, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return db.Put(uptimeKey(u.Epoch), blob)
+}
+
+// account records whether the validator committed to the latest block.
+func (u *EpochUptime) account(address common.Address, signed bool) *ValidatorUptime {
+	var uptime *ValidatorUptime
+	for _, v := range u.Validators {
+		if v.Address == address {
+			uptime = v
+			break
+		}
+	}
+	if uptime == nil {
+		uptime = &ValidatorUptime{Address: address}
+		u.Validators = append(u.Validators, uptime)
+	}
+
+	if signed {
+		uptime.Signed++
+		uptime.ConsecutiveMissed = 0
+	} else {
+		uptime.Missed++
+		uptime.ConsecutiveMissed++
+		if uptime.ConsecutiveMissed > uptime.MaxConsecutiveMissed {
+			uptime.MaxConsecutiveMissed = uptime.ConsecutiveMissed
+		}
+	}
+	return uptime
+}
+
+// committedSigners returns for every validator of the parent's validator set
+// whether it committed to the header.
+func committedSigners(header *types.Header, valSet istanbul.ValidatorSet, aggregated bool) ([]bool, error) {
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := make([]bool, valSet.Size())
+	if aggregated {
+		if extra.AggregatedSeal == nil || extra.AggregatedSeal.Bitmap == nil {
+			return nil, errEmptyCommittedSeals
+		}
+		for i := range signed {
+			signed[i] = extra.AggregatedSeal.Bitmap.Bit(i) == 1
+		}
+		return signed, nil
+	}
+
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+	for _, seal := range extra.CommittedSeal {
+		addr, err := istanbul.GetSignatureAddress(proposalSeal, seal)
+		if err != nil {
+			return nil, errInvalidSignature
+		}
+		if index, v := valSet.GetByAddress(addr); v != nil {
+			signed[index] = true
+		}
+	}
+	return signed, nil
+}
+
+// trackUptime accounts the committed seals of every chain head for the uptime of
+// the validators, until the subscription ends.
+func (sb *Backend) trackUptime(subscriber chainHeadSubscriber) {
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := subscriber.SubscribeChainHeadEvent(heads)
+	sb.uptimeSub = sub
+
+	go func() {
+		defer sub.Unsubscribe()
+
+		// Catch up with the blocks inserted while the node was down
+		if err := sb.updateUptime(sb.chain.CurrentHeader()); err != nil {
+			sb.logger.Warn("Failed to update validator uptime", "err", err)
+		}
+		for {
+			select {
+			case ev := <-heads:
+				if err := sb.updateUptime(ev.Block.Header()); err != nil {
+					sb.logger.Warn("Failed to update validator uptime", "number", ev.Block.Number(), "err", err)
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+}
+
+// updateUptime accounts the blocks of the canonical chain up to the given head
+// that haven't been accounted for yet. Nodes that have never tracked uptime start
+// at the first block of the head's epoch. Blocks are final once committed, so the
+// accounted blocks are never rolled back.
+func (sb *Backend) updateUptime(head *types.Header) error {
+	sb.uptimeMu.Lock()
+	defer sb.uptimeMu.Unlock()
+
+	next := uint64(1)
+	if blob, err := sb.db.Get([]byte(dbKeyUptimeHead)); err == nil && len(blob) == 8 {
+		next = binary.BigEndian.Uint64(blob) + 1
+	} else if first, err := istanbul.GetEpochFirstBlockNumber(istanbul.GetEpochNumber(head.Number.Uint64(), sb.config.Epoch), sb.config.Epoch); err == nil {
+		next = first
+	}
+
+	for number := next; number <= head.Number.Uint64(); number++ {
+		header := sb.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return errUnknownBlock
+		}
+		if err := sb.accountUptime(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountUptime records which validators of the parent's validator set committed
+// to the given block, and stores the epoch's uptime. Blocks whose committers
+// cannot be read are skipped, rather than retried on every new head.
+func (sb *Backend) accountUptime(header *types.Header) error {
+	number := header.Number.Uint64()
+	epoch := istanbul.GetEpochNumber(number, sb.config.Epoch)
+
+	uptime := sb.uptime
+	if uptime == nil || uptime.Epoch != epoch {
+		if uptime != nil {
+			unregisterUptimeMetrics(uptime)
+		}
+		var err error
+		if uptime, err = loadEpochUptime(sb.db, epoch); err != nil {
+			uptime = &EpochUptime{Epoch: epoch, FirstBlock: number}
+		}
+	}
+
+	if snap, signed, err := sb.committers(header); err != nil {
+		sb.logger.Warn("Skipping block in validator uptime", "number", number, "hash", header.Hash(), "err", err)
+	} else {
+		for i, val := range snap.ValSet.List() {
+			v := uptime.account(val.Address(), signed[i])
+			updateUptimeMetrics(v)
+		}
+	}
+	uptime.LastBlock = number
+
+	batch := sb.db.NewBatch()
+	if err := uptime.store(batch); err != nil {
+		return err
+	}
+	head := make([]byte, 8)
+	binary.BigEndian.PutUint64(head, number)
+	if err := batch.Put([]byte(dbKeyUptimeHead), head); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	sb.uptime = uptime
+	uptimeBlockGauge.Update(int64(number))
+	return nil
+}
+
+// committers returns the snapshot of the header's parent, and for every validator
+// of its validator set whether it committed to the header.
+func (sb *Backend) committers(header *types.Header) (*Snapshot, []bool, error) {
+	snap, err := sb.snapshot(sb.chain, header.Number.Uint64()-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	signed, err := committedSigners(header, snap.ValSet, sb.isAggregatedSeal(header.Number.Uint64(), snap.ValSet))
+	if err != nil {
+		return nil, nil, err
+	}
+	return snap, signed, nil
+}
+
+var uptimeBlockGauge = metrics.NewRegisteredGauge("consensus/istanbul/uptime/block", nil)
+
+// uptimeMetricsPrefix returns the prefix of the uptime gauges of a validator.
+func uptimeMetricsPrefix(address common.Address) string {
+	return fmt.Sprintf("consensus/istanbul/uptime/%s/", address.Hex())
+}
+
+// updateUptimeMetrics reports the validator's uptime in the current epoch.
+func updateUptimeMetrics(v *ValidatorUptime) {
+	prefix := uptimeMetricsPrefix(v.Address)
+	metrics.GetOrRegisterGauge(prefix+"signed", nil).Update(int64(v.Signed))
+	metrics.GetOrRegisterGauge(prefix+"missed", nil).Update(int64(v.Missed))
+	metrics.GetOrRegisterGauge(prefix+"consecutivemissed", nil).Update(int64(v.ConsecutiveMissed))
+}
+
+// unregisterUptimeMetrics removes the gauges of the validators of an epoch that
+// ended, so validators that left the set aren't reported forever.
+func unregisterUptimeMetrics(u *EpochUptime) {
+	for _, v := range u.Validators {
+		prefix := uptimeMetricsPrefix(v.Address)
+		metrics.Unregister(prefix + "signed")
+		metrics.Unregister(prefix + "missed")
+		metrics.Unregister(prefix + "consecutivemissed")
+	}
+}
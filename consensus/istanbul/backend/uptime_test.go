@@ -0,0 +1,116 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestValidatorUptime(t *testing.T) {
+	chain, engine := newBlockChain(1, true)
+
+	block := chain.Genesis()
+	for i := 0; i < 3; i++ {
+		parent := block
+		block = makeBlockWithoutSeal(chain, engine, parent)
+		block, _ = engine.updateBlock(parent.Header(), block)
+		seal, _ := engine.SignCommittedSeal(istanbulCore.PrepareCommittedSeal(block.Hash()), block.Number(), engine.ParentValidators(block))
+		header := block.Header()
+		writeCommittedSeals(header, [][]byte{seal})
+		block = block.WithSeal(header)
+
+		engine.clock = stoppedClock{istanbul.SystemClock, time.Unix(header.Time.Int64(), 0)}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i+1, err)
+		}
+	}
+	if err := engine.updateUptime(chain.CurrentHeader()); err != nil {
+		t.Fatalf("failed to update uptime: %v", err)
+	}
+
+	api := &API{chain: chain, istanbul: engine}
+	uptime, err := api.GetValidatorUptime(1)
+	if err != nil {
+		t.Fatalf("failed to get uptime: %v", err)
+	}
+	if uptime.FirstBlock != 1 || uptime.LastBlock != 3 {
+		t.Errorf("accounted blocks mismatch: have %d-%d, want 1-3", uptime.FirstBlock, uptime.LastBlock)
+	}
+	if len(uptime.Validators) != 1 {
+		t.Fatalf("validators mismatch: have %d, want 1", len(uptime.Validators))
+	}
+	if v := uptime.Validators[0]; v.Address != engine.Address() || v.Signed != 3 || v.Missed != 0 {
+		t.Errorf("uptime mismatch: have %+v", v)
+	}
+
+	// Accounting again doesn't count the blocks twice
+	if err := engine.updateUptime(chain.CurrentHeader()); err != nil {
+		t.Fatalf("failed to update uptime: %v", err)
+	}
+	if uptime, _ := api.GetValidatorUptime(1); uptime.Validators[0].Signed != 3 {
+		t.Errorf("signed mismatch: have %d, want 3", uptime.Validators[0].Signed)
+	}
+
+	if _, err := api.GetValidatorUptime(2); err != errUnknownEpochUptime {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnknownEpochUptime)
+	}
+
+	// Blocks whose committers cannot be read are skipped instead of retried
+	unsealed := &types.Header{Number: big.NewInt(4), ParentHash: chain.CurrentHeader().Hash()}
+	if err := engine.accountUptime(unsealed); err != nil {
+		t.Fatalf("failed to skip unsealed block: %v", err)
+	}
+	if uptime, _ := api.GetValidatorUptime(1); uptime.LastBlock != 4 || uptime.Validators[0].Signed != 3 || uptime.Validators[0].Missed != 0 {
+		t.Errorf("uptime mismatch after skipped block: have last block %d, %+v", uptime.LastBlock, uptime.Validators[0])
+	}
+}
+
+func TestUnregisterUptimeMetrics(t *testing.T) {
+	v := &ValidatorUptime{Address: common.HexToAddress("0x1"), Signed: 1}
+	updateUptimeMetrics(v)
+	name := uptimeMetricsPrefix(v.Address) + "signed"
+	if metrics.Get(name) == nil {
+		t.Fatalf("gauge %s not registered", name)
+	}
+	unregisterUptimeMetrics(&EpochUptime{Epoch: 1, Validators: []*ValidatorUptime{v}})
+	if metrics.Get(name) != nil {
+		t.Errorf("gauge %s still registered", name)
+	}
+}
+
+func TestEpochUptimeAccount(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	uptime := &EpochUptime{Epoch: 1}
+	for _, signed := range []bool{false, false, true, false, false, false, true} {
+		uptime.account(addr, signed)
+	}
+	v := uptime.Validators[0]
+	if v.Signed != 2 || v.Missed != 5 {
+		t.Errorf("counters mismatch: have signed %d missed %d, want signed 2 missed 5", v.Signed, v.Missed)
+	}
+	if v.ConsecutiveMissed != 0 || v.MaxConsecutiveMissed != 3 {
+		t.Errorf("missed streak mismatch: have %d max %d, want 0 max 3", v.ConsecutiveMissed, v.MaxConsecutiveMissed)
+	}
+}
@@ -17,34 +17,12 @@
 package backend
 
 import (
-	"strings"
-
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/params"
 )
 
-const (
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/Validators.json
-	getRegisteredValidatorsABI = `[{"constant": true,
-                                        "inputs": [],
-                                        "name": "getRegisteredValidators",
-                                        "outputs": [
-                                          {
-                                            "name": "",
-                                            "type": "address[]"
-                                          }
-                                        ],
-                                        "payable": false,
-                                        "stateMutability": "view",
-                                        "type": "function"
-                                      }]`
-)
-
-var (
-	getRegisteredValidatorsFuncABI, _ = abi.JSON(strings.NewReader(getRegisteredValidatorsABI))
-)
-
 // This function will retrieve the set of registered validators from the validator election
 // smart contract.
 func (sb *Backend) retrieveRegisteredValidators() (map[common.Address]bool, error) {
@@ -55,8 +33,12 @@ func (sb *Backend) retrieveRegisteredValidators() (map[common.Address]bool, erro
 		return nil, errValidatorsContractNotRegistered
 	} else {
 		// Get the new epoch's validator set
+		validators, err := contracts.NewValidators(*validatorAddress, sb.iEvmH)
+		if err != nil {
+			return nil, err
+		}
 		maxGasForGetRegisteredValidators := uint64(1000000)
-		if _, err := sb.iEvmH.MakeStaticCall(*validatorAddress, getRegisteredValidatorsFuncABI, "getRegisteredValidators", []interface{}{}, &regVals, maxGasForGetRegisteredValidators, nil, nil); err != nil {
+		if regVals, err = validators.GetRegisteredValidators(&bind.InternalCallOpts{Gas: maxGasForGetRegisteredValidators}); err != nil {
 			return nil, err
 		}
 	}
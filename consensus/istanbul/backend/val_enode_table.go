@@ -25,19 +25,30 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// Entries for the valEnodeTable
+// Entries for the valEnodeTable.  A validator behind sentries is reached through any of them,
+// so it has the enodeURLs of all of its sentries.
 type validatorEnode struct {
-	enodeURL string
-	view     *istanbul.View
+	enodeURLs []string
+	view      *istanbul.View
 }
 
 func (ve *validatorEnode) String() string {
-	return fmt.Sprintf("{enodeURL: %v, view: %v}", ve.enodeURL, ve.view)
+	return fmt.Sprintf("{enodeURLs: %v, view: %v}", ve.enodeURLs, ve.view)
+}
+
+// hasEnodeURL returns whether the entry has the given enodeURL.
+func (ve *validatorEnode) hasEnodeURL(enodeURL string) bool {
+	for _, url := range ve.enodeURLs {
+		if url == enodeURL {
+			return true
+		}
+	}
+	return false
 }
 
 type validatorEnodeTable struct {
 	valEnodeTable        map[common.Address]*validatorEnode
-	reverseValEnodeTable map[string]common.Address // EnodeURL -> Address mapping
+	reverseValEnodeTable map[string]common.Address // EnodeURL -> Address mapping, for each of the enodeURLs of an entry
 	valEnodeTableMu      *sync.RWMutex             // This mutex protects both valEnodeTable and reverseValEnodeTable, since they are modified at the same time
 
 	// This is used to set and remove the enodeURL validator connections.  Those adds and removes needs to be synchronized with the
@@ -90,21 +101,24 @@ func (vet *validatorEnodeTable) upsert(remoteAddress common.Address, newValEnode
 		if newValEnode.view.Cmp(oldValEnode.view) <= 0 {
 			return errOldAnnounceMessage
 		} else {
-			// Check if the valEnodeEntry has been changed
-			if (newValEnode.enodeURL != oldValEnode.enodeURL) || (newValEnode.view.Cmp(oldValEnode.view) > 0) {
-				if newValEnode.enodeURL != oldValEnode.enodeURL {
-					vet.reverseValEnodeTable[newValEnode.enodeURL] = remoteAddress
-					delete(vet.reverseValEnodeTable, oldValEnode.enodeURL)
-					// Disconnect from the peer
-					vet.removeValidatorPeer(oldValEnode.enodeURL)
+			// Disconnect from the enodeURLs that are no longer announced
+			for _, enodeURL := range oldValEnode.enodeURLs {
+				if !newValEnode.hasEnodeURL(enodeURL) {
+					delete(vet.reverseValEnodeTable, enodeURL)
+					vet.removeValidatorPeer(enodeURL)
 				}
-				vet.valEnodeTable[remoteAddress] = newValEnode
-				log.Trace("Updated an entry in the valEnodeTable", "address", remoteAddress, "ValidatorEnode", vet.valEnodeTable[remoteAddress].String())
 			}
+			for _, enodeURL := range newValEnode.enodeURLs {
+				vet.reverseValEnodeTable[enodeURL] = remoteAddress
+			}
+			vet.valEnodeTable[remoteAddress] = newValEnode
+			log.Trace("Updated an entry in the valEnodeTable", "address", remoteAddress, "ValidatorEnode", vet.valEnodeTable[remoteAddress].String())
 		}
 	} else {
 		vet.valEnodeTable[remoteAddress] = newValEnode
-		vet.reverseValEnodeTable[newValEnode.enodeURL] = remoteAddress
+		for _, enodeURL := range newValEnode.enodeURLs {
+			vet.reverseValEnodeTable[enodeURL] = remoteAddress
+		}
 		log.Trace("Created an entry in the valEnodeTable", "address", remoteAddress, "ValidatorEnode", vet.valEnodeTable[remoteAddress].String())
 	}
 
@@ -112,7 +126,9 @@ func (vet *validatorEnodeTable) upsert(remoteAddress common.Address, newValEnode
 	// if this node is also part of the current epoch's valset
 	if _, remoteNode := valSet.GetByAddress(remoteAddress); remoteNode != nil {
 		if _, localNode := valSet.GetByAddress(localAddress); localNode != nil {
-			vet.addValidatorPeer(newValEnode.enodeURL)
+			for _, enodeURL := range newValEnode.enodeURLs {
+				vet.addValidatorPeer(enodeURL)
+			}
 		}
 	}
 
@@ -126,7 +142,9 @@ func (vet *validatorEnodeTable) pruneEntries(addressesToKeep map[common.Address]
 	for remoteAddress := range vet.valEnodeTable {
 		if !addressesToKeep[remoteAddress] {
 			log.Trace("Deleting entry from the valEnodeTable and reverseValEnodeTable table", "address", remoteAddress, "valEnodeEntry", vet.valEnodeTable[remoteAddress].String())
-			delete(vet.reverseValEnodeTable, vet.valEnodeTable[remoteAddress].enodeURL)
+			for _, enodeURL := range vet.valEnodeTable[remoteAddress].enodeURLs {
+				delete(vet.reverseValEnodeTable, enodeURL)
+			}
 			delete(vet.valEnodeTable, remoteAddress)
 		}
 	}
@@ -139,7 +157,9 @@ func (vet *validatorEnodeTable) refreshValPeers(valSet istanbul.ValidatorSet, cu
 	// Add all of the valSet entries as validator peers
 	for _, val := range valSet.List() {
 		if valEnodeEntry := vet.getUsingAddress(val.Address()); valEnodeEntry != nil {
-			vet.addValidatorPeer(valEnodeEntry.enodeURL)
+			for _, enodeURL := range valEnodeEntry.enodeURLs {
+				vet.addValidatorPeer(enodeURL)
+			}
 		}
 	}
 
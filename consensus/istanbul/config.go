@@ -16,11 +16,17 @@
 
 package istanbul
 
+import (
+	"math/big"
+)
+
 type ProposerPolicy uint64
 
 const (
 	RoundRobin ProposerPolicy = iota
 	Sticky
+	StakeWeighted      // Proposers are drawn with a probability proportional to their bonded stake
+	ShuffledRoundRobin // Proposers take turns in an order shuffled with the on-chain randomness
 )
 
 type Config struct {
@@ -28,6 +34,13 @@ type Config struct {
 	BlockPeriod    uint64         `toml:",omitempty"` // Default minimum difference between two consecutive block's timestamps in second
 	ProposerPolicy ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
 	Epoch          uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
+	WAL            string         `toml:",omitempty"` // Path of the write-ahead log of signed messages, kept in memory only if empty
+	BLSKey         string         `toml:",omitempty"` // Path of the BLS key aggregated committed seals are signed with, generated if missing, ephemeral if empty
+
+	AggregatedSealBlock *big.Int `toml:"-"` // Block from whose epoch on committed seals are aggregated BLS signatures, set by the chain config
+
+	Sentries         []string `toml:",omitempty"` // Enode URLs of the sentries of a validator, which it exclusively connects to
+	ProxiedValidator string   `toml:",omitempty"` // Enode URL of the validator this node is a sentry of
 }
 
 var DefaultConfig = &Config{
@@ -36,3 +49,20 @@ var DefaultConfig = &Config{
 	ProposerPolicy: RoundRobin,
 	Epoch:          30000,
 }
+
+// IsAggregatedSeal returns whether the committed seals of the block with the
+// given number may be aggregated BLS signatures. Aggregation starts with the first
+// epoch that begins at or after AggregatedSealBlock whose validators all have BLS
+// public keys, as these are published in the last block of the epoch before.
+func (c *Config) IsAggregatedSeal(number uint64) bool {
+	if c.AggregatedSealBlock == nil {
+		return false
+	}
+	activation := c.AggregatedSealBlock.Uint64()
+	if activation > 1 && c.Epoch > 0 {
+		if offset := (activation - 1) % c.Epoch; offset != 0 {
+			activation += c.Epoch - offset
+		}
+	}
+	return number >= activation
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsAggregatedSeal(t *testing.T) {
+	tests := []struct {
+		activation *big.Int
+		number     uint64
+		expected   bool
+	}{
+		{nil, 100, false},
+		{big.NewInt(0), 0, true},
+		{big.NewInt(1), 1, true},
+		// Activation in the middle of an epoch waits for the next one
+		{big.NewInt(5), 10, false},
+		{big.NewInt(5), 11, true},
+		{big.NewInt(10), 10, false},
+		{big.NewInt(10), 11, true},
+		// Activation at the first block of an epoch is immediate
+		{big.NewInt(11), 10, false},
+		{big.NewInt(11), 11, true},
+		{big.NewInt(12), 20, false},
+		{big.NewInt(12), 21, true},
+	}
+	for _, tt := range tests {
+		config := &Config{Epoch: 10, AggregatedSealBlock: tt.activation}
+		if have := config.IsAggregatedSeal(tt.number); have != tt.expected {
+			t.Errorf("aggregated seal mismatch for block %d with activation %v: have %v, want %v", tt.number, tt.activation, have, tt.expected)
+		}
+	}
+}
@@ -99,7 +99,12 @@ func (c *core) storeBacklog(msg *message, src istanbul.Validator) {
 		if err == nil {
 			backlog.Push(msg, toPriority(msg.Code, p.View))
 		}
-		// for msgRoundChange, msgPrepare and msgCommit cases
+	case msgRoundChange:
+		_, p, err := c.decodeRoundChange(msg)
+		if err == nil {
+			backlog.Push(msg, toPriority(msg.Code, p.View))
+		}
+		// for msgPrepare and msgCommit cases
 	default:
 		var p *istanbul.Subject
 		err := msg.Decode(&p)
@@ -136,7 +141,12 @@ func (c *core) processBacklog() {
 				if err == nil {
 					view = m.View
 				}
-				// for msgRoundChange, msgPrepare and msgCommit cases
+			case msgRoundChange:
+				_, request, err := c.decodeRoundChange(msg)
+				if err == nil {
+					view = request.View
+				}
+				// for msgPrepare and msgCommit cases
 			default:
 				var sub *istanbul.Subject
 				err := msg.Decode(&sub)
@@ -170,6 +170,7 @@ func TestStoreBacklog(t *testing.T) {
 		logger:     log.New("backend", "test", "id", 0),
 		backlogs:   make(map[istanbul.Validator]*prque.Prque),
 		backlogsMu: new(sync.Mutex),
+		validateFn: new(testSystemBackend).CheckValidatorSignature,
 	}
 	v := &istanbul.View{
 		Round:    big.NewInt(10),
@@ -221,9 +222,10 @@ func TestStoreBacklog(t *testing.T) {
 	}
 
 	// push roundChange msg
+	roundChangePayload := newRoundChange(v, istanbul.PreparedCertificate{}, nil, common.Address{})
 	m = &message{
 		Code: msgRoundChange,
-		Msg:  subjectPayload,
+		Msg:  roundChangePayload,
 	}
 	c.storeBacklog(m, p)
 	msg = c.backlogs[p].PopItem()
@@ -241,6 +243,7 @@ func TestProcessFutureBacklog(t *testing.T) {
 		backlogs:   make(map[istanbul.Validator]*prque.Prque),
 		backlogsMu: new(sync.Mutex),
 		backend:    backend,
+		validateFn: backend.CheckValidatorSignature,
 		current: newRoundState(&istanbul.View{
 			Sequence: big.NewInt(1),
 			Round:    big.NewInt(0),
@@ -298,6 +301,8 @@ func TestProcessBacklog(t *testing.T) {
 	}
 	subjectPayload, _ := Encode(subject)
 
+	roundChangePayload := newRoundChange(v, istanbul.PreparedCertificate{}, nil, common.Address{})
+
 	msgs := []*message{
 		{
 			Code: msgPreprepare,
@@ -313,7 +318,7 @@ func TestProcessBacklog(t *testing.T) {
 		},
 		{
 			Code: msgRoundChange,
-			Msg:  subjectPayload,
+			Msg:  roundChangePayload,
 		},
 	}
 	for i := 0; i < len(msgs); i++ {
@@ -332,6 +337,7 @@ func testProcessBacklog(t *testing.T, msg *message) {
 		backlogs:   make(map[istanbul.Validator]*prque.Prque),
 		backlogsMu: new(sync.Mutex),
 		backend:    backend,
+		validateFn: backend.CheckValidatorSignature,
 		state:      State(msg.Code),
 		current: newRoundState(&istanbul.View{
 			Sequence: big.NewInt(1),
@@ -0,0 +1,182 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+// errInvalidSigner is returned when a message embedded in a certificate is not
+// signed by the validator it claims to come from.
+var errInvalidSigner = errors.New("message not signed by its sender")
+
+// createPreparedCertificate creates a certificate of the current proposal from
+// the PREPARE and COMMIT messages received in the current round.
+func (c *core) createPreparedCertificate() (istanbul.PreparedCertificate, error) {
+	messages := make([][]byte, 0, c.current.Prepares.Size()+c.current.Commits.Size())
+	seen := make(map[common.Address]bool)
+	for _, msg := range append(c.current.Prepares.Values(), c.current.Commits.Values()...) {
+		if seen[msg.Address] {
+			continue
+		}
+		seen[msg.Address] = true
+
+		payload, err := msg.Payload()
+		if err != nil {
+			return istanbul.PreparedCertificate{}, err
+		}
+		messages = append(messages, payload)
+	}
+	return istanbul.PreparedCertificate{
+		ProposalHash:            c.current.Proposal().Hash(),
+		PrepareOrCommitMessages: messages,
+	}, nil
+}
+
+// decodeCertificateMessage decodes a signed message embedded in a certificate,
+// checking that it is signed by the validator it claims to come from.
+func (c *core) decodeCertificateMessage(payload []byte) (*message, error) {
+	var signer common.Address
+	msg := new(message)
+	err := msg.FromPayload(payload, func(data []byte, sig []byte) (common.Address, error) {
+		var err error
+		signer, err = c.validateFn(data, sig)
+		return signer, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if signer != msg.Address {
+		return nil, errInvalidSigner
+	}
+	return msg, nil
+}
+
+// decodeRoundChangeRequest decodes a signed ROUND CHANGE REQUEST message,
+// returning the message and the request it holds.
+func (c *core) decodeRoundChangeRequest(payload []byte) (*message, *istanbul.RoundChangeRequest, error) {
+	msg, err := c.decodeCertificateMessage(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.Code != msgRoundChangeRequest {
+		return nil, nil, errInvalidMessage
+	}
+	var request *istanbul.RoundChangeRequest
+	if err := msg.Decode(&request); err != nil || request.View == nil || request.View.Round == nil || request.View.Sequence == nil {
+		return nil, nil, errInvalidMessage
+	}
+	return msg, request, nil
+}
+
+// verifyPreparedCertificate checks that the certificate holds PREPARE or COMMIT
+// messages for its proposal from more than 2F distinct validators, all of the
+// same view, and returns that view.
+func (c *core) verifyPreparedCertificate(preparedCertificate istanbul.PreparedCertificate) (*istanbul.View, error) {
+	if len(preparedCertificate.PrepareOrCommitMessages) <= 2*c.valSet.F() {
+		return nil, errInvalidPreparedCertificate
+	}
+	var view *istanbul.View
+	seen := make(map[common.Address]bool)
+	for _, payload := range preparedCertificate.PrepareOrCommitMessages {
+		msg, err := c.decodeCertificateMessage(payload)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Code != msgPrepare && msg.Code != msgCommit {
+			return nil, errInvalidPreparedCertificate
+		}
+		if _, v := c.valSet.GetByAddress(msg.Address); v == nil || seen[msg.Address] {
+			return nil, errInvalidPreparedCertificate
+		}
+		seen[msg.Address] = true
+
+		var subject *istanbul.Subject
+		if err := msg.Decode(&subject); err != nil || subject.View == nil || subject.View.Round == nil || subject.View.Sequence == nil {
+			return nil, errInvalidPreparedCertificate
+		}
+		if subject.Digest != preparedCertificate.ProposalHash {
+			return nil, errInvalidPreparedCertificate
+		}
+		if view == nil {
+			view = subject.View
+		} else if view.Cmp(subject.View) != 0 {
+			return nil, errInvalidPreparedCertificate
+		}
+	}
+	return view, nil
+}
+
+// verifyRoundChangeRequest checks the prepared certificate a ROUND CHANGE
+// REQUEST carries, if any, which must be of an earlier round of the same
+// sequence, and returns the view the proposal was prepared in.
+func (c *core) verifyRoundChangeRequest(request *istanbul.RoundChangeRequest) (*istanbul.View, error) {
+	if request.PreparedCertificate.IsEmpty() {
+		return nil, nil
+	}
+	view, err := c.verifyPreparedCertificate(request.PreparedCertificate)
+	if err != nil {
+		return nil, err
+	}
+	if view.Sequence.Cmp(request.View.Sequence) != 0 || view.Round.Cmp(request.View.Round) >= 0 {
+		return nil, errInvalidPreparedCertificate
+	}
+	return view, nil
+}
+
+// verifyRoundChangeCertificate checks that the certificate holds valid ROUND
+// CHANGE REQUEST messages to the given view from more than 2F distinct
+// validators, and returns the hash of the proposal of the highest prepared
+// certificate among them, if any. That proposal is the only one the proposer may
+// propose in the round.
+func (c *core) verifyRoundChangeCertificate(view *istanbul.View, roundChangeCertificate istanbul.RoundChangeCertificate) (common.Hash, error) {
+	if len(roundChangeCertificate.RoundChangeRequests) <= 2*c.valSet.F() {
+		return common.Hash{}, errInvalidRoundChangeCertificate
+	}
+	var (
+		preparedHash common.Hash
+		preparedView *istanbul.View
+	)
+	seen := make(map[common.Address]bool)
+	for _, payload := range roundChangeCertificate.RoundChangeRequests {
+		msg, request, err := c.decodeRoundChangeRequest(payload)
+		if err == errInvalidMessage {
+			return common.Hash{}, errInvalidRoundChangeCertificate
+		} else if err != nil {
+			return common.Hash{}, err
+		}
+		if _, v := c.valSet.GetByAddress(msg.Address); v == nil || seen[msg.Address] {
+			return common.Hash{}, errInvalidRoundChangeCertificate
+		}
+		seen[msg.Address] = true
+
+		if request.View.Cmp(view) != 0 {
+			return common.Hash{}, errInvalidRoundChangeCertificate
+		}
+		prepared, err := c.verifyRoundChangeRequest(request)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if prepared != nil && (preparedView == nil || preparedView.Cmp(prepared) < 0) {
+			preparedHash, preparedView = request.PreparedCertificate.ProposalHash, prepared
+		}
+	}
+	return preparedHash, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newCertificateMessage returns the payload of a message from the given validator,
+// as it would be embedded in a certificate.
+func newCertificateMessage(code uint64, val interface{}, from common.Address) []byte {
+	msg, _ := Encode(val)
+	payload, _ := (&message{Code: code, Msg: msg, Address: from, Signature: []byte{}}).Payload()
+	return payload
+}
+
+// newRoundChange returns the encoded ROUND CHANGE message of the given validator,
+// carrying its request and the proposal the request's certificate refers to.
+func newRoundChange(view *istanbul.View, preparedCertificate istanbul.PreparedCertificate, preparedProposal istanbul.Proposal, from common.Address) []byte {
+	request := newCertificateMessage(msgRoundChangeRequest, &istanbul.RoundChangeRequest{View: view, PreparedCertificate: preparedCertificate}, from)
+	payload, _ := Encode(&istanbul.RoundChange{Request: request, PreparedProposal: preparedProposal})
+	return payload
+}
+
+func newPreparedCertificate(c *core, proposal istanbul.Proposal, view *istanbul.View, signers int) istanbul.PreparedCertificate {
+	subject := &istanbul.Subject{View: view, Digest: proposal.Hash()}
+	messages := make([][]byte, signers)
+	for i := range messages {
+		messages[i] = newCertificateMessage(msgPrepare, subject, c.valSet.GetByIndex(uint64(i)).Address())
+	}
+	return istanbul.PreparedCertificate{ProposalHash: proposal.Hash(), PrepareOrCommitMessages: messages}
+}
+
+func TestVerifyPreparedCertificate(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	c := sys.backends[0].engine.(*core)
+
+	proposal := makeBlock(1)
+	view := &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}
+
+	if have, err := c.verifyPreparedCertificate(newPreparedCertificate(c, proposal, view, 3)); err != nil || have.Cmp(view) != 0 {
+		t.Errorf("prepared view mismatch: have %v, %v, want %v", have, err, view)
+	}
+	// too few messages
+	if _, err := c.verifyPreparedCertificate(newPreparedCertificate(c, proposal, view, 2)); err != errInvalidPreparedCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidPreparedCertificate)
+	}
+	// messages for another proposal
+	other := types.NewBlock(&types.Header{Number: big.NewInt(1), GasLimit: 1}, nil, nil, nil, nil)
+	certificate := newPreparedCertificate(c, proposal, view, 3)
+	certificate.ProposalHash = other.Hash()
+	if _, err := c.verifyPreparedCertificate(certificate); err != errInvalidPreparedCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidPreparedCertificate)
+	}
+	// the same validator counted twice
+	certificate = newPreparedCertificate(c, proposal, view, 3)
+	certificate.PrepareOrCommitMessages[2] = certificate.PrepareOrCommitMessages[0]
+	if _, err := c.verifyPreparedCertificate(certificate); err != errInvalidPreparedCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidPreparedCertificate)
+	}
+	// messages signed by someone else than their sender
+	c.validateFn = func([]byte, []byte) (common.Address, error) { return common.Address{}, nil }
+	if _, err := c.verifyPreparedCertificate(newPreparedCertificate(c, proposal, view, 3)); err != errInvalidSigner {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidSigner)
+	}
+}
+
+func TestVerifyRoundChangeCertificate(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	c := sys.backends[0].engine.(*core)
+
+	prepared := makeBlock(1)
+	other := types.NewBlock(&types.Header{Number: big.NewInt(1), GasLimit: 1}, nil, nil, nil, nil)
+	view := &istanbul.View{Round: big.NewInt(2), Sequence: big.NewInt(1)}
+
+	newCertificate := func(preparedCertificates ...istanbul.PreparedCertificate) istanbul.RoundChangeCertificate {
+		requests := make([][]byte, 3)
+		for i := range requests {
+			request := &istanbul.RoundChangeRequest{View: view}
+			if i < len(preparedCertificates) {
+				request.PreparedCertificate = preparedCertificates[i]
+			}
+			requests[i] = newCertificateMessage(msgRoundChangeRequest, request, c.valSet.GetByIndex(uint64(i)).Address())
+		}
+		return istanbul.RoundChangeCertificate{RoundChangeRequests: requests}
+	}
+
+	// nothing prepared, any proposal is justified
+	if have, err := c.verifyRoundChangeCertificate(view, newCertificate()); err != nil || (have != common.Hash{}) {
+		t.Errorf("prepared proposal mismatch: have %v, %v, want none", have, err)
+	}
+	// the proposal prepared in the highest round has to be re-proposed
+	certificate := newCertificate(
+		newPreparedCertificate(c, other, &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}, 3),
+		newPreparedCertificate(c, prepared, &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(1)}, 3),
+	)
+	if have, err := c.verifyRoundChangeCertificate(view, certificate); err != nil || have != prepared.Hash() {
+		t.Errorf("prepared proposal mismatch: have %v, %v, want %v", have, err, prepared.Hash())
+	}
+	// prepared certificates must be of an earlier round
+	certificate = newCertificate(newPreparedCertificate(c, prepared, view, 3))
+	if _, err := c.verifyRoundChangeCertificate(view, certificate); err != errInvalidPreparedCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidPreparedCertificate)
+	}
+	// too few messages
+	certificate = newCertificate()
+	certificate.RoundChangeRequests = certificate.RoundChangeRequests[:2]
+	if _, err := c.verifyRoundChangeCertificate(view, certificate); err != errInvalidRoundChangeCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidRoundChangeCertificate)
+	}
+	// messages to another round
+	if _, err := c.verifyRoundChangeCertificate(&istanbul.View{Round: big.NewInt(3), Sequence: big.NewInt(1)}, newCertificate()); err != errInvalidRoundChangeCertificate {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidRoundChangeCertificate)
+	}
+
+	// PRE-PREPARE messages must propose the highest prepared proposal
+	c.current = newRoundState(view, c.valSet, common.Hash{}, nil, nil, func(common.Hash) bool { return false })
+	c.valSet.CalcProposer(common.Address{}, view.Round.Uint64())
+	proposer := c.valSet.GetProposer()
+	for _, test := range []struct {
+		preprepare *istanbul.Preprepare
+		err        error
+	}{
+		{&istanbul.Preprepare{View: view, Proposal: other}, errInvalidRoundChangeCertificate},
+		{&istanbul.Preprepare{View: view, Proposal: other, RoundChangeCertificate: newCertificate(newPreparedCertificate(c, prepared, &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(1)}, 3))}, errInvalidPreparedProposal},
+	} {
+		m, _ := Encode(test.preprepare)
+		if err := c.handlePreprepare(&message{Code: msgPreprepare, Msg: m, Address: proposer.Address()}, proposer); err != test.err {
+			t.Errorf("error mismatch: have %v, want %v", err, test.err)
+		}
+	}
+}
+
+func TestHandleRoundChangePreparedProposal(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	c := sys.backends[0].engine.(*core)
+
+	prepared := makeBlock(1)
+	other := types.NewBlock(&types.Header{Number: big.NewInt(1), GasLimit: 1}, nil, nil, nil, nil)
+	view := &istanbul.View{Round: big.NewInt(2), Sequence: big.NewInt(1)}
+	c.current = newRoundState(view, c.valSet, common.Hash{}, nil, nil, func(common.Hash) bool { return false })
+	c.roundChangeSet = newRoundChangeSet(c.valSet)
+
+	certificate := newPreparedCertificate(c, prepared, &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(1)}, 3)
+	for i, test := range []struct {
+		proposal istanbul.Proposal
+		signer   common.Address
+		err      error
+	}{
+		{nil, c.valSet.GetByIndex(1).Address(), errInvalidPreparedCertificate},
+		{other, c.valSet.GetByIndex(1).Address(), errInvalidPreparedCertificate},
+		{prepared, c.valSet.GetByIndex(2).Address(), errInvalidSigner},
+		{prepared, c.valSet.GetByIndex(1).Address(), nil},
+	} {
+		src := c.valSet.GetByIndex(1)
+		msg := &message{
+			Code:    msgRoundChange,
+			Msg:     newRoundChange(view, certificate, test.proposal, test.signer),
+			Address: src.Address(),
+		}
+		if err := c.handleRoundChange(msg, src); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
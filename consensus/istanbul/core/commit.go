@@ -62,7 +62,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 		return err
 	}
 
-	if err := c.verifyCommit(commit, src); err != nil {
+	if err := c.verifyCommit(commit, msg.CommittedSeal, src); err != nil {
 		return err
 	}
 
@@ -75,14 +75,16 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 	if c.current.Commits.Size() > 2*c.valSet.F() && c.state.Cmp(StateCommitted) < 0 {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
 		c.current.LockHash()
+		c.updatePreparedCertificate()
 		c.commit()
 	}
 
 	return nil
 }
 
-// verifyCommit verifies if the received COMMIT message is equivalent to our subject
-func (c *core) verifyCommit(commit *istanbul.Subject, src istanbul.Validator) error {
+// verifyCommit verifies if the received COMMIT message is equivalent to our subject,
+// and carries a committed seal of the proposal signed by its sender
+func (c *core) verifyCommit(commit *istanbul.Subject, committedSeal []byte, src istanbul.Validator) error {
 	logger := c.logger.New("from", src, "state", c.state)
 
 	sub := c.current.Subject()
@@ -91,6 +93,11 @@ func (c *core) verifyCommit(commit *istanbul.Subject, src istanbul.Validator) er
 		return errInconsistentSubject
 	}
 
+	if err := c.backend.CheckCommittedSeal(PrepareCommittedSeal(commit.Digest), src, committedSeal, commit.View.Sequence); err != nil {
+		logger.Warn("Invalid committed seal", "err", err)
+		return errInvalidCommittedSeal
+	}
+
 	return nil
 }
 
@@ -167,13 +167,15 @@ OUTER:
 
 		for i, v := range test.system.backends {
 			validator := r0.valSet.GetByIndex(uint64(i))
-			m, _ := Encode(v.engine.(*core).current.Subject())
+			sub := v.engine.(*core).current.Subject()
+			m, _ := Encode(sub)
+			committedSeal, _ := v.SignCommittedSeal(PrepareCommittedSeal(sub.Digest), sub.View.Sequence, r0.valSet)
 			if err := r0.handleCommit(&message{
 				Code:          msgCommit,
 				Msg:           m,
 				Address:       validator.Address(),
 				Signature:     []byte{},
-				CommittedSeal: validator.Address().Bytes(), // small hack
+				CommittedSeal: committedSeal,
 			}, validator); err != nil {
 				if err != test.expectedErr {
 					t.Errorf("error mismatch: have %v, want %v", err, test.expectedErr)
@@ -238,6 +240,7 @@ func TestVerifyCommit(t *testing.T) {
 		expected   error
 		commit     *istanbul.Subject
 		roundState *roundState
+		forged     bool
 	}{
 		{
 			// normal case
@@ -251,6 +254,19 @@ func TestVerifyCommit(t *testing.T) {
 				valSet,
 			),
 		},
+		{
+			// committed seal not signed by the sender
+			expected: errInvalidCommittedSeal,
+			commit: &istanbul.Subject{
+				View:   &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(0)},
+				Digest: newTestProposal().Hash(),
+			},
+			roundState: newTestRoundState(
+				&istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(0)},
+				valSet,
+			),
+			forged: true,
+		},
 		{
 			// old message
 			expected: errInconsistentSubject,
@@ -316,10 +332,12 @@ func TestVerifyCommit(t *testing.T) {
 		c := sys.backends[0].engine.(*core)
 		c.current = test.roundState
 
-		if err := c.verifyCommit(test.commit, peer); err != nil {
-			if err != test.expected {
-				t.Errorf("result %d: error mismatch: have %v, want %v", i, err, test.expected)
-			}
+		committedSeal := append(peer.Address().Bytes(), PrepareCommittedSeal(test.commit.Digest)...)
+		if test.forged {
+			committedSeal = append(sys.backends[0].Address().Bytes(), PrepareCommittedSeal(test.commit.Digest)...)
+		}
+		if err := c.verifyCommit(test.commit, committedSeal, peer); err != test.expected {
+			t.Errorf("result %d: error mismatch: have %v, want %v", i, err, test.expected)
 		}
 	}
 }
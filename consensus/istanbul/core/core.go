@@ -26,7 +26,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -45,6 +44,9 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		backlogsMu:         new(sync.Mutex),
 		pendingRequests:    prque.New(nil),
 		pendingRequestsMu:  new(sync.Mutex),
+		signedMessages:     make(map[signedMessageKey]*signedMessage),
+		statusSubs:         make(map[chan<- StatusEvent]struct{}),
+		wal:                newWAL(config.WAL),
 		consensusTimestamp: time.Time{},
 		roundMeter:         metrics.NewRegisteredMeter("consensus/istanbul/core/round", nil),
 		sequenceMeter:      metrics.NewRegisteredMeter("consensus/istanbul/core/sequence", nil),
@@ -62,11 +64,15 @@ type core struct {
 	state   State
 	logger  log.Logger
 
+	// stateMu is held by the event loop while it handles an event, so the state
+	// can be read from other goroutines, see Status
+	stateMu sync.RWMutex
+
 	backend               istanbul.Backend
 	events                *event.TypeMuxSubscription
 	finalCommittedSub     *event.TypeMuxSubscription
 	timeoutSub            *event.TypeMuxSubscription
-	futurePreprepareTimer *time.Timer
+	futurePreprepareTimer istanbul.Timer
 
 	valSet                istanbul.ValidatorSet
 	waitingForRoundChange bool
@@ -79,11 +85,17 @@ type core struct {
 	handlerWg *sync.WaitGroup
 
 	roundChangeSet   *roundChangeSet
-	roundChangeTimer *time.Timer
+	roundChangeTimer istanbul.Timer
 
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	signedMessages map[signedMessageKey]*signedMessage // the first message each validator signed per view, to detect equivocations
+	wal            *wal                                // the log of the messages signed by this validator
+
+	statusSubs   map[chan<- StatusEvent]struct{} // the subscribers to the changes of the view and state
+	statusSubsMu sync.Mutex
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -107,7 +119,7 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	// Assign the CommittedSeal if it's a COMMIT message and proposal is not nil
 	if msg.Code == msgCommit && c.current.Proposal() != nil {
 		seal := PrepareCommittedSeal(c.current.Proposal().Hash())
-		msg.CommittedSeal, err = c.backend.Sign(seal)
+		msg.CommittedSeal, err = c.backend.SignCommittedSeal(seal, c.current.Proposal().Number(), c.valSet)
 		if err != nil {
 			return nil, err
 		}
@@ -135,11 +147,21 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 func (c *core) broadcast(msg *message) {
 	logger := c.logger.New("state", c.state)
 
+	// Never sign a message conflicting with one signed before, also not after a restart
+	if err := c.checkSigned(msg); err != nil {
+		logger.Error("Refusing to sign message", "msg", msg, "err", err)
+		return
+	}
 	payload, err := c.finalizeMessage(msg)
 	if err != nil {
 		logger.Error("Failed to finalize message", "msg", msg, "err", err)
 		return
 	}
+	// Log the message and the lock before sending it
+	if err := c.wal.append(c.current.Sequence(), c.current.GetLockedHash(), c.current.Preprepare, payload); err != nil {
+		logger.Error("Failed to write message to write-ahead log", "msg", msg, "err", err)
+		return
+	}
 
 	// Broadcast payload
 	if err = c.backend.Broadcast(c.valSet, payload); err != nil {
@@ -168,13 +190,14 @@ func (c *core) commit() {
 
 	proposal := c.current.Proposal()
 	if proposal != nil {
+		committers := make([]common.Address, c.current.Commits.Size())
 		committedSeals := make([][]byte, c.current.Commits.Size())
 		for i, v := range c.current.Commits.Values() {
-			committedSeals[i] = make([]byte, types.IstanbulExtraSeal)
-			copy(committedSeals[i][:], v.CommittedSeal[:])
+			committers[i] = v.Address
+			committedSeals[i] = common.CopyBytes(v.CommittedSeal)
 		}
 
-		if err := c.backend.Commit(proposal, committedSeals); err != nil {
+		if err := c.backend.Commit(proposal, committers, committedSeals); err != nil {
 			c.current.UnlockHash() //Unlock block when insertion fails
 			c.sendNextRoundChange()
 			return
@@ -231,6 +254,20 @@ func (c *core) startNewRound(round *big.Int) {
 			Round:    new(big.Int),
 		}
 		c.valSet = c.backend.Validators(lastProposal)
+		c.pruneSignedMessages(newView.Sequence)
+	}
+
+	// Keep the ROUND CHANGE messages to the new round as the proof the proposer
+	// justifies its proposal with
+	var (
+		roundChangeCertificate istanbul.RoundChangeCertificate
+		preparedProposals      map[common.Hash]istanbul.Proposal
+	)
+	if roundChange {
+		var err error
+		if roundChangeCertificate, preparedProposals, err = c.roundChangeSet.getCertificate(round, 2*c.valSet.F()+1); err != nil {
+			logger.Warn("Failed to create round change certificate", "round", round, "err", err)
+		}
 	}
 
 	// Update logger
@@ -239,18 +276,24 @@ func (c *core) startNewRound(round *big.Int) {
 	c.roundChangeSet = newRoundChangeSet(c.valSet)
 	// New snapshot for new round
 	c.updateRoundState(newView, c.valSet, roundChange)
+	c.current.SetRoundChangeCertificate(roundChangeCertificate)
 	// Calculate new proposer
 	c.valSet.CalcProposer(lastProposer, newView.Round.Uint64())
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
 	if roundChange && c.isProposer() && c.current != nil {
-		// If it is locked, propose the old proposal
+		// If the round change certificate holds a prepared proposal, propose the
+		// one of the highest round, which may not be the one we are locked on
 		// If we have pending request, propose pending request
-		if c.current.IsHashLocked() {
-			r := &istanbul.Request{
-				Proposal: c.current.Proposal(), //c.current.Proposal would be the locked proposal by previous proposer, see updateRoundState
+		preparedHash, err := c.verifyRoundChangeCertificate(newView, roundChangeCertificate)
+		if err != nil {
+			logger.Warn("Cannot propose without a round change certificate", "err", err)
+		} else if (preparedHash != common.Hash{}) {
+			if preparedProposal := preparedProposals[preparedHash]; preparedProposal != nil {
+				c.sendPreprepare(&istanbul.Request{Proposal: preparedProposal})
+			} else {
+				logger.Warn("Cannot propose without the highest prepared proposal", "prepared", preparedHash)
 			}
-			c.sendPreprepare(r)
 		} else if c.current.pendingRequest != nil {
 			c.sendPreprepare(c.current.pendingRequest)
 		}
@@ -280,19 +323,24 @@ func (c *core) catchUpRound(view *istanbul.View) {
 func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.ValidatorSet, roundChange bool) {
 	// Lock only if both roundChange is true and it is locked
 	if roundChange && c.current != nil {
+		preparedCertificate, preparedProposal := c.current.PreparedCertificate()
 		if c.current.IsHashLocked() {
 			c.current = newRoundState(view, validatorSet, c.current.GetLockedHash(), c.current.Preprepare, c.current.pendingRequest, c.backend.HasBadProposal)
 		} else {
 			c.current = newRoundState(view, validatorSet, common.Hash{}, nil, c.current.pendingRequest, c.backend.HasBadProposal)
 		}
+		// Keep the proof of the last prepared proposal for the ROUND CHANGE messages
+		c.current.SetPreparedCertificate(preparedCertificate, preparedProposal)
 	} else {
 		c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil, c.backend.HasBadProposal)
 	}
+	c.postStatusEvent(true)
 }
 
 func (c *core) setState(state State) {
 	if c.state != state {
 		c.state = state
+		c.postStatusEvent(false)
 	}
 	if state == StateAcceptRequest {
 		c.processPendingRequests()
@@ -327,7 +375,7 @@ func (c *core) newRoundChangeTimer() {
 		timeout += time.Duration(math.Pow(2, float64(round))) * time.Second
 	}
 
-	c.roundChangeTimer = time.AfterFunc(timeout, func() {
+	c.roundChangeTimer = c.backend.Clock().AfterFunc(timeout, func() {
 		c.sendEvent(timeoutEvent{})
 	})
 }
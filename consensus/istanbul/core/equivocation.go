@@ -0,0 +1,163 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// equivocationMeter records the rate of conflicting messages signed by validators
+var equivocationMeter = metrics.NewRegisteredMeter("consensus/istanbul/core/equivocation", nil)
+
+// signedMessageKey identifies the messages a validator may sign only once.
+type signedMessageKey struct {
+	code     uint64
+	sequence uint64
+	round    uint64
+	signer   common.Address
+}
+
+type signedMessage struct {
+	digest  common.Hash
+	payload []byte
+}
+
+// messageDigest returns the view of a PRE-PREPARE, PREPARE or COMMIT message,
+// and the digest of the proposal it is signed for.
+func messageDigest(msg *message) (*istanbul.View, common.Hash, error) {
+	switch msg.Code {
+	case msgPreprepare:
+		var preprepare *istanbul.Preprepare
+		if err := msg.Decode(&preprepare); err != nil || preprepare.Proposal == nil {
+			return nil, common.Hash{}, errFailedDecodePreprepare
+		}
+		return preprepare.View, preprepare.Proposal.Hash(), nil
+	case msgPrepare, msgCommit:
+		var subject *istanbul.Subject
+		if err := msg.Decode(&subject); err != nil {
+			return nil, common.Hash{}, errInvalidMessage
+		}
+		return subject.View, subject.Digest, nil
+	}
+	return nil, common.Hash{}, errInvalidMessage
+}
+
+// inEquivocationWindow returns whether messages of the given code and view are
+// remembered to detect equivocations: messages checkMessage accepts, and future
+// messages of the next round or the first round of the next sequence. Messages
+// further ahead are checked once they are replayed from the backlog, so a
+// validator cannot make us remember messages for any number of views.
+func (c *core) inEquivocationWindow(code uint64, view *istanbul.View) bool {
+	switch c.checkMessage(code, view) {
+	case nil:
+		return true
+	case errFutureMessage:
+		cv := c.currentView()
+		next := new(big.Int).Add(cv.Sequence, common.Big1)
+		if view.Sequence.Cmp(cv.Sequence) == 0 {
+			return view.Round.Cmp(new(big.Int).Add(cv.Round, common.Big1)) <= 0
+		}
+		return view.Sequence.Cmp(next) == 0 && view.Round.Sign() == 0
+	}
+	return false
+}
+
+// checkEquivocation remembers the first message of each code a validator signs
+// for a view, and reports the evidence to the backend when the validator signs
+// a conflicting one. Messages are checked before they are handled, and again
+// when they are replayed from the backlog.
+func (c *core) checkEquivocation(msg *message) {
+	view, digest, err := messageDigest(msg)
+	if err != nil || !c.inEquivocationWindow(msg.Code, view) {
+		return
+	}
+	payload, err := msg.Payload()
+	if err != nil {
+		return
+	}
+
+	key := signedMessageKey{
+		code:     msg.Code,
+		sequence: view.Sequence.Uint64(),
+		round:    view.Round.Uint64(),
+		signer:   msg.Address,
+	}
+	first, ok := c.signedMessages[key]
+	if !ok {
+		c.signedMessages[key] = &signedMessage{digest: digest, payload: payload}
+		return
+	}
+	if first.digest == digest {
+		return
+	}
+
+	c.logger.Warn("Validator signed conflicting messages", "signer", msg.Address, "code", msg.Code, "view", view, "first", first.digest, "second", digest)
+	equivocationMeter.Mark(1)
+	c.backend.ReportEquivocation(&istanbul.Equivocation{
+		Signer:   msg.Address,
+		Code:     msg.Code,
+		View:     view,
+		Messages: [][]byte{first.payload, payload},
+	})
+}
+
+// pruneSignedMessages forgets the messages signed for sequences before the given
+// one, which are no longer in the equivocation window.
+func (c *core) pruneSignedMessages(sequence *big.Int) {
+	for key := range c.signedMessages {
+		if key.sequence < sequence.Uint64() {
+			delete(c.signedMessages, key)
+		}
+	}
+}
+
+// VerifyEquivocation checks that the evidence holds two messages of its code and
+// view, signed by its signer for different digests. It doesn't check that the
+// signer was a validator of the view.
+func VerifyEquivocation(evidence *istanbul.Equivocation) error {
+	if len(evidence.Messages) != 2 || evidence.View == nil || evidence.View.Round == nil || evidence.View.Sequence == nil {
+		return errInvalidEquivocation
+	}
+
+	var digests [2]common.Hash
+	for i, payload := range evidence.Messages {
+		var signer common.Address
+		msg := new(message)
+		err := msg.FromPayload(payload, func(data []byte, sig []byte) (common.Address, error) {
+			var err error
+			signer, err = istanbul.GetSignatureAddress(data, sig)
+			return signer, err
+		})
+		if err != nil || signer != evidence.Signer || msg.Address != evidence.Signer || msg.Code != evidence.Code {
+			return errInvalidEquivocation
+		}
+
+		view, digest, err := messageDigest(msg)
+		if err != nil || view == nil || view.Round == nil || view.Sequence == nil || view.Cmp(evidence.View) != 0 {
+			return errInvalidEquivocation
+		}
+		digests[i] = digest
+	}
+	if digests[0] == digests[1] {
+		return errInvalidEquivocation
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newSignedMessage returns the payload of a message signed with the given key.
+func newSignedMessage(code uint64, val interface{}, key *ecdsa.PrivateKey) []byte {
+	msg, _ := Encode(val)
+	m := &message{Code: code, Msg: msg, Address: crypto.PubkeyToAddress(key.PublicKey), Signature: []byte{}}
+	data, _ := m.PayloadNoSig()
+	m.Signature, _ = crypto.Sign(crypto.Keccak256(data), key)
+	payload, _ := m.Payload()
+	return payload
+}
+
+func TestCheckEquivocation(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+
+	closer := sys.Run(true)
+	defer closer()
+
+	v0 := sys.backends[0]
+	r0 := v0.engine.(*core)
+	sender := sys.backends[1].Address()
+
+	view := &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(1)}
+	first := newCertificateMessage(msgPrepare, &istanbul.Subject{View: view, Digest: common.HexToHash("0x1")}, sender)
+	second := newCertificateMessage(msgPrepare, &istanbul.Subject{View: view, Digest: common.HexToHash("0x2")}, sender)
+	commit := newCertificateMessage(msgCommit, &istanbul.Subject{View: view, Digest: common.HexToHash("0x2")}, sender)
+
+	// Repeated messages and messages of other codes are no equivocation
+	for _, payload := range [][]byte{first, first, commit} {
+		r0.handleMsg(payload)
+	}
+	if len(v0.equivocations) != 0 {
+		t.Fatalf("equivocations mismatch: have %d, want 0", len(v0.equivocations))
+	}
+
+	r0.handleMsg(second)
+	if len(v0.equivocations) != 1 {
+		t.Fatalf("equivocations mismatch: have %d, want 1", len(v0.equivocations))
+	}
+	evidence := v0.equivocations[0]
+	if evidence.Signer != sender || evidence.Code != msgPrepare || evidence.View.Cmp(view) != 0 {
+		t.Errorf("evidence mismatch: have signer %v code %d view %v", evidence.Signer, evidence.Code, evidence.View)
+	}
+	if len(evidence.Messages) != 2 || string(evidence.Messages[0]) != string(first) || string(evidence.Messages[1]) != string(second) {
+		t.Errorf("evidence messages mismatch")
+	}
+
+	// Only messages of the current view, the next round or the next sequence are
+	// remembered
+	signed := len(r0.signedMessages)
+	for _, v := range []*istanbul.View{
+		{Round: big.NewInt(2), Sequence: big.NewInt(1)},
+		{Round: big.NewInt(1), Sequence: big.NewInt(2)},
+		{Round: big.NewInt(0), Sequence: big.NewInt(3)},
+		{Round: big.NewInt(0), Sequence: big.NewInt(0)},
+	} {
+		r0.handleMsg(newCertificateMessage(msgPrepare, &istanbul.Subject{View: v, Digest: common.HexToHash("0x1")}, sender))
+	}
+	if len(r0.signedMessages) != signed {
+		t.Errorf("signed messages outside of the window mismatch: have %d, want %d", len(r0.signedMessages), signed)
+	}
+	for _, v := range []*istanbul.View{
+		{Round: big.NewInt(1), Sequence: big.NewInt(1)},
+		{Round: big.NewInt(0), Sequence: big.NewInt(2)},
+	} {
+		r0.handleMsg(newCertificateMessage(msgPrepare, &istanbul.Subject{View: v, Digest: common.HexToHash("0x1")}, sender))
+	}
+	if len(r0.signedMessages) != signed+2 {
+		t.Errorf("signed messages within the window mismatch: have %d, want %d", len(r0.signedMessages), signed+2)
+	}
+
+	// Messages of old sequences are forgotten
+	r0.pruneSignedMessages(big.NewInt(3))
+	if len(r0.signedMessages) != 0 {
+		t.Errorf("signed messages mismatch: have %d, want 0", len(r0.signedMessages))
+	}
+}
+
+func TestVerifyEquivocation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	view := &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(5)}
+	subject := func(digest string) *istanbul.Subject {
+		return &istanbul.Subject{View: view, Digest: common.HexToHash(digest)}
+	}
+
+	testCases := []struct {
+		evidence    *istanbul.Equivocation
+		expectedErr error
+	}{
+		{
+			// conflicting commits
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newSignedMessage(msgCommit, subject("0x1"), key),
+				newSignedMessage(msgCommit, subject("0x2"), key),
+			}},
+			nil,
+		},
+		{
+			// conflicting proposals
+			&istanbul.Equivocation{Signer: signer, Code: msgPreprepare, View: view, Messages: [][]byte{
+				newSignedMessage(msgPreprepare, &istanbul.Preprepare{View: view, Proposal: makeBlock(5)}, key),
+				newSignedMessage(msgPreprepare, &istanbul.Preprepare{View: view, Proposal: types.NewBlock(&types.Header{Number: big.NewInt(5), Difficulty: big.NewInt(0), Time: big.NewInt(1)}, nil, nil, nil, nil)}, key),
+			}},
+			nil,
+		},
+		{
+			// same digest
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newSignedMessage(msgCommit, subject("0x1"), key),
+				newSignedMessage(msgCommit, subject("0x1"), key),
+			}},
+			errInvalidEquivocation,
+		},
+		{
+			// message signed by another validator
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newSignedMessage(msgCommit, subject("0x1"), key),
+				newSignedMessage(msgCommit, subject("0x2"), other),
+			}},
+			errInvalidEquivocation,
+		},
+		{
+			// messages of different codes
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newSignedMessage(msgCommit, subject("0x1"), key),
+				newSignedMessage(msgPrepare, subject("0x2"), key),
+			}},
+			errInvalidEquivocation,
+		},
+		{
+			// message of another view
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newSignedMessage(msgCommit, subject("0x1"), key),
+				newSignedMessage(msgCommit, &istanbul.Subject{View: &istanbul.View{Round: big.NewInt(2), Sequence: big.NewInt(5)}, Digest: common.HexToHash("0x2")}, key),
+			}},
+			errInvalidEquivocation,
+		},
+		{
+			// unsigned messages
+			&istanbul.Equivocation{Signer: signer, Code: msgCommit, View: view, Messages: [][]byte{
+				newCertificateMessage(msgCommit, subject("0x1"), signer),
+				newCertificateMessage(msgCommit, subject("0x2"), signer),
+			}},
+			errInvalidEquivocation,
+		},
+	}
+	for i, test := range testCases {
+		if err := VerifyEquivocation(test.evidence); err != test.expectedErr {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.expectedErr)
+		}
+	}
+}
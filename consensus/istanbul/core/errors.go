@@ -41,4 +41,23 @@ var (
 	errFailedDecodePrepare = errors.New("failed to decode PREPARE")
 	// errFailedDecodeCommit is returned when the COMMIT message is malformed.
 	errFailedDecodeCommit = errors.New("failed to decode COMMIT")
+	// errInvalidCommittedSeal is returned when the committed seal of a COMMIT
+	// message is not signed by its sender.
+	errInvalidCommittedSeal = errors.New("invalid committed seal")
+	// errInvalidPreparedCertificate is returned when a ROUND CHANGE message carries
+	// a prepared certificate that doesn't prove its proposal was prepared.
+	errInvalidPreparedCertificate = errors.New("invalid prepared certificate")
+	// errInvalidRoundChangeCertificate is returned when a PRE-PREPARE message of a
+	// round after the first doesn't carry enough valid ROUND CHANGE messages.
+	errInvalidRoundChangeCertificate = errors.New("invalid round change certificate")
+	// errInvalidPreparedProposal is returned when a PRE-PREPARE message doesn't
+	// propose the proposal of the highest prepared certificate in its round
+	// change certificate.
+	errInvalidPreparedProposal = errors.New("proposal does not match the highest prepared certificate")
+	// errInvalidEquivocation is returned when an evidence doesn't hold two conflicting
+	// messages signed by the accused validator.
+	errInvalidEquivocation = errors.New("invalid equivocation evidence")
+	// errConflictingMessage is returned when the validator is about to sign a message
+	// conflicting with one it signed before for the same view.
+	errConflictingMessage = errors.New("message conflicts with a message signed before")
 )
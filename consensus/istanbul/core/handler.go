@@ -23,8 +23,16 @@ import (
 
 // Start implements core.Engine.Start
 func (c *core) Start() error {
-	// Start a new round from last sequence + 1
+	if err := c.wal.load(); err != nil {
+		return err
+	}
+
+	// Start a new round from last sequence + 1, and restore what was signed in
+	// the sequence before a restart
+	c.stateMu.Lock()
 	c.startNewRound(common.Big0)
+	c.restoreWAL()
+	c.stateMu.Unlock()
 
 	// Tests will handle events itself, so we have to make subscribeEvents()
 	// be able to call in test.
@@ -45,6 +53,9 @@ func (c *core) Stop() error {
 }
 
 func (c *core) CurrentView() *istanbul.View {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
 	return c.currentView()
 }
 
@@ -77,7 +88,9 @@ func (c *core) unsubscribeEvents() {
 func (c *core) handleEvents() {
 	// Clear state
 	defer func() {
+		c.stateMu.Lock()
 		c.current = nil
+		c.stateMu.Unlock()
 		c.handlerWg.Done()
 	}()
 
@@ -90,6 +103,7 @@ func (c *core) handleEvents() {
 				return
 			}
 			// A real event arrived, process interesting content
+			c.stateMu.Lock()
 			switch ev := event.Data.(type) {
 			case istanbul.RequestEvent:
 				r := &istanbul.Request{
@@ -109,18 +123,23 @@ func (c *core) handleEvents() {
 					c.logger.Error("Error in handling istanbul message that was sent from a backlog event", "err", err)
 				}
 			}
+			c.stateMu.Unlock()
 		case _, ok := <-c.timeoutSub.Chan():
 			if !ok {
 				return
 			}
+			c.stateMu.Lock()
 			c.handleTimeoutMsg()
+			c.stateMu.Unlock()
 		case event, ok := <-c.finalCommittedSub.Chan():
 			if !ok {
 				return
 			}
 			switch event.Data.(type) {
 			case istanbul.FinalCommittedEvent:
+				c.stateMu.Lock()
 				c.handleFinalCommitted()
+				c.stateMu.Unlock()
 			}
 		}
 	}
@@ -151,9 +170,31 @@ func (c *core) handleMsg(payload []byte) error {
 	return c.handleCheckedMsg(msg, src)
 }
 
+// VerifyMessage decodes a consensus message and checks that it is signed by the
+// validator of the given validator set it claims to come from, returning the
+// validator's address.
+func VerifyMessage(payload []byte, valSet istanbul.ValidatorSet) (common.Address, error) {
+	var signer common.Address
+	msg := new(message)
+	err := msg.FromPayload(payload, func(data []byte, sig []byte) (common.Address, error) {
+		var err error
+		signer, err = istanbul.CheckValidatorSignature(valSet, data, sig)
+		return signer, err
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if signer != msg.Address {
+		return common.Address{}, errInvalidSigner
+	}
+	return msg.Address, nil
+}
+
 func (c *core) handleCheckedMsg(msg *message, src istanbul.Validator) error {
 	logger := c.logger.New("address", c.address, "from", src)
 
+	c.checkEquivocation(msg)
+
 	// Store the message if it's a future message
 	testBacklog := func(err error) error {
 		if err == errFutureMessage {
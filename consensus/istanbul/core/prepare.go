@@ -62,6 +62,7 @@ func (c *core) handlePrepare(msg *message, src istanbul.Validator) error {
 	if ((c.current.IsHashLocked() && prepare.Digest == c.current.GetLockedHash()) || c.current.GetPrepareOrCommitSize() > 2*c.valSet.F()) &&
 		c.state.Cmp(StatePrepared) < 0 {
 		c.current.LockHash()
+		c.updatePreparedCertificate()
 		c.setState(StatePrepared)
 		c.sendCommit()
 	}
@@ -82,6 +83,21 @@ func (c *core) verifyPrepare(prepare *istanbul.Subject, src istanbul.Validator)
 	return nil
 }
 
+// updatePreparedCertificate replaces the prepared certificate of the round state
+// with one of the current proposal, if enough PREPARE or COMMIT messages for it
+// were received.
+func (c *core) updatePreparedCertificate() {
+	if c.current.GetPrepareOrCommitSize() <= 2*c.valSet.F() {
+		return
+	}
+	preparedCertificate, err := c.createPreparedCertificate()
+	if err != nil {
+		c.logger.Error("Failed to create prepared certificate", "err", err)
+		return
+	}
+	c.current.SetPreparedCertificate(preparedCertificate, c.current.Proposal())
+}
+
 func (c *core) acceptPrepare(msg *message, src istanbul.Validator) error {
 	logger := c.logger.New("from", src, "state", c.state)
 
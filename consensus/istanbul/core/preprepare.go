@@ -19,6 +19,7 @@ package core
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 )
@@ -29,9 +30,25 @@ func (c *core) sendPreprepare(request *istanbul.Request) {
 	// If I'm the proposer and I have the same sequence with the proposal
 	if c.current.Sequence().Cmp(request.Proposal.Number()) == 0 && c.isProposer() {
 		curView := c.currentView()
+		roundChangeCertificate := c.current.RoundChangeCertificate()
+
+		// Proposals after the first round must be justified by the ROUND CHANGE
+		// messages that moved the validators to the round
+		if curView.Round.Sign() > 0 {
+			preparedHash, err := c.verifyRoundChangeCertificate(curView, roundChangeCertificate)
+			if err != nil {
+				logger.Warn("Cannot propose without a round change certificate", "view", curView, "err", err)
+				return
+			}
+			if (preparedHash != common.Hash{}) && preparedHash != request.Proposal.Hash() {
+				logger.Warn("Cannot propose other than the highest prepared proposal", "view", curView, "prepared", preparedHash)
+				return
+			}
+		}
 		preprepare, err := Encode(&istanbul.Preprepare{
-			View:     curView,
-			Proposal: request.Proposal,
+			View:                   curView,
+			Proposal:               request.Proposal,
+			RoundChangeCertificate: roundChangeCertificate,
 		})
 		if err != nil {
 			logger.Error("Failed to encode", "view", curView)
@@ -80,13 +97,28 @@ func (c *core) handlePreprepare(msg *message, src istanbul.Validator) error {
 		return errNotFromProposer
 	}
 
+	// Proposals after the first round must carry the ROUND CHANGE messages that
+	// moved the validators to the round, and re-propose the proposal of the
+	// highest prepared certificate among them
+	if preprepare.View.Round.Sign() > 0 {
+		preparedHash, err := c.verifyRoundChangeCertificate(preprepare.View, preprepare.RoundChangeCertificate)
+		if err != nil {
+			logger.Warn("Invalid round change certificate in PRE-PREPARE", "err", err)
+			return err
+		}
+		if (preparedHash != common.Hash{}) && preparedHash != preprepare.Proposal.Hash() {
+			logger.Warn("PRE-PREPARE does not propose the highest prepared proposal", "proposal", preprepare.Proposal.Hash(), "prepared", preparedHash)
+			return errInvalidPreparedProposal
+		}
+	}
+
 	// Verify the proposal we received
 	if duration, err := c.backend.Verify(preprepare.Proposal, src); err != nil {
 		logger.Warn("Failed to verify proposal", "err", err, "duration", duration)
 		// if it's a future block, we will handle it again after the duration
 		if err == consensus.ErrFutureBlock {
 			c.stopFuturePreprepareTimer()
-			c.futurePreprepareTimer = time.AfterFunc(duration, func() {
+			c.futurePreprepareTimer = c.backend.Clock().AfterFunc(duration, func() {
 				c.sendEvent(backlogEvent{
 					src: src,
 					msg: msg,
@@ -107,6 +139,13 @@ func (c *core) handlePreprepare(msg *message, src istanbul.Validator) error {
 				c.acceptPreprepare(preprepare)
 				c.setState(StatePrepared)
 				c.sendCommit()
+			} else if preprepare.View.Round.Sign() > 0 {
+				// The round change certificate proves that the locked proposal
+				// was not committed, or it would be the highest prepared one
+				c.current.UnlockHash()
+				c.acceptPreprepare(preprepare)
+				c.setState(StatePreprepared)
+				c.sendPrepare()
 			} else {
 				// Send round change
 				c.sendNextRoundChange()
@@ -46,16 +46,30 @@ func (c *core) sendRoundChange(round *big.Int) {
 		Sequence: new(big.Int).Set(cv.Sequence),
 	})
 
-	// Now we have the new round number and sequence number
+	// Now we have the new round number and sequence number, send along the
+	// proof of the proposal we last saw prepared, and the proposal itself
 	cv = c.currentView()
-	rc := &istanbul.Subject{
-		View:   cv,
-		Digest: common.Hash{},
+	preparedCertificate, preparedProposal := c.current.PreparedCertificate()
+	request, err := Encode(&istanbul.RoundChangeRequest{
+		View:                cv,
+		PreparedCertificate: preparedCertificate,
+	})
+	if err != nil {
+		logger.Error("Failed to encode ROUND CHANGE REQUEST", "view", cv, "err", err)
+		return
+	}
+	rc := new(istanbul.RoundChange)
+	if rc.Request, err = c.finalizeMessage(&message{Code: msgRoundChangeRequest, Msg: request}); err != nil {
+		logger.Error("Failed to sign ROUND CHANGE REQUEST", "view", cv, "err", err)
+		return
+	}
+	if !preparedCertificate.IsEmpty() {
+		rc.PreparedProposal = preparedProposal
 	}
 
 	payload, err := Encode(rc)
 	if err != nil {
-		logger.Error("Failed to encode ROUND CHANGE", "rc", rc, "err", err)
+		logger.Error("Failed to encode ROUND CHANGE", "view", cv, "err", err)
 		return
 	}
 
@@ -69,18 +83,30 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 	logger := c.logger.New("state", c.state, "from", src.Address().Hex())
 
 	// Decode ROUND CHANGE message
-	var rc *istanbul.Subject
-	if err := msg.Decode(&rc); err != nil {
+	rc, request, err := c.decodeRoundChange(msg)
+	if err != nil {
 		logger.Error("Failed to decode ROUND CHANGE", "err", err)
-		return errInvalidMessage
+		return err
 	}
 
-	if err := c.checkMessage(msgRoundChange, rc.View); err != nil {
+	if err := c.checkMessage(msgRoundChange, request.View); err != nil {
 		return err
 	}
 
+	// The proposer of the new round re-proposes the proposal of the highest
+	// prepared certificate, so only valid certificates that come with their
+	// proposal are accepted
+	if _, err := c.verifyRoundChangeRequest(request); err != nil {
+		logger.Warn("Invalid prepared certificate in ROUND CHANGE", "err", err)
+		return err
+	}
+	if !request.PreparedCertificate.IsEmpty() && (rc.PreparedProposal == nil || rc.PreparedProposal.Hash() != request.PreparedCertificate.ProposalHash) {
+		logger.Warn("ROUND CHANGE does not carry the prepared proposal", "prepared", request.PreparedCertificate.ProposalHash)
+		return errInvalidPreparedCertificate
+	}
+
 	cv := c.currentView()
-	roundView := rc.View
+	roundView := request.View
 
 	// Add the ROUND CHANGE message to its message set and return how many
 	// messages we've got with the same round number and sequence number.
@@ -109,6 +135,23 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 	return nil
 }
 
+// decodeRoundChange decodes a ROUND CHANGE message and the request it carries,
+// which must be signed by the sender of the message.
+func (c *core) decodeRoundChange(msg *message) (*istanbul.RoundChange, *istanbul.RoundChangeRequest, error) {
+	var rc *istanbul.RoundChange
+	if err := msg.Decode(&rc); err != nil {
+		return nil, nil, errInvalidMessage
+	}
+	requestMsg, request, err := c.decodeRoundChangeRequest(rc.Request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if requestMsg.Address != msg.Address {
+		return nil, nil, errInvalidSigner
+	}
+	return rc, request, nil
+}
+
 // ----------------------------------------------------------------------------
 
 func newRoundChangeSet(valSet istanbul.ValidatorSet) *roundChangeSet {
@@ -141,6 +184,47 @@ func (rcs *roundChangeSet) Add(r *big.Int, msg *message) (int, error) {
 	return rcs.roundChanges[round].Size(), nil
 }
 
+// counts returns the number of rounds each validator sent a ROUND CHANGE message
+// to.
+func (rcs *roundChangeSet) counts() map[common.Address]int {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+
+	counts := make(map[common.Address]int)
+	for _, rms := range rcs.roundChanges {
+		for _, msg := range rms.Values() {
+			counts[msg.Address]++
+		}
+	}
+	return counts
+}
+
+// getCertificate returns a certificate of the requests of the ROUND CHANGE
+// messages to the given round, if there are at least quorum of them, and the
+// prepared proposals the messages carry by hash.
+func (rcs *roundChangeSet) getCertificate(r *big.Int, quorum int) (istanbul.RoundChangeCertificate, map[common.Hash]istanbul.Proposal, error) {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+
+	rms := rcs.roundChanges[r.Uint64()]
+	if rms == nil || rms.Size() < quorum {
+		return istanbul.RoundChangeCertificate{}, nil, errInvalidRoundChangeCertificate
+	}
+	requests := make([][]byte, 0, rms.Size())
+	proposals := make(map[common.Hash]istanbul.Proposal)
+	for _, msg := range rms.Values() {
+		var rc *istanbul.RoundChange
+		if err := msg.Decode(&rc); err != nil {
+			return istanbul.RoundChangeCertificate{}, nil, err
+		}
+		requests = append(requests, rc.Request)
+		if rc.PreparedProposal != nil {
+			proposals[rc.PreparedProposal.Hash()] = rc.PreparedProposal
+		}
+	}
+	return istanbul.RoundChangeCertificate{RoundChangeRequests: requests}, proposals, nil
+}
+
 // Clear deletes the messages with smaller round
 func (rcs *roundChangeSet) Clear(round *big.Int) {
 	rcs.mu.Lock()
@@ -33,11 +33,7 @@ func TestRoundChangeSet(t *testing.T) {
 		Sequence: big.NewInt(1),
 		Round:    big.NewInt(1),
 	}
-	r := &istanbul.Subject{
-		View:   view,
-		Digest: common.Hash{},
-	}
-	m, _ := Encode(r)
+	m := newRoundChange(view, istanbul.PreparedCertificate{}, nil, common.Address{})
 
 	// Test Add()
 	// Add message from all validators
@@ -53,6 +53,10 @@ type roundState struct {
 	lockedHash     common.Hash
 	pendingRequest *istanbul.Request
 
+	preparedCertificate    istanbul.PreparedCertificate    // Proof of the proposal last seen prepared in this sequence
+	preparedProposal       istanbul.Proposal               // Proposal the prepared certificate refers to
+	roundChangeCertificate istanbul.RoundChangeCertificate // Proof that the validators moved to this round
+
 	mu             *sync.RWMutex
 	hasBadProposal func(hash common.Hash) bool
 }
@@ -121,6 +125,16 @@ func (s *roundState) Round() *big.Int {
 	return s.round
 }
 
+func (s *roundState) View() *istanbul.View {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &istanbul.View{
+		Sequence: new(big.Int).Set(s.sequence),
+		Round:    new(big.Int).Set(s.round),
+	}
+}
+
 func (s *roundState) SetSequence(seq *big.Int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -168,6 +182,35 @@ func (s *roundState) GetLockedHash() common.Hash {
 	return s.lockedHash
 }
 
+func (s *roundState) SetPreparedCertificate(preparedCertificate istanbul.PreparedCertificate, preparedProposal istanbul.Proposal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.preparedCertificate = preparedCertificate
+	s.preparedProposal = preparedProposal
+}
+
+func (s *roundState) PreparedCertificate() (istanbul.PreparedCertificate, istanbul.Proposal) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.preparedCertificate, s.preparedProposal
+}
+
+func (s *roundState) SetRoundChangeCertificate(roundChangeCertificate istanbul.RoundChangeCertificate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roundChangeCertificate = roundChangeCertificate
+}
+
+func (s *roundState) RoundChangeCertificate() istanbul.RoundChangeCertificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.roundChangeCertificate
+}
+
 // The DecodeRLP method should read one value from the given
 // Stream. It is not forbidden to read less or more, but it might
 // be confusing.
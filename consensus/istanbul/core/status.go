@@ -0,0 +1,144 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// ValidatorStatus counts the messages of a validator in the current view.
+type ValidatorStatus struct {
+	Address      common.Address `json:"address"`
+	Prepares     int            `json:"prepares"`     // PREPARE messages for the current round
+	Commits      int            `json:"commits"`      // COMMIT messages for the current round
+	RoundChanges int            `json:"roundChanges"` // ROUND CHANGE messages to rounds of the current sequence
+	Backlog      int            `json:"backlog"`      // Future messages waiting in the backlog
+}
+
+// Status is a snapshot of the consensus state, to diagnose liveness issues.
+type Status struct {
+	View                  *istanbul.View     `json:"view"`
+	State                 string             `json:"state"`
+	LockedHash            common.Hash        `json:"lockedHash"`
+	Proposer              common.Address     `json:"proposer"`
+	WaitingForRoundChange bool               `json:"waitingForRoundChange"`
+	Validators            []*ValidatorStatus `json:"validators"`
+}
+
+// StatusEvent is posted when the consensus moves to a new view or state.
+type StatusEvent struct {
+	View       *istanbul.View `json:"view"`
+	State      string         `json:"state"`
+	NewView    bool           `json:"newView"` // Whether the event is for a new round or sequence, rather than a state transition
+	LockedHash common.Hash    `json:"lockedHash"`
+}
+
+// Status implements core.Engine.Status
+func (c *core) Status() *Status {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	current, valSet := c.current, c.valSet
+	if current == nil || valSet == nil {
+		return nil
+	}
+	status := &Status{
+		View:                  current.View(),
+		State:                 c.state.String(),
+		LockedHash:            current.GetLockedHash(),
+		WaitingForRoundChange: c.waitingForRoundChange,
+	}
+	if proposer := valSet.GetProposer(); proposer != nil {
+		status.Proposer = proposer.Address()
+	}
+
+	validators := make(map[common.Address]*ValidatorStatus)
+	for _, val := range valSet.List() {
+		validators[val.Address()] = &ValidatorStatus{Address: val.Address()}
+		status.Validators = append(status.Validators, validators[val.Address()])
+	}
+	for _, msg := range current.Prepares.Values() {
+		if val := validators[msg.Address]; val != nil {
+			val.Prepares++
+		}
+	}
+	for _, msg := range current.Commits.Values() {
+		if val := validators[msg.Address]; val != nil {
+			val.Commits++
+		}
+	}
+	if c.roundChangeSet != nil {
+		for addr, count := range c.roundChangeSet.counts() {
+			if val := validators[addr]; val != nil {
+				val.RoundChanges = count
+			}
+		}
+	}
+
+	c.backlogsMu.Lock()
+	for src, backlog := range c.backlogs {
+		if val := validators[src.Address()]; val != nil {
+			val.Backlog = backlog.Size()
+		}
+	}
+	c.backlogsMu.Unlock()
+
+	return status
+}
+
+// SubscribeStatusEvents implements core.Engine.SubscribeStatusEvents
+func (c *core) SubscribeStatusEvents(ch chan<- StatusEvent) event.Subscription {
+	c.statusSubsMu.Lock()
+	c.statusSubs[ch] = struct{}{}
+	c.statusSubsMu.Unlock()
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		c.statusSubsMu.Lock()
+		delete(c.statusSubs, ch)
+		c.statusSubsMu.Unlock()
+		return nil
+	})
+}
+
+// postStatusEvent notifies the subscribers of the current view and state. The
+// consensus never waits for the subscribers: the events of a subscriber that is
+// lagging behind are dropped.
+func (c *core) postStatusEvent(newView bool) {
+	if c.current == nil {
+		return
+	}
+	ev := StatusEvent{
+		View:       c.current.View(),
+		State:      c.state.String(),
+		NewView:    newView,
+		LockedHash: c.current.GetLockedHash(),
+	}
+
+	c.statusSubsMu.Lock()
+	defer c.statusSubsMu.Unlock()
+
+	for ch := range c.statusSubs {
+		select {
+		case ch <- ev:
+		default:
+			c.logger.Trace("Dropped status event of a lagging subscriber", "view", ev.View, "state", ev.State)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+func TestStatus(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	r0 := sys.backends[0].engine.(*core)
+	r0.roundChangeSet = newRoundChangeSet(r0.valSet)
+	val1, val2 := r0.valSet.GetByIndex(1), r0.valSet.GetByIndex(2)
+
+	view := r0.currentView()
+	subject := &istanbul.Subject{View: view, Digest: common.HexToHash("0x1")}
+	r0.current.Prepares.Add(&message{Code: msgPrepare, Address: val1.Address()})
+	r0.current.Prepares.Add(&message{Code: msgPrepare, Address: val2.Address()})
+	r0.current.Commits.Add(&message{Code: msgCommit, Address: val1.Address()})
+	r0.roundChangeSet.Add(big.NewInt(1), &message{Code: msgRoundChange, Address: val2.Address()})
+	r0.roundChangeSet.Add(big.NewInt(2), &message{Code: msgRoundChange, Address: val2.Address()})
+	r0.storeBacklog(&message{Code: msgPrepare, Msg: func() []byte {
+		future, _ := Encode(&istanbul.Subject{View: &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(3)}, Digest: subject.Digest})
+		return future
+	}(), Address: val1.Address()}, val1)
+
+	status := r0.Status()
+	if status == nil {
+		t.Fatalf("status is nil")
+	}
+	if status.View.Cmp(view) != 0 || status.State != StateAcceptRequest.String() || status.Proposer != r0.valSet.GetProposer().Address() {
+		t.Errorf("status mismatch: have view %v state %v proposer %v", status.View, status.State, status.Proposer)
+	}
+	want := map[common.Address]ValidatorStatus{
+		val1.Address(): {Address: val1.Address(), Prepares: 1, Commits: 1, Backlog: 1},
+		val2.Address(): {Address: val2.Address(), Prepares: 1, RoundChanges: 2},
+	}
+	if len(status.Validators) != 4 {
+		t.Fatalf("validators mismatch: have %d, want 4", len(status.Validators))
+	}
+	for _, val := range status.Validators {
+		if expected, ok := want[val.Address]; ok && *val != expected {
+			t.Errorf("validator status mismatch: have %+v, want %+v", *val, expected)
+		}
+	}
+}
+
+func TestStatusEvents(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	r0 := sys.backends[0].engine.(*core)
+	r0.roundChangeSet = newRoundChangeSet(r0.valSet)
+
+	events := make(chan StatusEvent, 10)
+	sub := r0.SubscribeStatusEvents(events)
+	defer sub.Unsubscribe()
+
+	r0.setState(StatePreprepared)
+	r0.setState(StatePreprepared)
+	r0.catchUpRound(&istanbul.View{Round: big.NewInt(1), Sequence: r0.current.Sequence()})
+
+	if ev := <-events; ev.NewView || ev.State != StatePreprepared.String() {
+		t.Errorf("event mismatch: have %+v, want state transition to %v", ev, StatePreprepared)
+	}
+	if ev := <-events; !ev.NewView || ev.View.Round.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("event mismatch: have %+v, want new view of round 1", ev)
+	}
+	if len(events) != 0 {
+		t.Errorf("events mismatch: have %d more", len(events))
+	}
+
+	// Lagging subscribers don't block the consensus, their events are dropped
+	lagging := make(chan StatusEvent, 1)
+	laggingSub := r0.SubscribeStatusEvents(lagging)
+	defer laggingSub.Unsubscribe()
+
+	r0.setState(StatePrepared)
+	r0.setState(StateCommitted)
+	if len(lagging) != 1 || len(events) != 2 {
+		t.Errorf("events mismatch: have %d for the lagging subscriber, %d for the other, want 1 and 2", len(lagging), len(events))
+	}
+	if ev := <-lagging; ev.State != StatePrepared.String() {
+		t.Errorf("event mismatch: have %+v, want state transition to %v", ev, StatePrepared)
+	}
+
+	// Unsubscribed channels are no longer sent to
+	laggingSub.Unsubscribe()
+	r0.setState(StatePreprepared)
+	if len(lagging) != 0 {
+		t.Errorf("events mismatch: have %d after unsubscribing", len(lagging))
+	}
+}
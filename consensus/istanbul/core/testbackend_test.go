@@ -17,7 +17,9 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	elog "github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var testLogger = elog.New()
@@ -42,7 +45,8 @@ type testSystemBackend struct {
 	events *event.TypeMux
 
 	committedMsgs []testCommittedMsgs
-	sentMsgs      [][]byte // store the message when Send is called by core
+	sentMsgs      [][]byte                 // store the message when Send is called by core
+	equivocations []*istanbul.Equivocation // store the evidence reported by core
 
 	address common.Address
 	db      ethdb.Database
@@ -66,6 +70,10 @@ func (self *testSystemBackend) Address() common.Address {
 	return self.address
 }
 
+func (self *testSystemBackend) Clock() istanbul.Clock {
+	return istanbul.SystemClock
+}
+
 // Peers returns all connected peers
 func (self *testSystemBackend) Validators(proposal istanbul.Proposal) istanbul.ValidatorSet {
 	return self.peers
@@ -97,7 +105,7 @@ func (self *testSystemBackend) Gossip(valSet istanbul.ValidatorSet, message []by
 	return nil
 }
 
-func (self *testSystemBackend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
+func (self *testSystemBackend) Commit(proposal istanbul.Proposal, committers []common.Address, seals [][]byte) error {
 	testLogger.Info("commit message", "address", self.Address())
 	self.committedMsgs = append(self.committedMsgs, testCommittedMsgs{
 		commitProposal: proposal,
@@ -118,12 +126,31 @@ func (self *testSystemBackend) Sign(data []byte) ([]byte, error) {
 	return data, nil
 }
 
+// SignCommittedSeal prefixes the committed seal with the address of the signer,
+// as messages are not signed in tests.
+func (self *testSystemBackend) SignCommittedSeal(seal []byte, number *big.Int, valSet istanbul.ValidatorSet) ([]byte, error) {
+	return append(self.Address().Bytes(), seal...), nil
+}
+
 func (self *testSystemBackend) CheckSignature([]byte, common.Address, []byte) error {
 	return nil
 }
 
+func (self *testSystemBackend) CheckCommittedSeal(seal []byte, validator istanbul.Validator, committedSeal []byte, number *big.Int) error {
+	if !bytes.Equal(committedSeal, append(validator.Address().Bytes(), seal...)) {
+		return errors.New("committed seal not signed by validator")
+	}
+	return nil
+}
+
+// CheckValidatorSignature returns the address messages claim to come from, as
+// messages are not signed in tests.
 func (self *testSystemBackend) CheckValidatorSignature(data []byte, sig []byte) (common.Address, error) {
-	return common.Address{}, nil
+	var msg message
+	if err := rlp.DecodeBytes(data, &msg); err != nil {
+		return common.Address{}, err
+	}
+	return msg.Address, nil
 }
 
 func (self *testSystemBackend) Hash(b interface{}) common.Hash {
@@ -140,6 +167,10 @@ func (self *testSystemBackend) HasBadProposal(hash common.Hash) bool {
 	return false
 }
 
+func (self *testSystemBackend) ReportEquivocation(evidence *istanbul.Equivocation) {
+	self.equivocations = append(self.equivocations, evidence)
+}
+
 func (self *testSystemBackend) LastProposal() (istanbul.Proposal, common.Address) {
 	l := len(self.committedMsgs)
 	if l > 0 {
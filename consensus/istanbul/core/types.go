@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -30,6 +31,10 @@ type Engine interface {
 	Stop() error
 	CurrentView() *istanbul.View
 	SetAddress(common.Address)
+	// Status returns a snapshot of the consensus state, or nil if not started
+	Status() *Status
+	// SubscribeStatusEvents notifies the changes of the view and state
+	SubscribeStatusEvents(ch chan<- StatusEvent) event.Subscription
 }
 
 type State uint64
@@ -74,6 +79,9 @@ const (
 	msgPrepare
 	msgCommit
 	msgRoundChange
+	// msgRoundChangeRequest is only sent within ROUND CHANGE messages, which
+	// round change certificates are made of
+	msgRoundChangeRequest
 )
 
 type message struct {
@@ -172,8 +172,70 @@ func testSubjectWithSignature(t *testing.T) {
 	}
 }
 
+func testRoundChange(t *testing.T) {
+	view := &istanbul.View{
+		Round:    big.NewInt(1),
+		Sequence: big.NewInt(2),
+	}
+	proposal := makeBlock(2)
+	for _, request := range []*istanbul.RoundChangeRequest{
+		{View: view},
+		{View: view, PreparedCertificate: istanbul.PreparedCertificate{
+			ProposalHash:            proposal.Hash(),
+			PrepareOrCommitMessages: [][]byte{{0x01}, {0x02}},
+		}},
+	} {
+		payload, err := Encode(request)
+		if err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+		var decodedRequest *istanbul.RoundChangeRequest
+		if err := (&message{Msg: payload}).Decode(&decodedRequest); err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+		if !reflect.DeepEqual(request.View, decodedRequest.View) {
+			t.Errorf("view mismatch: have %v, want %v", decodedRequest.View, request.View)
+		}
+		if request.PreparedCertificate.ProposalHash != decodedRequest.PreparedCertificate.ProposalHash {
+			t.Errorf("proposal hash mismatch: have %v, want %v", decodedRequest.PreparedCertificate.ProposalHash, request.PreparedCertificate.ProposalHash)
+		}
+		if len(request.PreparedCertificate.PrepareOrCommitMessages) != 0 && !reflect.DeepEqual(request.PreparedCertificate.PrepareOrCommitMessages, decodedRequest.PreparedCertificate.PrepareOrCommitMessages) {
+			t.Errorf("prepared messages mismatch: have %v, want %v", decodedRequest.PreparedCertificate.PrepareOrCommitMessages, request.PreparedCertificate.PrepareOrCommitMessages)
+		}
+
+		// The prepared proposal travels once, next to the request referring to it
+		rc := &istanbul.RoundChange{Request: payload}
+		if !request.PreparedCertificate.IsEmpty() {
+			rc.PreparedProposal = proposal
+		}
+		payload, err = Encode(rc)
+		if err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+		m := &message{
+			Code:    msgRoundChange,
+			Msg:     payload,
+			Address: common.HexToAddress("0x1234567890"),
+		}
+
+		var decodedRC *istanbul.RoundChange
+		if err := m.Decode(&decodedRC); err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+		if !reflect.DeepEqual(rc.Request, decodedRC.Request) {
+			t.Errorf("request mismatch: have %x, want %x", decodedRC.Request, rc.Request)
+		}
+		if (rc.PreparedProposal == nil) != (decodedRC.PreparedProposal == nil) {
+			t.Errorf("prepared proposal mismatch: have %v, want %v", decodedRC.PreparedProposal, rc.PreparedProposal)
+		} else if rc.PreparedProposal != nil && rc.PreparedProposal.Hash() != decodedRC.PreparedProposal.Hash() {
+			t.Errorf("proposal hash mismatch: have %v, want %v", decodedRC.PreparedProposal.Hash(), rc.PreparedProposal.Hash())
+		}
+	}
+}
+
 func TestMessageEncodeDecode(t *testing.T) {
 	testPreprepare(t)
 	testSubject(t)
 	testSubjectWithSignature(t)
+	testRoundChange(t)
 }
@@ -0,0 +1,187 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// walRecord is what a validator signed in a sequence, so that it doesn't sign
+// conflicting messages after a restart.
+type walRecord struct {
+	Sequence   *big.Int
+	LockedHash common.Hash
+	Preprepare []byte   // Encoded PRE-PREPARE of the locked proposal, empty if not locked
+	Messages   [][]byte // Signed messages sent in the sequence
+}
+
+// wal is the write-ahead log of the messages signed by the validator, written
+// before they are sent. Only the record of the latest sequence is kept. The log
+// is kept in memory only if it has no path.
+type wal struct {
+	path   string
+	record *walRecord
+}
+
+func newWAL(path string) *wal {
+	return &wal{path: path}
+}
+
+// load loads the record left in the write-ahead log by the previous run, if
+// there is one.
+func (w *wal) load() error {
+	if w.path == "" {
+		return nil
+	}
+	blob, err := ioutil.ReadFile(w.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	record := new(walRecord)
+	if err := rlp.DecodeBytes(blob, record); err != nil {
+		return err
+	}
+	w.record = record
+	return nil
+}
+
+// append records a signed message of the given sequence together with the lock
+// state, replacing the record of an earlier sequence. The log is synced to disk
+// before append returns.
+func (w *wal) append(sequence *big.Int, lockedHash common.Hash, preprepare *istanbul.Preprepare, payload []byte) error {
+	record := &walRecord{Sequence: new(big.Int).Set(sequence), LockedHash: lockedHash, Preprepare: []byte{}}
+	if w.record != nil && w.record.Sequence.Cmp(sequence) == 0 {
+		record.Messages = append(record.Messages, w.record.Messages...)
+	}
+	record.Messages = append(record.Messages, payload)
+	if lockedHash != (common.Hash{}) && preprepare != nil {
+		encoded, err := Encode(preprepare)
+		if err != nil {
+			return err
+		}
+		record.Preprepare = encoded
+	}
+
+	if w.path != "" {
+		blob, err := rlp.EncodeToBytes(record)
+		if err != nil {
+			return err
+		}
+		if err := writeFileSync(w.path, blob); err != nil {
+			return err
+		}
+	}
+	w.record = record
+	return nil
+}
+
+// writeFileSync atomically replaces the file with the given data, making sure
+// the data is on disk.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// signedDigests returns the digests of the messages of the given sequence in the
+// log, by message code and round.
+func (w *wal) signedDigests(sequence *big.Int) map[signedMessageKey]common.Hash {
+	digests := make(map[signedMessageKey]common.Hash)
+	if w.record == nil || w.record.Sequence.Cmp(sequence) != 0 {
+		return digests
+	}
+	for _, payload := range w.record.Messages {
+		msg := new(message)
+		if err := msg.FromPayload(payload, nil); err != nil {
+			continue
+		}
+		view, digest, err := messageDigest(msg)
+		if err != nil {
+			continue
+		}
+		digests[signedMessageKey{code: msg.Code, sequence: view.Sequence.Uint64(), round: view.Round.Uint64(), signer: msg.Address}] = digest
+	}
+	return digests
+}
+
+// checkSigned returns an error if the message conflicts with a message signed
+// before for the same view, according to the write-ahead log.
+func (c *core) checkSigned(msg *message) error {
+	view, digest, err := messageDigest(msg)
+	if err != nil || view == nil || view.Round == nil || view.Sequence == nil {
+		return nil
+	}
+	key := signedMessageKey{code: msg.Code, sequence: view.Sequence.Uint64(), round: view.Round.Uint64(), signer: c.Address()}
+	if signed, ok := c.wal.signedDigests(view.Sequence)[key]; ok && signed != digest {
+		return errConflictingMessage
+	}
+	return nil
+}
+
+// restoreWAL restores the round and the lock of the current sequence from the
+// write-ahead log, after the validator restarted in the middle of the sequence.
+func (c *core) restoreWAL() {
+	record := c.wal.record
+	if record == nil || record.Sequence.Cmp(c.current.Sequence()) != 0 {
+		return
+	}
+	logger := c.logger.New("sequence", record.Sequence)
+
+	// Move to the latest round a message was signed in
+	round := new(big.Int)
+	for key := range c.wal.signedDigests(record.Sequence) {
+		if r := new(big.Int).SetUint64(key.round); r.Cmp(round) > 0 {
+			round = r
+		}
+	}
+	if round.Cmp(c.current.Round()) > 0 {
+		c.startNewRound(round)
+	}
+
+	if record.LockedHash != (common.Hash{}) && len(record.Preprepare) > 0 {
+		var preprepare *istanbul.Preprepare
+		if err := rlp.DecodeBytes(record.Preprepare, &preprepare); err != nil {
+			logger.Error("Failed to decode locked proposal in write-ahead log", "err", err)
+			return
+		}
+		c.current.SetPreprepare(preprepare)
+		c.current.LockHash()
+	}
+	logger.Info("Restored consensus state from write-ahead log", "round", c.current.Round(), "locked", c.current.GetLockedHash())
+}
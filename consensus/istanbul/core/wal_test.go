@@ -0,0 +1,114 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+func TestWAL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "istanbul-wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wal")
+
+	signer := common.HexToAddress("0x1")
+	view := &istanbul.View{Round: big.NewInt(1), Sequence: big.NewInt(1)}
+	prepare := newCertificateMessage(msgPrepare, &istanbul.Subject{View: view, Digest: common.HexToHash("0x1")}, signer)
+
+	w := newWAL(path)
+	if err := w.load(); err != nil {
+		t.Fatalf("failed to load empty log: %v", err)
+	}
+	if err := w.append(view.Sequence, common.Hash{}, nil, prepare); err != nil {
+		t.Fatalf("failed to append message: %v", err)
+	}
+
+	// The messages are read back after a restart
+	w = newWAL(path)
+	if err := w.load(); err != nil {
+		t.Fatalf("failed to load log: %v", err)
+	}
+	key := signedMessageKey{code: msgPrepare, sequence: 1, round: 1, signer: signer}
+	if digest := w.signedDigests(view.Sequence)[key]; digest != common.HexToHash("0x1") {
+		t.Errorf("digest mismatch: have %v, want %v", digest, common.HexToHash("0x1"))
+	}
+	if digests := w.signedDigests(big.NewInt(2)); len(digests) != 0 {
+		t.Errorf("digests mismatch: have %d, want 0", len(digests))
+	}
+
+	// Messages of a new sequence replace the old ones
+	next := &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(2)}
+	if err := w.append(next.Sequence, common.Hash{}, nil, newCertificateMessage(msgPrepare, &istanbul.Subject{View: next, Digest: common.HexToHash("0x2")}, signer)); err != nil {
+		t.Fatalf("failed to append message: %v", err)
+	}
+	if digests := w.signedDigests(view.Sequence); len(digests) != 0 {
+		t.Errorf("digests mismatch: have %d, want 0", len(digests))
+	}
+	if digests := w.signedDigests(next.Sequence); len(digests) != 1 {
+		t.Errorf("digests mismatch: have %d, want 1", len(digests))
+	}
+}
+
+func TestRestoreWAL(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+
+	closer := sys.Run(true)
+	defer closer()
+
+	r0 := sys.backends[0].engine.(*core)
+	r0.roundChangeSet = newRoundChangeSet(r0.valSet)
+	sequence := r0.current.Sequence()
+	view := &istanbul.View{Round: big.NewInt(2), Sequence: sequence}
+	proposal := makeBlock(sequence.Int64())
+	preprepare := &istanbul.Preprepare{View: &istanbul.View{Round: big.NewInt(1), Sequence: sequence}, Proposal: proposal}
+	commit := newCertificateMessage(msgCommit, &istanbul.Subject{View: view, Digest: proposal.Hash()}, r0.Address())
+
+	// Simulate a restart after the validator committed to the proposal in round 2
+	r0.wal = newWAL("")
+	if err := r0.wal.append(sequence, proposal.Hash(), preprepare, commit); err != nil {
+		t.Fatalf("failed to append message: %v", err)
+	}
+	r0.restoreWAL()
+
+	if r0.current.Round().Cmp(view.Round) != 0 {
+		t.Errorf("round mismatch: have %v, want %v", r0.current.Round(), view.Round)
+	}
+	if r0.current.GetLockedHash() != proposal.Hash() {
+		t.Errorf("locked hash mismatch: have %v, want %v", r0.current.GetLockedHash(), proposal.Hash())
+	}
+
+	// A conflicting commit is refused, the same one is not
+	msg := &message{Code: msgCommit}
+	msg.Msg, _ = Encode(&istanbul.Subject{View: view, Digest: common.HexToHash("0x1")})
+	if err := r0.checkSigned(msg); err != errConflictingMessage {
+		t.Errorf("error mismatch: have %v, want %v", err, errConflictingMessage)
+	}
+	msg.Msg, _ = Encode(&istanbul.Subject{View: view, Digest: proposal.Hash()})
+	if err := r0.checkSigned(msg); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+}
@@ -17,6 +17,7 @@
 package istanbul
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -92,30 +93,104 @@ func (v *View) Cmp(y *View) int {
 }
 
 type Preprepare struct {
-	View     *View
-	Proposal Proposal
+	View                   *View
+	Proposal               Proposal
+	RoundChangeCertificate RoundChangeCertificate
 }
 
 // EncodeRLP serializes b into the Ethereum RLP format.
 func (b *Preprepare) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{b.View, b.Proposal})
+	return rlp.Encode(w, []interface{}{b.View, b.Proposal, &b.RoundChangeCertificate})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the consensus fields from a RLP stream.
 func (b *Preprepare) DecodeRLP(s *rlp.Stream) error {
 	var preprepare struct {
-		View     *View
-		Proposal *types.Block
+		View                   *View
+		Proposal               *types.Block
+		RoundChangeCertificate RoundChangeCertificate
 	}
 
 	if err := s.Decode(&preprepare); err != nil {
 		return err
 	}
-	b.View, b.Proposal = preprepare.View, preprepare.Proposal
+	b.View, b.Proposal, b.RoundChangeCertificate = preprepare.View, preprepare.Proposal, preprepare.RoundChangeCertificate
+
+	return nil
+}
+
+// PreparedCertificate proves that the proposal with the given hash was prepared,
+// by the signed PREPARE or COMMIT messages for it of more than two thirds of the
+// validators. A certificate without a proposal hash proves nothing.
+type PreparedCertificate struct {
+	ProposalHash            common.Hash
+	PrepareOrCommitMessages [][]byte
+}
+
+// IsEmpty returns whether the certificate refers to no proposal.
+func (pc *PreparedCertificate) IsEmpty() bool {
+	return pc.ProposalHash == common.Hash{}
+}
+
+// RoundChangeRequest is the request of a validator to move to a new round. It
+// carries the certificate of the proposal the validator last saw prepared, if
+// any, so the proposer of the new round re-proposes it.
+type RoundChangeRequest struct {
+	View                *View
+	PreparedCertificate PreparedCertificate
+}
+
+// RoundChange is the message a validator sends to move to a new round. It
+// carries the validator's signed ROUND CHANGE REQUEST message, and the proposal
+// the request's prepared certificate refers to, if any. Round change certificates
+// only hold the requests, so the proposal travels once, with the PRE-PREPARE
+// message re-proposing it.
+type RoundChange struct {
+	Request          []byte
+	PreparedProposal Proposal
+}
+
+// EncodeRLP serializes b into the Ethereum RLP format.
+func (b *RoundChange) EncodeRLP(w io.Writer) error {
+	if b.PreparedProposal == nil {
+		return rlp.Encode(w, []interface{}{b.Request})
+	}
+	return rlp.Encode(w, []interface{}{b.Request, b.PreparedProposal})
+}
+
+// DecodeRLP implements rlp.Decoder, and load the consensus fields from a RLP stream.
+func (b *RoundChange) DecodeRLP(s *rlp.Stream) error {
+	var roundChange struct {
+		Request          []byte
+		PreparedProposal []*types.Block `rlp:"tail"`
+	}
 
+	if err := s.Decode(&roundChange); err != nil {
+		return err
+	}
+	b.Request, b.PreparedProposal = roundChange.Request, nil
+	switch len(roundChange.PreparedProposal) {
+	case 0:
+	case 1:
+		b.PreparedProposal = roundChange.PreparedProposal[0]
+	default:
+		return errors.New("round change holds more than one proposal")
+	}
 	return nil
 }
 
+// RoundChangeCertificate justifies the proposal of a round after the first, by
+// the signed ROUND CHANGE REQUEST messages to the round of more than two thirds
+// of the validators.
+type RoundChangeCertificate struct {
+	RoundChangeRequests [][]byte
+}
+
+// IsEmpty returns whether the certificate holds no requests.
+func (rcc *RoundChangeCertificate) IsEmpty() bool {
+	return len(rcc.RoundChangeRequests) == 0
+}
+
 type Subject struct {
 	View   *View
 	Digest common.Hash
@@ -143,3 +218,21 @@ func (b *Subject) DecodeRLP(s *rlp.Stream) error {
 func (b *Subject) String() string {
 	return fmt.Sprintf("{View: %v, Digest: %v}", b.View, b.Digest.String())
 }
+
+// Equivocation is the evidence of a validator signing two conflicting messages
+// of the same code for the same view: PRE-PREPARE messages for different
+// proposals, or PREPARE or COMMIT messages for different digests. The messages
+// are kept with their signatures, so that the evidence can be verified without
+// any other state.
+type Equivocation struct {
+	Signer   common.Address
+	Code     uint64
+	View     *View
+	Messages [][]byte
+}
+
+// Hash returns the hash identifying the evidence. A validator is only accused
+// once per message code and view.
+func (e *Equivocation) Hash() common.Hash {
+	return RLPHash([]interface{}{e.Signer, e.Code, e.View})
+}
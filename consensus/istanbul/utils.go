@@ -81,12 +81,12 @@ func IsLastBlockOfEpoch(number uint64, epochSize uint64) bool {
 
 // Retrieves the epoch number given the block number.
 // There is a special case if the number == 0 (the genesis block).  That block will be in the
-// 1st epoch.
+// 0th epoch.  The last block of an epoch belongs to that epoch, see GetEpochLastBlockNumber.
 func GetEpochNumber(number uint64, epochSize uint64) uint64 {
 	if number == 0 {
 		return 0
 	} else {
-		return (number / epochSize) + 1
+		return ((number - 1) / epochSize) + 1
 	}
 }
 
@@ -121,3 +121,43 @@ func TestValSetDiff(t *testing.T) {
 	}
 
 }
+
+func TestGetEpochNumber(t *testing.T) {
+	tests := []struct {
+		number        uint64
+		epochSize     uint64
+		expectedEpoch uint64
+	}{
+		{0, 10, 0},
+		{1, 10, 1},
+		{9, 10, 1},
+		{10, 10, 1},
+		{11, 10, 2},
+		{19, 10, 2},
+		{20, 10, 2},
+		{21, 10, 3},
+		{0, 1, 0},
+		{1, 1, 1},
+		{2, 1, 2},
+	}
+
+	for _, tt := range tests {
+		epoch := GetEpochNumber(tt.number, tt.epochSize)
+		if epoch != tt.expectedEpoch {
+			t.Errorf("epoch mismatch for block %d of epoch size %d: have %d, want %d", tt.number, tt.epochSize, epoch, tt.expectedEpoch)
+		}
+		if tt.number == 0 {
+			continue
+		}
+		// The block lies within the bounds of its epoch, and closes it if it is
+		// the last block of an epoch
+		first, _ := GetEpochFirstBlockNumber(epoch, tt.epochSize)
+		last := GetEpochLastBlockNumber(epoch, tt.epochSize)
+		if tt.number < first || tt.number > last {
+			t.Errorf("block %d outside of its epoch %d: %d-%d", tt.number, epoch, first, last)
+		}
+		if IsLastBlockOfEpoch(tt.number, tt.epochSize) != (tt.number == last) {
+			t.Errorf("block %d last of epoch mismatch: have %v, want %v", tt.number, !(tt.number == last), tt.number == last)
+		}
+	}
+}
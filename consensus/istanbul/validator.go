@@ -17,6 +17,7 @@
 package istanbul
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,6 +27,14 @@ type Validator interface {
 	// Address returns address
 	Address() common.Address
 
+	// BLSPublicKey returns the public key the validator signs committed seals
+	// with, if it registered one
+	BLSPublicKey() []byte
+
+	// Stake returns the validator's bonded stake at the start of the epoch, or
+	// nil if it is unknown
+	Stake() *big.Int
+
 	// String representation of Validator
 	String() string
 }
@@ -67,6 +76,14 @@ type ValidatorSet interface {
 	AddValidators(address []common.Address) bool
 	// Remove validators
 	RemoveValidators(address []common.Address) bool
+	// Set the BLS public keys of the given validators
+	SetBLSPublicKeys(address []common.Address, publicKeys [][]byte) bool
+	// Set the bonded stakes of the given validators
+	SetStakes(address []common.Address, stakes []*big.Int) bool
+	// Get the on-chain randomness proposer selection is seeded with
+	Randomness() common.Hash
+	// Set the on-chain randomness proposer selection is seeded with
+	SetRandomness(randomness common.Hash)
 	// Copy validator set
 	Copy() ValidatorSet
 	// Get the maximum number of faulty nodes
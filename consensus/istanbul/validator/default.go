@@ -17,23 +17,36 @@
 package validator
 
 import (
+	"encoding/binary"
 	"math"
+	"math/big"
 	"reflect"
 	"sort"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type defaultValidator struct {
-	address common.Address
+	address      common.Address
+	blsPublicKey []byte
+	stake        *big.Int
 }
 
 func (val *defaultValidator) Address() common.Address {
 	return val.address
 }
 
+func (val *defaultValidator) BLSPublicKey() []byte {
+	return val.blsPublicKey
+}
+
+func (val *defaultValidator) Stake() *big.Int {
+	return val.stake
+}
+
 func (val *defaultValidator) String() string {
 	return val.Address().String()
 }
@@ -47,6 +60,7 @@ type defaultSet struct {
 	proposer    istanbul.Validator
 	validatorMu sync.RWMutex
 	selector    istanbul.ProposalSelector
+	randomness  common.Hash
 }
 
 func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy) *defaultSet {
@@ -64,9 +78,15 @@ func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy) *defa
 	if valSet.Size() > 0 {
 		valSet.proposer = valSet.GetByIndex(0)
 	}
-	valSet.selector = roundRobinProposer
-	if policy == istanbul.Sticky {
+	switch policy {
+	case istanbul.Sticky:
 		valSet.selector = stickyProposer
+	case istanbul.StakeWeighted:
+		valSet.selector = stakeWeightedProposer
+	case istanbul.ShuffledRoundRobin:
+		valSet.selector = shuffledRoundRobinProposer
+	default:
+		valSet.selector = roundRobinProposer
 	}
 
 	return valSet
@@ -157,6 +177,71 @@ func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, round
 	return valSet.GetByIndex(pick)
 }
 
+// stakeWeightedProposer draws the proposer with a probability proportional to
+// its stake. The draw is seeded with the on-chain randomness, or with the last
+// proposer if there is none yet, and the round. It falls back to round robin
+// while no validator has stake.
+func stakeWeightedProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+	if valSet.Size() == 0 {
+		return nil
+	}
+	total := new(big.Int)
+	for _, val := range valSet.List() {
+		if stake := val.Stake(); stake != nil && stake.Sign() > 0 {
+			total.Add(total, stake)
+		}
+	}
+	if total.Sign() == 0 {
+		return roundRobinProposer(valSet, proposer, round)
+	}
+	seed := valSet.Randomness()
+	if seed == (common.Hash{}) {
+		seed = common.BytesToHash(proposer.Bytes())
+	}
+	pick := new(big.Int).SetBytes(crypto.Keccak256(seed[:], roundBytes(round)))
+	pick.Mod(pick, total)
+	for _, val := range valSet.List() {
+		if stake := val.Stake(); stake != nil && stake.Sign() > 0 {
+			if pick.Cmp(stake) < 0 {
+				return val
+			}
+			pick.Sub(pick, stake)
+		}
+	}
+	return nil
+}
+
+// shuffledRoundRobinProposer lets the validators take turns over the rounds in
+// an order shuffled with the on-chain randomness. The randomness is revealed by
+// the proposer of the last block, so the order cannot be predicted before that
+// block is final. It falls back to round robin while there is no randomness.
+func shuffledRoundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+	if valSet.Size() == 0 {
+		return nil
+	}
+	seed := valSet.Randomness()
+	if seed == (common.Hash{}) {
+		return roundRobinProposer(valSet, proposer, round)
+	}
+	// Fisher-Yates shuffle of the validator indices, drawing from keccak(seed, i)
+	order := make([]int, valSet.Size())
+	for i := range order {
+		order[i] = i
+	}
+	for i := len(order) - 1; i > 0; i-- {
+		draw := new(big.Int).SetBytes(crypto.Keccak256(seed[:], roundBytes(uint64(i))))
+		j := int(draw.Mod(draw, big.NewInt(int64(i+1))).Int64())
+		order[i], order[j] = order[j], order[i]
+	}
+	return valSet.GetByIndex(uint64(order[round%uint64(len(order))]))
+}
+
+func roundBytes(round uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], round)
+	return b[:]
+}
+
 func (valSet *defaultSet) AddValidators(addresses []common.Address) bool {
 	newValidators := make([]istanbul.Validator, 0, len(addresses))
 	newAddressesMap := make(map[common.Address]bool)
@@ -217,15 +302,83 @@ func (valSet *defaultSet) RemoveValidators(addresses []common.Address) bool {
 	}
 }
 
+func (valSet *defaultSet) SetBLSPublicKeys(addresses []common.Address, publicKeys [][]byte) bool {
+	if len(addresses) != len(publicKeys) {
+		return false
+	}
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+
+	validators := make(map[common.Address]*defaultValidator)
+	for _, v := range valSet.validators {
+		validators[v.Address()] = v.(*defaultValidator)
+	}
+	for _, address := range addresses {
+		if _, ok := validators[address]; !ok {
+			return false
+		}
+	}
+	for i, address := range addresses {
+		validators[address].blsPublicKey = common.CopyBytes(publicKeys[i])
+	}
+	return true
+}
+
+func (valSet *defaultSet) SetStakes(addresses []common.Address, stakes []*big.Int) bool {
+	if len(addresses) != len(stakes) {
+		return false
+	}
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+
+	validators := make(map[common.Address]*defaultValidator)
+	for _, v := range valSet.validators {
+		validators[v.Address()] = v.(*defaultValidator)
+	}
+	for _, address := range addresses {
+		if _, ok := validators[address]; !ok {
+			return false
+		}
+	}
+	for i, address := range addresses {
+		if stakes[i] == nil {
+			validators[address].stake = nil
+		} else {
+			validators[address].stake = new(big.Int).Set(stakes[i])
+		}
+	}
+	return true
+}
+
+func (valSet *defaultSet) Randomness() common.Hash {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	return valSet.randomness
+}
+
+func (valSet *defaultSet) SetRandomness(randomness common.Hash) {
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+	valSet.randomness = randomness
+}
+
 func (valSet *defaultSet) Copy() istanbul.ValidatorSet {
 	valSet.validatorMu.RLock()
 	defer valSet.validatorMu.RUnlock()
 
 	addresses := make([]common.Address, 0, len(valSet.validators))
+	publicKeys := make([][]byte, 0, len(valSet.validators))
+	stakes := make([]*big.Int, 0, len(valSet.validators))
 	for _, v := range valSet.validators {
 		addresses = append(addresses, v.Address())
+		publicKeys = append(publicKeys, v.BLSPublicKey())
+		stakes = append(stakes, v.Stake())
 	}
-	return NewSet(addresses, valSet.policy)
+	newValSet := NewSet(addresses, valSet.policy)
+	newValSet.SetBLSPublicKeys(addresses, publicKeys)
+	newValSet.SetStakes(addresses, stakes)
+	newValSet.SetRandomness(valSet.randomness)
+	return newValSet
 }
 
 func (valSet *defaultSet) F() int { return int(math.Ceil(float64(valSet.Size())/3)) - 1 }
@@ -17,6 +17,7 @@
 package validator
 
 import (
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
@@ -36,6 +37,8 @@ func TestValidatorSet(t *testing.T) {
 	testNormalValSet(t)
 	testEmptyValSet(t)
 	testStickyProposer(t)
+	testStakeWeightedProposer(t)
+	testShuffledRoundRobinProposer(t)
 	testAddAndRemoveValidator(t)
 }
 
@@ -205,3 +208,80 @@ func testStickyProposer(t *testing.T) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 }
+
+func testStakeWeightedProposer(t *testing.T) {
+	addr1 := common.BytesToAddress(common.Hex2Bytes(testAddress))
+	addr2 := common.BytesToAddress(common.Hex2Bytes(testAddress2))
+	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.StakeWeighted)
+
+	// test round robin without stakes
+	valSet.CalcProposer(addr1, uint64(0))
+	if val := valSet.GetProposer(); val.Address() != addr2 {
+		t.Errorf("proposer mismatch: have %v, want %v", val.Address().Hex(), addr2.Hex())
+	}
+	// test the only validator with stake is always picked
+	valSet.SetStakes([]common.Address{addr1, addr2}, []*big.Int{big.NewInt(0), big.NewInt(100)})
+	for round := uint64(0); round < 10; round++ {
+		valSet.CalcProposer(addr2, round)
+		if val := valSet.GetProposer(); val.Address() != addr2 {
+			t.Errorf("proposer mismatch in round %d: have %v, want %v", round, val.Address().Hex(), addr2.Hex())
+		}
+	}
+	// test proposers are drawn proportionally to their stakes
+	valSet.SetStakes([]common.Address{addr1, addr2}, []*big.Int{big.NewInt(100), big.NewInt(300)})
+	valSet.SetRandomness(common.HexToHash("0x5eed"))
+	picked := 0
+	for round := uint64(0); round < 2000; round++ {
+		valSet.CalcProposer(addr1, round)
+		if valSet.GetProposer().Address() == addr2 {
+			picked++
+		}
+	}
+	if picked < 1400 || picked > 1600 {
+		t.Errorf("validator with three quarters of the stake proposed %d of 2000 rounds", picked)
+	}
+	// test copies keep the stakes
+	if stake := valSet.Copy().List()[1].Stake(); stake == nil || stake.Cmp(big.NewInt(300)) != 0 {
+		t.Errorf("stake mismatch: have %v, want %v", stake, 300)
+	}
+}
+
+func testShuffledRoundRobinProposer(t *testing.T) {
+	addrs := make([]common.Address, 8)
+	for i := range addrs {
+		addrs[i] = common.BytesToAddress([]byte{byte(i + 1)})
+	}
+	valSet := newDefaultSet(addrs, istanbul.ShuffledRoundRobin)
+
+	// test round robin without randomness
+	valSet.CalcProposer(addrs[0], uint64(0))
+	if val := valSet.GetProposer(); val.Address() != addrs[1] {
+		t.Errorf("proposer mismatch: have %v, want %v", val.Address().Hex(), addrs[1].Hex())
+	}
+	// test every validator proposes once in a shuffled order
+	order := func(randomness common.Hash) []common.Address {
+		valSet.SetRandomness(randomness)
+		var proposers []common.Address
+		seen := make(map[common.Address]bool)
+		for round := uint64(0); round < uint64(len(addrs)); round++ {
+			valSet.CalcProposer(addrs[0], round)
+			proposer := valSet.GetProposer().Address()
+			if seen[proposer] {
+				t.Errorf("validator %v proposes twice", proposer.Hex())
+			}
+			seen[proposer] = true
+			proposers = append(proposers, proposer)
+		}
+		return proposers
+	}
+	order1, order2 := order(common.HexToHash("0x01")), order(common.HexToHash("0x02"))
+	if reflect.DeepEqual(order1, addrs) {
+		t.Errorf("proposer order not shuffled")
+	}
+	if reflect.DeepEqual(order1, order2) {
+		t.Errorf("proposer order independent of randomness")
+	}
+	if !reflect.DeepEqual(order(common.HexToHash("0x01")), order1) {
+		t.Errorf("proposer order not deterministic")
+	}
+}
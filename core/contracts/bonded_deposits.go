@@ -0,0 +1,64 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// BondedDepositsABI is the input ABI used to generate the binding from.
+const BondedDepositsABI = "[{\"constant\":false,\"inputs\":[{\"name\":\"blockReward\",\"type\":\"uint256\"}],\"name\":\"setCumulativeRewardWeight\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getAccountWeight\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// parsedBondedDepositsABI is BondedDepositsABI parsed once, and shared by all the BondedDeposits bindings.
+var parsedBondedDepositsABI, parsedBondedDepositsABIErr = abi.JSON(strings.NewReader(BondedDepositsABI))
+
+// BondedDeposits is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type BondedDeposits struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewBondedDeposits creates a new instance of BondedDeposits, bound to a specific deployed contract.
+func NewBondedDeposits(address common.Address, handler bind.InternalEVMHandler) (*BondedDeposits, error) {
+	if parsedBondedDepositsABIErr != nil {
+		return nil, parsedBondedDepositsABIErr
+	}
+	return &BondedDeposits{contract: bind.NewBoundInternalContract(address, parsedBondedDepositsABI, handler)}, nil
+}
+
+// Address returns the address the BondedDeposits binding is bound to.
+func (_BondedDeposits *BondedDeposits) Address() common.Address {
+	return _BondedDeposits.contract.Address()
+}
+
+// GetAccountWeight is a free data retrieval call binding the contract method 0x3ea01b34.
+//
+// Solidity: function getAccountWeight(address account) constant returns(uint256)
+func (_BondedDeposits *BondedDeposits) GetAccountWeight(opts *bind.InternalCallOpts, account common.Address) (*big.Int, error) {
+	ret0 := new(*big.Int)
+	_, err := _BondedDeposits.contract.Call(opts, ret0, "getAccountWeight", account)
+	return *ret0, err
+}
+
+// SetCumulativeRewardWeight is a state modifying call binding the contract method 0x8213639a.
+//
+// Solidity: function setCumulativeRewardWeight(uint256 blockReward) returns()
+func (_BondedDeposits *BondedDeposits) SetCumulativeRewardWeight(opts *bind.InternalCallOpts, blockReward *big.Int) error {
+	_, err := _BondedDeposits.contract.Transact(opts, nil, "setCumulativeRewardWeight", blockReward)
+	return err
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package contracts
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testEVMHandler is a mock internal EVM handler that records the calls made and
+// answers them with canned return values.
+type testEVMHandler struct {
+	returns map[string][]interface{} // Values returned by each method
+
+	address common.Address
+	method  string
+	args    []interface{}
+	gas     uint64
+	value   *big.Int
+	static  bool
+}
+
+func (h *testEVMHandler) MakeStaticCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state *state.StateDB) (uint64, error) {
+	h.static = true
+	return h.call(scAddress, abi, funcName, args, returnObj, gas, nil)
+}
+
+func (h *testEVMHandler) MakeCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int, header *types.Header, state *state.StateDB) (uint64, error) {
+	h.static = false
+	return h.call(scAddress, abi, funcName, args, returnObj, gas, value)
+}
+
+func (h *testEVMHandler) call(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int) (uint64, error) {
+	h.address, h.method, h.args, h.gas, h.value = scAddress, funcName, args, gas, value
+
+	// Make sure the arguments match the ABI of the method
+	if _, err := abi.Pack(funcName, args...); err != nil {
+		return 0, err
+	}
+	output, err := abi.Methods[funcName].Outputs.Pack(h.returns[funcName]...)
+	if err != nil {
+		return 0, err
+	}
+	if returnObj == nil {
+		return gas, nil
+	}
+	return gas, abi.Unpack(returnObj, funcName, output)
+}
+
+// Tests that read only calls with a single return value are routed and unpacked.
+func TestInternalCall(t *testing.T) {
+	address := common.HexToAddress("0x000000000000000000000000000000000000ce10")
+	expected := common.HexToAddress("0xc0ffee")
+
+	handler := &testEVMHandler{returns: map[string][]interface{}{"getAddressFor": {expected}}}
+	registry, err := NewRegistry(address, handler)
+	if err != nil {
+		t.Fatalf("failed to bind registry: %v", err)
+	}
+	have, err := registry.GetAddressFor(&bind.InternalCallOpts{Gas: 20000}, "Random")
+	if err != nil {
+		t.Fatalf("failed to call getAddressFor: %v", err)
+	}
+	if have != expected {
+		t.Errorf("address mismatch: have %x, want %x", have, expected)
+	}
+	if !handler.static || handler.address != address || handler.method != "getAddressFor" || handler.gas != 20000 {
+		t.Errorf("call mismatch: static %v, address %x, method %s, gas %d", handler.static, handler.address, handler.method, handler.gas)
+	}
+	if !reflect.DeepEqual(handler.args, []interface{}{"Random"}) {
+		t.Errorf("arguments mismatch: have %v, want %v", handler.args, []interface{}{"Random"})
+	}
+}
+
+// Tests that read only calls with multiple return values are unpacked in order.
+func TestInternalCallMultipleReturns(t *testing.T) {
+	handler := &testEVMHandler{returns: map[string][]interface{}{"medianRate": {big.NewInt(3), big.NewInt(7)}}}
+	sortedOracles, err := NewSortedOracles(common.HexToAddress("0x01"), handler)
+	if err != nil {
+		t.Fatalf("failed to bind sorted oracles: %v", err)
+	}
+	numerator, denominator, err := sortedOracles.MedianRate(&bind.InternalCallOpts{Gas: 20000}, common.HexToAddress("0x02"))
+	if err != nil {
+		t.Fatalf("failed to call medianRate: %v", err)
+	}
+	if numerator.Cmp(big.NewInt(3)) != 0 || denominator.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("rate mismatch: have %v/%v, want 3/7", numerator, denominator)
+	}
+}
+
+// Tests that state modifying calls are made through MakeCall with the requested
+// value, defaulting to zero.
+func TestInternalTransact(t *testing.T) {
+	handler := &testEVMHandler{returns: map[string][]interface{}{"updateGasPriceMinimum": {big.NewInt(42)}, "increaseSupply": {}}}
+
+	gasPriceMinimum, err := NewGasPriceMinimum(common.HexToAddress("0x01"), handler)
+	if err != nil {
+		t.Fatalf("failed to bind gas price minimum: %v", err)
+	}
+	updated, err := gasPriceMinimum.UpdateGasPriceMinimum(&bind.InternalCallOpts{Gas: 100000, Value: big.NewInt(5)}, big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatalf("failed to call updateGasPriceMinimum: %v", err)
+	}
+	if updated.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("updated gas price minimum mismatch: have %v, want %v", updated, 42)
+	}
+	if handler.static || handler.value.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("transaction mismatch: static %v, value %v", handler.static, handler.value)
+	}
+
+	goldToken, err := NewGoldToken(common.HexToAddress("0x02"), handler)
+	if err != nil {
+		t.Fatalf("failed to bind gold token: %v", err)
+	}
+	if err := goldToken.IncreaseSupply(&bind.InternalCallOpts{Gas: 100000}, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to call increaseSupply: %v", err)
+	}
+	if handler.value == nil || handler.value.Sign() != 0 {
+		t.Errorf("default value mismatch: have %v, want 0", handler.value)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package contracts contains typed Go bindings of the Celo system contracts, operated
+// through the node's own EVM (see core.InternalEVMHandler).
+//
+// The ABIs in the abis directory are the subsets of the
+// celo-monorepo/packages/protocol/build/<env>/contracts/<Contract>.json artifacts
+// that the node relies on. Regenerate the bindings with `go generate` after
+// updating them.
+package contracts
+
+//go:generate abigen --abi abis/BondedDeposits.json --pkg contracts --type BondedDeposits --lang go-internal --out bonded_deposits.go
+//go:generate abigen --abi abis/ERC20.json --pkg contracts --type ERC20 --lang go-internal --out erc20.go
+//go:generate abigen --abi abis/GasCurrencyWhitelist.json --pkg contracts --type GasCurrencyWhitelist --lang go-internal --out gas_currency_whitelist.go
+//go:generate abigen --abi abis/GasPriceMinimum.json --pkg contracts --type GasPriceMinimum --lang go-internal --out gasprice_minimum.go
+//go:generate abigen --abi abis/GoldToken.json --pkg contracts --type GoldToken --lang go-internal --out gold_token.go
+//go:generate abigen --abi abis/Random.json --pkg contracts --type Random --lang go-internal --out random.go
+//go:generate abigen --abi abis/Registry.json --pkg contracts --type Registry --lang go-internal --out registry.go
+//go:generate abigen --abi abis/SortedOracles.json --pkg contracts --type SortedOracles --lang go-internal --out sorted_oracles.go
+//go:generate abigen --abi abis/Validators.json --pkg contracts --type Validators --lang go-internal --out validators.go
@@ -0,0 +1,56 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// ERC20ABI is the input ABI used to generate the binding from.
+const ERC20ABI = "[{\"constant\":true,\"inputs\":[{\"name\":\"who\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// parsedERC20ABI is ERC20ABI parsed once, and shared by all the ERC20 bindings.
+var parsedERC20ABI, parsedERC20ABIErr = abi.JSON(strings.NewReader(ERC20ABI))
+
+// ERC20 is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type ERC20 struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewERC20 creates a new instance of ERC20, bound to a specific deployed contract.
+func NewERC20(address common.Address, handler bind.InternalEVMHandler) (*ERC20, error) {
+	if parsedERC20ABIErr != nil {
+		return nil, parsedERC20ABIErr
+	}
+	return &ERC20{contract: bind.NewBoundInternalContract(address, parsedERC20ABI, handler)}, nil
+}
+
+// Address returns the address the ERC20 binding is bound to.
+func (_ERC20 *ERC20) Address() common.Address {
+	return _ERC20.contract.Address()
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address who) constant returns(uint256)
+func (_ERC20 *ERC20) BalanceOf(opts *bind.InternalCallOpts, who common.Address) (*big.Int, error) {
+	ret0 := new(*big.Int)
+	_, err := _ERC20.contract.Call(opts, ret0, "balanceOf", who)
+	return *ret0, err
+}
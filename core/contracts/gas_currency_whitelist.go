@@ -0,0 +1,56 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// GasCurrencyWhitelistABI is the input ABI used to generate the binding from.
+const GasCurrencyWhitelistABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"getWhitelist\",\"outputs\":[{\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// parsedGasCurrencyWhitelistABI is GasCurrencyWhitelistABI parsed once, and shared by all the GasCurrencyWhitelist bindings.
+var parsedGasCurrencyWhitelistABI, parsedGasCurrencyWhitelistABIErr = abi.JSON(strings.NewReader(GasCurrencyWhitelistABI))
+
+// GasCurrencyWhitelist is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type GasCurrencyWhitelist struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewGasCurrencyWhitelist creates a new instance of GasCurrencyWhitelist, bound to a specific deployed contract.
+func NewGasCurrencyWhitelist(address common.Address, handler bind.InternalEVMHandler) (*GasCurrencyWhitelist, error) {
+	if parsedGasCurrencyWhitelistABIErr != nil {
+		return nil, parsedGasCurrencyWhitelistABIErr
+	}
+	return &GasCurrencyWhitelist{contract: bind.NewBoundInternalContract(address, parsedGasCurrencyWhitelistABI, handler)}, nil
+}
+
+// Address returns the address the GasCurrencyWhitelist binding is bound to.
+func (_GasCurrencyWhitelist *GasCurrencyWhitelist) Address() common.Address {
+	return _GasCurrencyWhitelist.contract.Address()
+}
+
+// GetWhitelist is a free data retrieval call binding the contract method 0xd01f63f5.
+//
+// Solidity: function getWhitelist() constant returns(address[])
+func (_GasCurrencyWhitelist *GasCurrencyWhitelist) GetWhitelist(opts *bind.InternalCallOpts) ([]common.Address, error) {
+	ret0 := new([]common.Address)
+	_, err := _GasCurrencyWhitelist.contract.Call(opts, ret0, "getWhitelist")
+	return *ret0, err
+}
@@ -0,0 +1,73 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// GoldTokenABI is the input ABI used to generate the binding from.
+const GoldTokenABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"owner\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"increaseSupply\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// parsedGoldTokenABI is GoldTokenABI parsed once, and shared by all the GoldToken bindings.
+var parsedGoldTokenABI, parsedGoldTokenABIErr = abi.JSON(strings.NewReader(GoldTokenABI))
+
+// GoldToken is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type GoldToken struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewGoldToken creates a new instance of GoldToken, bound to a specific deployed contract.
+func NewGoldToken(address common.Address, handler bind.InternalEVMHandler) (*GoldToken, error) {
+	if parsedGoldTokenABIErr != nil {
+		return nil, parsedGoldTokenABIErr
+	}
+	return &GoldToken{contract: bind.NewBoundInternalContract(address, parsedGoldTokenABI, handler)}, nil
+}
+
+// Address returns the address the GoldToken binding is bound to.
+func (_GoldToken *GoldToken) Address() common.Address {
+	return _GoldToken.contract.Address()
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address owner) constant returns(uint256)
+func (_GoldToken *GoldToken) BalanceOf(opts *bind.InternalCallOpts, owner common.Address) (*big.Int, error) {
+	ret0 := new(*big.Int)
+	_, err := _GoldToken.contract.Call(opts, ret0, "balanceOf", owner)
+	return *ret0, err
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() constant returns(uint256)
+func (_GoldToken *GoldToken) TotalSupply(opts *bind.InternalCallOpts) (*big.Int, error) {
+	ret0 := new(*big.Int)
+	_, err := _GoldToken.contract.Call(opts, ret0, "totalSupply")
+	return *ret0, err
+}
+
+// IncreaseSupply is a state modifying call binding the contract method 0xb921e163.
+//
+// Solidity: function increaseSupply(uint256 amount) returns()
+func (_GoldToken *GoldToken) IncreaseSupply(opts *bind.InternalCallOpts, amount *big.Int) error {
+	_, err := _GoldToken.contract.Transact(opts, nil, "increaseSupply", amount)
+	return err
+}
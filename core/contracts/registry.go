@@ -0,0 +1,64 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// RegistryABI is the input ABI used to generate the binding from.
+const RegistryABI = "[{\"constant\":true,\"inputs\":[{\"name\":\"identifier\",\"type\":\"string\"}],\"name\":\"getAddressFor\",\"outputs\":[{\"name\":\"\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"identifier\",\"type\":\"string\"},{\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAddressFor\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// parsedRegistryABI is RegistryABI parsed once, and shared by all the Registry bindings.
+var parsedRegistryABI, parsedRegistryABIErr = abi.JSON(strings.NewReader(RegistryABI))
+
+// Registry is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type Registry struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewRegistry creates a new instance of Registry, bound to a specific deployed contract.
+func NewRegistry(address common.Address, handler bind.InternalEVMHandler) (*Registry, error) {
+	if parsedRegistryABIErr != nil {
+		return nil, parsedRegistryABIErr
+	}
+	return &Registry{contract: bind.NewBoundInternalContract(address, parsedRegistryABI, handler)}, nil
+}
+
+// Address returns the address the Registry binding is bound to.
+func (_Registry *Registry) Address() common.Address {
+	return _Registry.contract.Address()
+}
+
+// GetAddressFor is a free data retrieval call binding the contract method 0x0b5855e1.
+//
+// Solidity: function getAddressFor(string identifier) constant returns(address)
+func (_Registry *Registry) GetAddressFor(opts *bind.InternalCallOpts, identifier string) (common.Address, error) {
+	ret0 := new(common.Address)
+	_, err := _Registry.contract.Call(opts, ret0, "getAddressFor", identifier)
+	return *ret0, err
+}
+
+// SetAddressFor is a state modifying call binding the contract method 0xc5865793.
+//
+// Solidity: function setAddressFor(string identifier, address addr) returns()
+func (_Registry *Registry) SetAddressFor(opts *bind.InternalCallOpts, identifier string, addr common.Address) error {
+	_, err := _Registry.contract.Transact(opts, nil, "setAddressFor", identifier, addr)
+	return err
+}
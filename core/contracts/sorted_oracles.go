@@ -0,0 +1,57 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// SortedOraclesABI is the input ABI used to generate the binding from.
+const SortedOraclesABI = "[{\"constant\":true,\"inputs\":[{\"name\":\"token\",\"type\":\"address\"}],\"name\":\"medianRate\",\"outputs\":[{\"name\":\"\",\"type\":\"uint128\"},{\"name\":\"\",\"type\":\"uint128\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// parsedSortedOraclesABI is SortedOraclesABI parsed once, and shared by all the SortedOracles bindings.
+var parsedSortedOraclesABI, parsedSortedOraclesABIErr = abi.JSON(strings.NewReader(SortedOraclesABI))
+
+// SortedOracles is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type SortedOracles struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewSortedOracles creates a new instance of SortedOracles, bound to a specific deployed contract.
+func NewSortedOracles(address common.Address, handler bind.InternalEVMHandler) (*SortedOracles, error) {
+	if parsedSortedOraclesABIErr != nil {
+		return nil, parsedSortedOraclesABIErr
+	}
+	return &SortedOracles{contract: bind.NewBoundInternalContract(address, parsedSortedOraclesABI, handler)}, nil
+}
+
+// Address returns the address the SortedOracles binding is bound to.
+func (_SortedOracles *SortedOracles) Address() common.Address {
+	return _SortedOracles.contract.Address()
+}
+
+// MedianRate is a free data retrieval call binding the contract method 0xef90e1b0.
+//
+// Solidity: function medianRate(address token) constant returns(uint128, uint128)
+func (_SortedOracles *SortedOracles) MedianRate(opts *bind.InternalCallOpts, token common.Address) (*big.Int, *big.Int, error) {
+	ret0 := new(*big.Int)
+	ret1 := new(*big.Int)
+	_, err := _SortedOracles.contract.Call(opts, &[]interface{}{ret0, ret1}, "medianRate", token)
+	return *ret0, *ret1, err
+}
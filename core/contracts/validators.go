@@ -0,0 +1,80 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+)
+
+// ValidatorsABI is the input ABI used to generate the binding from.
+const ValidatorsABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"getValidators\",\"outputs\":[{\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"getRegisteredValidators\",\"outputs\":[{\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"validator\",\"type\":\"address\"}],\"name\":\"getValidatorBlsKey\",\"outputs\":[{\"name\":\"publicKey\",\"type\":\"bytes\"},{\"name\":\"proofOfPossession\",\"type\":\"bytes\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// parsedValidatorsABI is ValidatorsABI parsed once, and shared by all the Validators bindings.
+var parsedValidatorsABI, parsedValidatorsABIErr = abi.JSON(strings.NewReader(ValidatorsABI))
+
+// Validators is an auto generated Go binding around an Ethereum contract,
+// operated through the node's own EVM.
+type Validators struct {
+	contract *bind.BoundInternalContract // Generic contract wrapper for the low level calls
+}
+
+// NewValidators creates a new instance of Validators, bound to a specific deployed contract.
+func NewValidators(address common.Address, handler bind.InternalEVMHandler) (*Validators, error) {
+	if parsedValidatorsABIErr != nil {
+		return nil, parsedValidatorsABIErr
+	}
+	return &Validators{contract: bind.NewBoundInternalContract(address, parsedValidatorsABI, handler)}, nil
+}
+
+// Address returns the address the Validators binding is bound to.
+func (_Validators *Validators) Address() common.Address {
+	return _Validators.contract.Address()
+}
+
+// GetRegisteredValidators is a free data retrieval call binding the contract method 0xd93ab5ad.
+//
+// Solidity: function getRegisteredValidators() constant returns(address[])
+func (_Validators *Validators) GetRegisteredValidators(opts *bind.InternalCallOpts) ([]common.Address, error) {
+	ret0 := new([]common.Address)
+	_, err := _Validators.contract.Call(opts, ret0, "getRegisteredValidators")
+	return *ret0, err
+}
+
+// GetValidatorBlsKey is a free data retrieval call binding the contract method 0xcc3406e5.
+//
+// Solidity: function getValidatorBlsKey(address validator) constant returns(bytes publicKey, bytes proofOfPossession)
+func (_Validators *Validators) GetValidatorBlsKey(opts *bind.InternalCallOpts, validator common.Address) (struct {
+	PublicKey         []byte
+	ProofOfPossession []byte
+}, error) {
+	ret := new(struct {
+		PublicKey         []byte
+		ProofOfPossession []byte
+	})
+	_, err := _Validators.contract.Call(opts, ret, "getValidatorBlsKey", validator)
+	return *ret, err
+}
+
+// GetValidators is a free data retrieval call binding the contract method 0xb7ab4db5.
+//
+// Solidity: function getValidators() constant returns(address[])
+func (_Validators *Validators) GetValidators(opts *bind.InternalCallOpts) ([]common.Address, error) {
+	ret0 := new([]common.Address)
+	_, err := _Validators.contract.Call(opts, ret0, "getValidators")
+	return *ret0, err
+}
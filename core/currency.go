@@ -19,90 +19,30 @@ package core
 import (
 	"errors"
 	"math/big"
-	"strings"
 	"sync"
-	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
 const (
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/SortedOracles.json
-	medianRateABI = `[
-    {
-      "constant": true,
-      "inputs": [
-        {
-          "name": "token",
-          "type": "address"
-        }
-      ],
-      "name": "medianRate",
-      "outputs": [
-        {
-          "name": "",
-          "type": "uint128"
-        },
-        {
-          "name": "",
-          "type": "uint128"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "view",
-      "type": "function"
-    }]`
-
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/ERC20.json
-	balanceOfABI = `[{"constant": true,
-                          "inputs": [
-                               {
-                                   "name": "who",
-                                   "type": "address"
-                               }
-                          ],
-                          "name": "balanceOf",
-                          "outputs": [
-                               {
-                                   "name": "",
-                                   "type": "uint256"
-                               }
-                          ],
-                          "payable": false,
-                          "stateMutability": "view",
-                          "type": "function"
-                         }]`
-
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/GasCurrency.json
-	getWhitelistABI = `[{"constant": true,
-	                     "inputs": [],
-	                     "name": "getWhitelist",
-	                     "outputs": [
-			          {
-			              "name": "",
-				      "type": "address[]"
-				  }
-			     ],
-			     "payable": false,
-			     "stateMutability": "view",
-			     "type": "function"
-			    }]`
+	// exchangeRateSnapshotWindow is the number of recent blocks for which the
+	// exchange rate snapshots are retained.
+	exchangeRateSnapshotWindow = 128
 )
 
 var (
 	cgExchangeRateNum = big.NewInt(1)
 	cgExchangeRateDen = big.NewInt(1)
 
-	medianRateFuncABI, _   = abi.JSON(strings.NewReader(medianRateABI))
-	balanceOfFuncABI, _    = abi.JSON(strings.NewReader(balanceOfABI))
-	getWhitelistFuncABI, _ = abi.JSON(strings.NewReader(getWhitelistABI))
-
 	errExchangeRateCacheMiss = errors.New("exchange rate cache miss")
 )
 
@@ -111,21 +51,41 @@ type exchangeRate struct {
 	Denominator *big.Int
 }
 
+// exchangeRateKey identifies the block, and the state of that block, an exchange
+// rate snapshot was computed from.
+type exchangeRateKey struct {
+	hash common.Hash
+	root common.Hash
+}
+
+// exchangeRateSnapshot holds the exchange rates of all whitelisted gas currencies
+// as reported by the SortedOracles contract at a specific block.
+type exchangeRateSnapshot struct {
+	number uint64
+	rates  map[common.Address]*exchangeRate // indexedCurrency:CeloGold exchange rate
+}
+
 type CurrencyOperator struct {
-	gcWl               *GasCurrencyWhitelist            // Object to retrieve the set of currencies that will have their exchange rate monitored
-	exchangeRates      map[common.Address]*exchangeRate // indexedCurrency:CeloGold exchange rate
+	gcWl               *GasCurrencyWhitelist // Object to retrieve the set of currencies that will have their exchange rate monitored
 	regAdd             *RegisteredAddresses
 	iEvmH              *InternalEVMHandler
+	chain              blockChain                                // Chain used to pin exchange rates to blocks, nil if rates are never computed
+	snapshots          map[exchangeRateKey]*exchangeRateSnapshot // Exchange rates of the recent blocks
+	head               *exchangeRateSnapshot                     // Exchange rates at the current chain head
 	currencyOperatorMu sync.RWMutex
+
+	chainHeadCh  chan ChainHeadEvent
+	chainHeadSub event.Subscription
 }
 
-func (co *CurrencyOperator) getExchangeRate(currency *common.Address) (*exchangeRate, error) {
+func (co *CurrencyOperator) getExchangeRate(currency *common.Address, snapshot *exchangeRateSnapshot) (*exchangeRate, error) {
 	if currency == nil {
 		return &exchangeRate{cgExchangeRateNum, cgExchangeRateDen}, nil
 	} else {
-		co.currencyOperatorMu.RLock()
-		defer co.currencyOperatorMu.RUnlock()
-		if exchangeRate, ok := co.exchangeRates[*currency]; !ok {
+		if snapshot == nil {
+			return nil, errExchangeRateCacheMiss
+		}
+		if exchangeRate, ok := snapshot.rates[*currency]; !ok {
 			return nil, errExchangeRateCacheMiss
 		} else {
 			return exchangeRate, nil
@@ -144,11 +104,22 @@ func (co *CurrencyOperator) ConvertToGold(val *big.Int, currencyFrom *common.Add
 	return co.Convert(val, currencyFrom, celoGoldAddress)
 }
 
+// Convert converts val between the two currencies using the exchange rates at the
+// current chain head.
+func (co *CurrencyOperator) Convert(val *big.Int, currencyFrom *common.Address, currencyTo *common.Address) (*big.Int, error) {
+	return co.ConvertAtHeader(val, currencyFrom, currencyTo, nil)
+}
+
+// ConvertAtHeader converts val between the two currencies using the exchange rates
+// computed from the state of the given header.  A nil header will use the current
+// chain head.
+//
 // NOTE (jarmg 4/24/18): values are rounded down which can cause
 // an estimate to be off by 1 (at most)
-func (co *CurrencyOperator) Convert(val *big.Int, currencyFrom *common.Address, currencyTo *common.Address) (*big.Int, error) {
-	exchangeRateFrom, err1 := co.getExchangeRate(currencyFrom)
-	exchangeRateTo, err2 := co.getExchangeRate(currencyTo)
+func (co *CurrencyOperator) ConvertAtHeader(val *big.Int, currencyFrom *common.Address, currencyTo *common.Address, header *types.Header) (*big.Int, error) {
+	snapshot := co.exchangeRatesAtHeader(header)
+	exchangeRateFrom, err1 := co.getExchangeRate(currencyFrom, snapshot)
+	exchangeRateTo, err2 := co.getExchangeRate(currencyTo, snapshot)
 
 	if err1 != nil || err2 != nil {
 		log.Error("CurrencyOperator.Convert - Error in retreiving currency exchange rates")
@@ -167,13 +138,23 @@ func (co *CurrencyOperator) Convert(val *big.Int, currencyFrom *common.Address,
 	return new(big.Int).Div(numerator, denominator), nil
 }
 
+// Cmp compares two values denominated in possibly different currencies using the
+// exchange rates at the current chain head.
 func (co *CurrencyOperator) Cmp(val1 *big.Int, currency1 *common.Address, val2 *big.Int, currency2 *common.Address) int {
+	return co.CmpAtHeader(val1, currency1, val2, currency2, nil)
+}
+
+// CmpAtHeader compares two values denominated in possibly different currencies using
+// the exchange rates computed from the state of the given header.  A nil header will
+// use the current chain head.
+func (co *CurrencyOperator) CmpAtHeader(val1 *big.Int, currency1 *common.Address, val2 *big.Int, currency2 *common.Address, header *types.Header) int {
 	if currency1 == currency2 {
 		return val1.Cmp(val2)
 	}
 
-	exchangeRate1, err1 := co.getExchangeRate(currency1)
-	exchangeRate2, err2 := co.getExchangeRate(currency2)
+	snapshot := co.exchangeRatesAtHeader(header)
+	exchangeRate1, err1 := co.getExchangeRate(currency1, snapshot)
+	exchangeRate2, err2 := co.getExchangeRate(currency2, snapshot)
 
 	if err1 != nil || err2 != nil {
 		currency1Output := "nil"
@@ -197,105 +178,215 @@ func (co *CurrencyOperator) Cmp(val1 *big.Int, currency1 *common.Address, val2 *
 	return leftSide.Cmp(rightSide)
 }
 
-// This function will retrieve the exchange rates from the SortedOracles contract and cache them.
+// exchangeRatesAtHeader returns the exchange rate snapshot for the given header,
+// computing it from the header's state if it is not cached yet.  A nil header
+// returns the snapshot of the current chain head.  Returns nil if the snapshot is
+// not available.
+func (co *CurrencyOperator) exchangeRatesAtHeader(header *types.Header) *exchangeRateSnapshot {
+	if header == nil {
+		co.currencyOperatorMu.RLock()
+		defer co.currencyOperatorMu.RUnlock()
+		return co.head
+	}
+
+	key := exchangeRateKey{hash: header.Hash(), root: header.Root}
+	co.currencyOperatorMu.RLock()
+	snapshot, ok := co.snapshots[key]
+	co.currencyOperatorMu.RUnlock()
+	if ok || co.chain == nil || co.gcWl == nil {
+		return snapshot
+	}
+
+	state, err := co.chain.StateAt(header.Root)
+	if err != nil {
+		log.Warn("Failed to retrieve state for exchange rates", "number", header.Number, "hash", header.Hash(), "err", err)
+		return nil
+	}
+	snapshot = co.computeExchangeRates(header, state)
+
+	co.currencyOperatorMu.Lock()
+	co.snapshots[key] = snapshot
+	co.currencyOperatorMu.Unlock()
+
+	return snapshot
+}
+
+// This function will retrieve the exchange rates from the SortedOracles contract at the given
+// header and state.
 // SortedOracles must have a function with the following signature:
 // "function medianRate(address)"
-func (co *CurrencyOperator) refreshExchangeRates() {
-	gasCurrencyAddresses := co.gcWl.Whitelist()
-	sortedOraclesAddress, err := co.regAdd.GetRegisteredAddressAtCurrentHeader(params.SortedOraclesRegistryId)
+func (co *CurrencyOperator) computeExchangeRates(header *types.Header, state *state.StateDB) *exchangeRateSnapshot {
+	snapshot := &exchangeRateSnapshot{
+		number: header.Number.Uint64(),
+		rates:  make(map[common.Address]*exchangeRate),
+	}
+
+	sortedOraclesAddress, err := co.regAdd.GetRegisteredAddressAtStateAndHeader(params.SortedOraclesRegistryId, state, header)
 
 	if err == ErrSmartContractNotDeployed {
 		log.Warn("Registry address lookup failed", "err", err)
-		return
+		return snapshot
 	} else if err != nil {
 		log.Error(err.Error())
 	}
 
-	celoGoldAddress, err := co.regAdd.GetRegisteredAddressAtCurrentHeader(params.GoldTokenRegistryId)
+	celoGoldAddress, err := co.regAdd.GetRegisteredAddressAtStateAndHeader(params.GoldTokenRegistryId, state, header)
 
 	if err == ErrSmartContractNotDeployed {
 		log.Warn("Registry address lookup failed", "err", err)
-		return
+		return snapshot
 	} else if err != nil {
 		log.Error(err.Error())
 	}
 
-	co.currencyOperatorMu.Lock()
+	// Celo Gold is always exchanged at par with itself
+	snapshot.rates[*celoGoldAddress] = &exchangeRate{cgExchangeRateNum, cgExchangeRateDen}
+
+	gasCurrencyAddresses, err := co.gcWl.retrieveWhitelist(state, header)
+	if err != nil {
+		log.Warn("Failed to get gas currency whitelist", "err", err)
+		return snapshot
+	}
+
+	sortedOracles, err := contracts.NewSortedOracles(*sortedOraclesAddress, co.iEvmH)
+	if err != nil {
+		log.Error("Failed to bind SortedOracles contract", "err", err)
+		return snapshot
+	}
+	opts := &bind.InternalCallOpts{Gas: 20000, Header: header, State: state}
 
 	for _, gasCurrencyAddress := range gasCurrencyAddresses {
 		if gasCurrencyAddress == *celoGoldAddress {
 			continue
 		}
 
-		var returnArray [2]*big.Int
-		if leftoverGas, err := co.iEvmH.MakeStaticCall(*sortedOraclesAddress, medianRateFuncABI, "medianRate", []interface{}{gasCurrencyAddress}, &returnArray, 20000, nil, nil); err != nil {
-			log.Error("medianRate invocation error", "gasCurrencyAddress", gasCurrencyAddress.Hex(), "leftoverGas", leftoverGas, "err", err)
+		if numerator, denominator, err := sortedOracles.MedianRate(opts, gasCurrencyAddress); err != nil {
+			log.Error("medianRate invocation error", "gasCurrencyAddress", gasCurrencyAddress.Hex(), "err", err)
 			continue
 		} else {
-			log.Trace("medianRate invocation success", "gasCurrencyAddress", gasCurrencyAddress, "returnArray", returnArray, "leftoverGas", leftoverGas)
+			log.Trace("medianRate invocation success", "gasCurrencyAddress", gasCurrencyAddress, "numerator", numerator, "denominator", denominator)
+			snapshot.rates[gasCurrencyAddress] = &exchangeRate{numerator, denominator}
+		}
+	}
 
-			if _, ok := co.exchangeRates[gasCurrencyAddress]; !ok {
-				co.exchangeRates[gasCurrencyAddress] = &exchangeRate{}
-			}
+	return snapshot
+}
 
-			co.exchangeRates[gasCurrencyAddress].Numerator = returnArray[0]
-			co.exchangeRates[gasCurrencyAddress].Denominator = returnArray[1]
-		}
+// setHead pins the exchange rates used by Convert and Cmp to the given chain head,
+// and drops the snapshots that have fallen out of the retention window.
+func (co *CurrencyOperator) setHead(header *types.Header) {
+	snapshot := co.exchangeRatesAtHeader(header)
+	if snapshot == nil {
+		return
 	}
 
-	co.currencyOperatorMu.Unlock()
+	co.currencyOperatorMu.Lock()
+	defer co.currencyOperatorMu.Unlock()
+
+	co.head = snapshot
+	for key, s := range co.snapshots {
+		if s.number+exchangeRateSnapshotWindow <= snapshot.number {
+			delete(co.snapshots, key)
+		}
+	}
 }
 
-// TODO (jarmg 5/30/18): Change this to cache based on block number
-func (co *CurrencyOperator) mainLoop() {
-	co.refreshExchangeRates()
-	ticker := time.NewTicker(10 * time.Second)
+// loop computes the exchange rates of every new chain head.
+func (co *CurrencyOperator) loop() {
+	co.setHead(co.chain.CurrentBlock().Header())
 
-	for range ticker.C {
-		co.refreshExchangeRates()
+	for {
+		select {
+		case ev := <-co.chainHeadCh:
+			if ev.Block != nil {
+				co.setHead(ev.Block.Header())
+			}
+		// Be unsubscribed due to system stopped
+		case <-co.chainHeadSub.Err():
+			return
+		}
 	}
 }
 
-func NewCurrencyOperator(gcWl *GasCurrencyWhitelist, regAdd *RegisteredAddresses, iEvmH *InternalEVMHandler) *CurrencyOperator {
-	exchangeRates := make(map[common.Address]*exchangeRate)
+// Stop terminates the tracking of new chain heads.
+func (co *CurrencyOperator) Stop() {
+	if co.chainHeadSub != nil {
+		co.chainHeadSub.Unsubscribe()
+	}
+}
 
+func NewCurrencyOperator(gcWl *GasCurrencyWhitelist, regAdd *RegisteredAddresses, iEvmH *InternalEVMHandler, chain blockChain) *CurrencyOperator {
 	co := &CurrencyOperator{
-		gcWl:          gcWl,
-		exchangeRates: exchangeRates,
-		regAdd:        regAdd,
-		iEvmH:         iEvmH,
+		gcWl:      gcWl,
+		regAdd:    regAdd,
+		iEvmH:     iEvmH,
+		chain:     chain,
+		snapshots: make(map[exchangeRateKey]*exchangeRateSnapshot),
 	}
 
-	if co.gcWl != nil {
-		go co.mainLoop()
+	if co.gcWl != nil && co.chain != nil {
+		co.chainHeadCh = make(chan ChainHeadEvent, chainHeadChanSize)
+		co.chainHeadSub = co.chain.SubscribeChainHeadEvent(co.chainHeadCh)
+		go co.loop()
 	}
 
 	return co
 }
 
+// vmEVMHandler operates the contract bindings on a given EVM, such as the one
+// applying a transaction, rather than on the state of a header.
+type vmEVMHandler struct {
+	evm *vm.EVM
+}
+
+func (h vmEVMHandler) MakeStaticCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state *state.StateDB) (uint64, error) {
+	return h.evm.ABIStaticCall(zeroCaller, scAddress, abi, funcName, args, returnObj, gas)
+}
+
+func (h vmEVMHandler) MakeCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, value *big.Int, header *types.Header, state *state.StateDB) (uint64, error) {
+	return h.evm.ABICall(zeroCaller, scAddress, abi, funcName, args, returnObj, gas, value)
+}
+
+// gasMeteredEVMHandler records the gas left over by the last read only call made
+// through the handler it wraps.
+type gasMeteredEVMHandler struct {
+	bind.InternalEVMHandler
+	leftoverGas uint64
+}
+
+func (h *gasMeteredEVMHandler) MakeStaticCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state *state.StateDB) (uint64, error) {
+	leftoverGas, err := h.InternalEVMHandler.MakeStaticCall(scAddress, abi, funcName, args, returnObj, gas, header, state)
+	h.leftoverGas = leftoverGas
+	return leftoverGas, err
+}
+
 // This function will retrieve the balance of an ERC20 token.
 //
 func GetBalanceOf(accountOwner common.Address, contractAddress common.Address, iEvmH *InternalEVMHandler, evm *vm.EVM, gas uint64) (result *big.Int, gasUsed uint64, err error) {
 
 	log.Trace("GetBalanceOf() Called", "accountOwner", accountOwner.Hex(), "contractAddress", contractAddress, "gas", gas)
 
-	var leftoverGas uint64
-
+	handler := new(gasMeteredEVMHandler)
 	if evm != nil {
-		leftoverGas, err = evm.ABIStaticCall(vm.AccountRef(common.HexToAddress("0x0")), contractAddress, balanceOfFuncABI, "balanceOf", []interface{}{accountOwner}, &result, gas)
+		handler.InternalEVMHandler = vmEVMHandler{evm}
 	} else if iEvmH != nil {
-		leftoverGas, err = iEvmH.MakeStaticCall(contractAddress, balanceOfFuncABI, "balanceOf", []interface{}{accountOwner}, &result, gas, nil, nil)
+		handler.InternalEVMHandler = iEvmH
 	} else {
 		err = errors.New("Either iEvmH or evm must be non-nil")
 		return
 	}
 
+	token, err := contracts.NewERC20(contractAddress, handler)
 	if err != nil {
-		log.Error("GetBalanceOf evm invocation error", "leftoverGas", leftoverGas, "err", err)
-		gasUsed = gas - leftoverGas
+		return
+	}
+	result, err = token.BalanceOf(&bind.InternalCallOpts{Gas: gas}, accountOwner)
+	gasUsed = gas - handler.leftoverGas
+
+	if err != nil {
+		log.Error("GetBalanceOf evm invocation error", "leftoverGas", handler.leftoverGas, "err", err)
 		return
 	} else {
-		gasUsed = gas - leftoverGas
 		log.Trace("GetBalanceOf evm invocation success", "accountOwner", accountOwner.Hex(), "Balance", result.String(), "gas used", gasUsed)
 		return
 	}
@@ -310,7 +401,7 @@ type GasCurrencyWhitelist struct {
 
 func (gcWl *GasCurrencyWhitelist) retrieveWhitelist(state *state.StateDB, header *types.Header) ([]common.Address, error) {
 	returnList := []common.Address{}
-	gasCurrencyWhiteListAddress, err := gcWl.regAdd.GetRegisteredAddressAtCurrentHeader(params.GasCurrencyWhitelistRegistryId)
+	gasCurrencyWhiteListAddress, err := gcWl.regAdd.GetRegisteredAddressAtStateAndHeader(params.GasCurrencyWhitelistRegistryId, state, header)
 	if err != nil {
 		if err == ErrSmartContractNotDeployed {
 			log.Warn("Registry address lookup failed", "err", err)
@@ -320,8 +411,11 @@ func (gcWl *GasCurrencyWhitelist) retrieveWhitelist(state *state.StateDB, header
 		return returnList, err
 	}
 
-	_, err = gcWl.iEvmH.MakeStaticCall(*gasCurrencyWhiteListAddress, getWhitelistFuncABI, "getWhitelist", []interface{}{}, &returnList, 20000, header, state)
-	return returnList, err
+	whitelist, err := contracts.NewGasCurrencyWhitelist(*gasCurrencyWhiteListAddress, gcWl.iEvmH)
+	if err != nil {
+		return returnList, err
+	}
+	return whitelist.GetWhitelist(&bind.InternalCallOpts{Gas: 20000, Header: header, State: state})
 }
 
 func (gcWl *GasCurrencyWhitelist) RefreshWhitelistAtStateAndHeader(state *state.StateDB, header *types.Header) {
@@ -352,6 +446,12 @@ func (gcWl *GasCurrencyWhitelist) refreshWhitelist(state *state.StateDB, header
 	gcWl.whitelistedAddressesMu.Unlock()
 }
 
+// WhitelistAtStateAndHeader returns the gas currency whitelist at the given state
+// and header, without refreshing the cached whitelist.
+func (gcWl *GasCurrencyWhitelist) WhitelistAtStateAndHeader(state *state.StateDB, header *types.Header) ([]common.Address, error) {
+	return gcWl.retrieveWhitelist(state, header)
+}
+
 func (gcWl *GasCurrencyWhitelist) IsWhitelisted(gasCurrencyAddress common.Address) bool {
 	gcWl.RefreshWhitelistAtCurrentHeader()
 	gcWl.whitelistedAddressesMu.RLock()
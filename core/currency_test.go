@@ -0,0 +1,92 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// addExchangeRateSnapshot injects a snapshot for the given header in which one unit of
+// currency is worth rate units of gold.
+func addExchangeRateSnapshot(co *CurrencyOperator, header *types.Header, currency common.Address, rate int64) {
+	co.snapshots[exchangeRateKey{hash: header.Hash(), root: header.Root}] = &exchangeRateSnapshot{
+		number: header.Number.Uint64(),
+		rates: map[common.Address]*exchangeRate{
+			currency: {big.NewInt(rate), big.NewInt(1)},
+		},
+	}
+}
+
+// Tests that conversions and comparisons use the exchange rates of the requested
+// header rather than the ones at the current head.
+func TestExchangeRatesPinnedToHeader(t *testing.T) {
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	currency := common.HexToAddress("0xc0ffee")
+
+	old := &types.Header{Number: big.NewInt(1), Root: common.HexToHash("0x01")}
+	head := &types.Header{Number: big.NewInt(2), Root: common.HexToHash("0x02")}
+	addExchangeRateSnapshot(co, old, currency, 2)
+	addExchangeRateSnapshot(co, head, currency, 4)
+	co.setHead(head)
+
+	if val, err := co.ConvertAtHeader(big.NewInt(100), nil, &currency, old); err != nil || val.Cmp(big.NewInt(50)) != 0 {
+		t.Errorf("conversion at old header mismatch: have %v (err %v), want %v", val, err, 50)
+	}
+	if val, err := co.Convert(big.NewInt(100), nil, &currency); err != nil || val.Cmp(big.NewInt(25)) != 0 {
+		t.Errorf("conversion at head mismatch: have %v (err %v), want %v", val, err, 25)
+	}
+	// 30 units of currency are worth 60 gold at the old header, but 120 gold at the head
+	if cmp := co.CmpAtHeader(big.NewInt(100), nil, big.NewInt(30), &currency, old); cmp <= 0 {
+		t.Errorf("comparison at old header mismatch: have %d, want > 0", cmp)
+	}
+	if cmp := co.Cmp(big.NewInt(100), nil, big.NewInt(30), &currency); cmp >= 0 {
+		t.Errorf("comparison at head mismatch: have %d, want < 0", cmp)
+	}
+	// Headers without a snapshot cannot be converted
+	unknown := &types.Header{Number: big.NewInt(3), Root: common.HexToHash("0x03")}
+	if _, err := co.ConvertAtHeader(big.NewInt(100), nil, &currency, unknown); err != errExchangeRateCacheMiss {
+		t.Errorf("conversion at unknown header error mismatch: have %v, want %v", err, errExchangeRateCacheMiss)
+	}
+}
+
+// Tests that snapshots falling out of the retention window are dropped when the
+// head moves.
+func TestExchangeRateSnapshotWindow(t *testing.T) {
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	currency := common.HexToAddress("0xc0ffee")
+
+	headers := make([]*types.Header, 2*exchangeRateSnapshotWindow)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Root: common.BigToHash(big.NewInt(int64(i)))}
+		addExchangeRateSnapshot(co, headers[i], currency, int64(i+1))
+	}
+	head := headers[len(headers)-1]
+	co.setHead(head)
+
+	if len(co.snapshots) != exchangeRateSnapshotWindow {
+		t.Fatalf("retained snapshot count mismatch: have %d, want %d", len(co.snapshots), exchangeRateSnapshotWindow)
+	}
+	for _, snapshot := range co.snapshots {
+		if snapshot.number+exchangeRateSnapshotWindow <= head.Number.Uint64() {
+			t.Errorf("stale snapshot retained: number %d, head %d", snapshot.number, head.Number)
+		}
+	}
+}
@@ -19,90 +19,21 @@ package core
 import (
 	"errors"
 	"math/big"
-	"strings"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
-// TODO (jarmg 5/22/19): Store ABIs in a central location
-const (
-	gasPriceMinimumABIString = `[
-    {
-      "constant": true,
-      "inputs": [],
-      "name": "infrastructureFraction",
-      "outputs": [
-        {
-          "name": "",
-          "type": "uint256"
-        },
-        {
-          "name": "",
-          "type": "uint256"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "view",
-      "type": "function"
-    },
-    {
-      "constant": true,
-      "inputs": [
-        {
-          "name": "_tokenAddress",
-          "type": "address"
-        }
-      ],
-      "name": "getGasPriceMinimum",
-      "outputs": [
-        {
-          "name": "",
-          "type": "uint256"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "view",
-      "type": "function"
-    },
-    {
-      "constant": false,
-      "inputs": [
-        {
-          "name": "_blockGasTotal",
-          "type": "uint256"
-        },
-        {
-          "name": "_blockGasLimit",
-          "type": "uint256"
-        }
-      ],
-      "name": "updateGasPriceMinimum",
-      "outputs": [
-        {
-          "name": "",
-          "type": "uint256"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "nonpayable",
-      "type": "function"
-      } 
-    
-  ]`
-)
-
 const defaultGasAmount = 2000000
 
 var (
-	gasPriceMinimumABI, _                           = abi.JSON(strings.NewReader(gasPriceMinimumABIString))
 	FallbackInfraFraction   *InfrastructureFraction = &InfrastructureFraction{big.NewInt(0), big.NewInt(1)}
 	FallbackGasPriceMinimum *big.Int                = big.NewInt(0) // gasprice min to return if contracts are not found
-	suggestionMultiplier    *big.Int                = big.NewInt(5) // The multiplier that we apply to the minimum when suggesting gas price
 )
 
 type InfrastructureFraction struct {
@@ -110,16 +41,19 @@ type InfrastructureFraction struct {
 	Denominator *big.Int
 }
 
+// InfrastructureFee returns the part of the fee of a transaction using the given
+// amount of gas that is routed to the infrastructure fund. The remainder of the
+// fee goes to the transaction's fee recipient.
+func InfrastructureFee(gasUsed uint64, gasPriceMinimum *big.Int, infraFraction *InfrastructureFraction) *big.Int {
+	infraFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), new(big.Int).Mul(gasPriceMinimum, infraFraction.Numerator))
+	return infraFee.Div(infraFee, infraFraction.Denominator)
+}
+
 type GasPriceMinimum struct {
 	regAdd *RegisteredAddresses
 	iEvmH  *InternalEVMHandler
 }
 
-func (gpm *GasPriceMinimum) GetGasPriceSuggestion(currency *common.Address, state *state.StateDB, header *types.Header) (*big.Int, error) {
-	gasPriceMinimum, err := gpm.GetGasPriceMinimum(currency, state, header)
-	return new(big.Int).Mul(gasPriceMinimum, suggestionMultiplier), err
-}
-
 func (gpm *GasPriceMinimum) GetGasPriceMinimum(currency *common.Address, state *state.StateDB, header *types.Header) (*big.Int, error) {
 
 	if gpm == nil || gpm.iEvmH == nil || gpm.regAdd == nil {
@@ -140,75 +74,51 @@ func (gpm *GasPriceMinimum) GetGasPriceMinimum(currency *common.Address, state *
 		currencyAddress = currency
 	}
 
-	var gasPriceMinimum *big.Int
-	gasPriceMinimumAddress, err := gpm.regAdd.GetRegisteredAddressAtStateAndHeader(params.GasPriceMinimumRegistryId, state, header)
-
+	gasPriceMinimum, err := gpm.contract(state, header)
 	if err != nil {
 		return FallbackGasPriceMinimum, err
 	}
-
-	_, err = gpm.iEvmH.MakeStaticCall(
-		*gasPriceMinimumAddress,
-		gasPriceMinimumABI,
-		"getGasPriceMinimum",
-		[]interface{}{currencyAddress},
-		&gasPriceMinimum,
-		defaultGasAmount,
-		header,
-		state,
-	)
-	return gasPriceMinimum, err
+	return gasPriceMinimum.GetGasPriceMinimum(&bind.InternalCallOpts{Gas: defaultGasAmount, Header: header, State: state}, *currencyAddress)
 }
 
 func (gpm *GasPriceMinimum) UpdateGasPriceMinimum(header *types.Header, state *state.StateDB) (*big.Int, error) {
-	gasPriceMinimumAddress, err := gpm.regAdd.GetRegisteredAddressAtStateAndHeader(params.GasPriceMinimumRegistryId, state, header)
-
+	gasPriceMinimum, err := gpm.contract(state, header)
 	if err != nil {
 		return nil, err
 	}
-
-	var updatedGasPriceMinimum *big.Int
-
-	_, err = gpm.iEvmH.MakeCall(
-		*gasPriceMinimumAddress,
-		gasPriceMinimumABI,
-		"updateGasPriceMinimum",
-		[]interface{}{big.NewInt(int64(header.GasUsed)),
-			big.NewInt(int64(header.GasLimit))},
-		&updatedGasPriceMinimum,
-		defaultGasAmount,
-		big.NewInt(0),
-		header,
-		state,
+	return gasPriceMinimum.UpdateGasPriceMinimum(
+		&bind.InternalCallOpts{Gas: defaultGasAmount, Value: big.NewInt(0), Header: header, State: state},
+		big.NewInt(int64(header.GasUsed)),
+		big.NewInt(int64(header.GasLimit)),
 	)
-	return updatedGasPriceMinimum, err
 }
 
 // Returns the fraction of the gasprice min that should be allocated to the infrastructure fund
 func (gpm *GasPriceMinimum) GetInfrastructureFraction(state *state.StateDB, header *types.Header) (*InfrastructureFraction, error) {
-	infraFraction := [2]*big.Int{big.NewInt(0), big.NewInt(1)} // Give everything to the miner as Fallback
-
 	if gpm == nil || gpm.iEvmH == nil || gpm.regAdd == nil {
 		return FallbackInfraFraction, errors.New("nil iEvmH or addressRegistry")
 	}
 
-	gasPriceMinimumAddress, err := gpm.regAdd.GetRegisteredAddressAtStateAndHeader(params.GasPriceMinimumRegistryId, state, header)
+	gasPriceMinimum, err := gpm.contract(state, header)
 	if err != nil {
 		return FallbackInfraFraction, err
 	}
 
-	_, err = gpm.iEvmH.MakeStaticCall(
-		*gasPriceMinimumAddress,
-		gasPriceMinimumABI,
-		"infrastructureFraction",
-		[]interface{}{},
-		&infraFraction,
-		200000,
-		header,
-		state,
-	)
+	numerator, denominator, err := gasPriceMinimum.InfrastructureFraction(&bind.InternalCallOpts{Gas: 200000, Header: header, State: state})
+	if err != nil {
+		// Give everything to the miner as Fallback
+		return FallbackInfraFraction, err
+	}
+	return &InfrastructureFraction{numerator, denominator}, nil
+}
 
-	return &InfrastructureFraction{infraFraction[0], infraFraction[1]}, err
+// contract returns the GasPriceMinimum contract binding registered at the given state and header.
+func (gpm *GasPriceMinimum) contract(state *state.StateDB, header *types.Header) (*contracts.GasPriceMinimum, error) {
+	gasPriceMinimumAddress, err := gpm.regAdd.GetRegisteredAddressAtStateAndHeader(params.GasPriceMinimumRegistryId, state, header)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.NewGasPriceMinimum(*gasPriceMinimumAddress, gpm.iEvmH)
 }
 
 func NewGasPriceMinimum(iEvmH *InternalEVMHandler, regAdd *RegisteredAddresses) *GasPriceMinimum {
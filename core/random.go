@@ -1,111 +1,50 @@
 package core
 
 import (
-	"crypto/rand"
-	"strings"
+	"fmt"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
 const (
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/Random.json
-	revealAndCommitABI = `[
-	{
-		"constant": false,
-		"inputs": [
-			{
-				"name": "randomness",
-				"type": "bytes32"
-			},
-			{
-				"name": "newCommitment",
-				"type": "bytes32"
-			},
-			{
-				"name": "proposer",
-				"type": "address"
-			}
-		],
-		"name": "revealAndCommit",
-		"outputs": [],
-		"payable": false,
-		"stateMutability": "nonpayable",
-		"type": "function"
-	}
-]`
-	commitmentsAbi = `[
-	{
-		"constant": true,
-		"inputs": [
-			{
-				"name": "",
-				"type": "address"
-			}
-		],
-		"name": "commitments",
-		"outputs": [
-			{
-				"name": "",
-				"type": "bytes32"
-			}
-		],
-		"payable": false,
-		"stateMutability": "view",
-		"type": "function"
-	}
-]`
-
-	computeCommitmentAbi = `[
-    {
-      "constant": true,
-      "inputs": [
-        {
-          "name": "randomness",
-          "type": "bytes32"
-        }
-      ],
-      "name": "computeCommitment",
-      "outputs": [
-        {
-          "name": "",
-          "type": "bytes32"
-        }
-      ],
-      "payable": false,
-      "stateMutability": "view",
-      "type": "function"
-    }
-]`
 	gasAmount = 1000000
+
+	// maxRandomnessRecoveryDepth is the number of blocks searched for the block
+	// that made the last commitment of a validator whose preimage was lost.
+	maxRandomnessRecoveryDepth = 100000
 )
 
 var (
-	revealAndCommitFuncABI, _   = abi.JSON(strings.NewReader(revealAndCommitABI))
-	commitmentsFuncABI, _       = abi.JSON(strings.NewReader(commitmentsAbi))
-	computeCommitmentFuncABI, _ = abi.JSON(strings.NewReader(computeCommitmentAbi))
-	zeroValue                   = common.Big0
-	dbRandomnessPrefix          = []byte("commitment-to-randomness")
+	zeroValue = common.Big0
+
+	// dbRandomnessPrefix prefixes the unencrypted preimages earlier versions kept
+	// in the chain database, see RandomnessStore.Migrate.
+	dbRandomnessPrefix = []byte("commitment-to-randomness")
 )
 
-func commitmentDbLocation(commitment common.Hash) []byte {
-	return append(dbRandomnessPrefix, commitment.Bytes()...)
+// BlockReader retrieves blocks from the local chain.
+type BlockReader interface {
+	// GetBlock retrieves a block from the database by hash and number.
+	GetBlock(hash common.Hash, number uint64) *types.Block
 }
 
 type Random struct {
 	registeredAddresses *RegisteredAddresses
 	iEvmH               *InternalEVMHandler
+	store               *RandomnessStore
 }
 
-func NewRandom(registeredAddresses *RegisteredAddresses, iEvmH *InternalEVMHandler) *Random {
+func NewRandom(registeredAddresses *RegisteredAddresses, iEvmH *InternalEVMHandler, store *RandomnessStore) *Random {
 	r := &Random{
 		registeredAddresses: registeredAddresses,
 		iEvmH:               iEvmH,
+		store:               store,
 	}
 	return r
 }
@@ -124,6 +63,11 @@ func (r *Random) address() *common.Address {
 	}
 }
 
+// contract returns the Random contract binding at the address currently registered.
+func (r *Random) contract() (*contracts.Random, error) {
+	return contracts.NewRandom(*r.address(), r.iEvmH)
+}
+
 func (r *Random) Running() bool {
 	randomAddress := r.address()
 	return randomAddress != nil && *randomAddress != common.ZeroAddress
@@ -131,11 +75,16 @@ func (r *Random) Running() bool {
 
 // GetLastRandomness returns up the last randomness we committed to by first
 // looking up our last commitment in the smart contract, and then finding the
-// corresponding preimage in a (commitment => randomness) mapping we keep in the
-// database.
-func (r *Random) GetLastRandomness(coinbase common.Address, db *ethdb.Database, header *types.Header, state *state.StateDB) (common.Hash, error) {
-	lastCommitment := common.Hash{}
-	_, err := r.iEvmH.MakeStaticCall(*r.address(), commitmentsFuncABI, "commitments", []interface{}{coinbase}, &lastCommitment, gasAmount, header, state)
+// corresponding preimage in the randomness store. If the preimage is missing,
+// ErrRandomnessPreimageNotFound is returned and no block can be proposed until
+// the preimage is recovered or imported from a backup.
+func (r *Random) GetLastRandomness(coinbase common.Address, header *types.Header, state *state.StateDB) (common.Hash, error) {
+	random, err := r.contract()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var lastCommitment common.Hash
+	lastCommitment, err = random.Commitments(&bind.InternalCallOpts{Gas: gasAmount, Header: header, State: state}, coinbase)
 	if err != nil {
 		log.Error("Failed to get last commitment", "err", err)
 		return lastCommitment, err
@@ -146,44 +95,135 @@ func (r *Random) GetLastRandomness(coinbase common.Address, db *ethdb.Database,
 		return common.Hash{}, nil
 	}
 
-	randomness := common.Hash{}
-	randomnessSlice, err := (*db).Get(commitmentDbLocation(lastCommitment))
+	if r.store == nil {
+		return common.Hash{}, ErrRandomnessStoreLocked
+	}
+	randomness, err := r.store.Get(lastCommitment)
 	if err != nil {
-		log.Error("Failed to get randomness from database", "commitment", lastCommitment.Hex(), "err", err)
-	} else {
-		randomness = common.BytesToHash(randomnessSlice)
+		log.Error("Failed to get randomness from store", "commitment", lastCommitment.Hex(), "err", err)
 	}
 	return randomness, err
 }
 
 // GenerateNewRandomnessAndCommitment generates a new random number and a corresponding commitment.
-// The random number is stored in the database, keyed by the corresponding commitment.
-func (r *Random) GenerateNewRandomnessAndCommitment(header *types.Header, state *state.StateDB, db *ethdb.Database) (common.Hash, error) {
+// The random number is derived by the validator key from the parent of the block
+// the commitment is made in, and stored in the randomness store, keyed by the
+// corresponding commitment.
+func (r *Random) GenerateNewRandomnessAndCommitment(header *types.Header, state *state.StateDB) (common.Hash, error) {
 	commitment := common.Hash{}
+	if r.store == nil {
+		return commitment, ErrRandomnessStoreLocked
+	}
 
-	randomBytes := [32]byte{}
-	_, err := rand.Read(randomBytes[0:32])
+	randomness, err := r.store.Derive(header.ParentHash)
 	if err != nil {
 		log.Error("Failed to generate randomness", "err", err)
 		return commitment, err
 	}
-	randomness := common.BytesToHash(randomBytes[:])
+	random, err := r.contract()
+	if err != nil {
+		return commitment, err
+	}
 	// TODO(asa): Make an issue to not have to do this via StaticCall
-	_, err = r.iEvmH.MakeStaticCall(*r.address(), computeCommitmentFuncABI, "computeCommitment", []interface{}{randomness}, &commitment, gasAmount, header, state)
-	err = (*db).Put(commitmentDbLocation(commitment), randomness[:])
+	commitment, err = random.ComputeCommitment(&bind.InternalCallOpts{Gas: gasAmount, Header: header, State: state}, randomness)
+	if err != nil {
+		return commitment, err
+	}
+	err = r.store.Put(commitment, randomness)
 	if err != nil {
-		log.Error("Failed to save randomness to the database", "err", err)
+		log.Error("Failed to save randomness to the store", "err", err)
 	}
 
 	return commitment, err
 }
 
+// RecoverLastRandomness regenerates the preimage of the last commitment of the
+// given coinbase when it is missing from the randomness store. The block that
+// made the commitment is searched for among the recent ancestors of header, and
+// the randomness derived again from its parent. Preimages of commitments made
+// before randomness was derived cannot be recovered this way.
+func (r *Random) RecoverLastRandomness(coinbase common.Address, chain BlockReader, header *types.Header, state *state.StateDB) (common.Hash, error) {
+	if r.store == nil {
+		return common.Hash{}, ErrRandomnessStoreLocked
+	}
+	random, err := r.contract()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts := &bind.InternalCallOpts{Gas: gasAmount, Header: header, State: state}
+	committed, err := random.Commitments(opts, coinbase)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	lastCommitment := common.Hash(committed)
+	hash, number := header.ParentHash, header.Number.Uint64()
+	for depth := 0; depth < maxRandomnessRecoveryDepth && number > 0; depth++ {
+		number--
+		block := chain.GetBlock(hash, number)
+		if block == nil {
+			break
+		}
+		if block.Coinbase() == coinbase && block.Randomness() != nil && block.Randomness().Committed == lastCommitment {
+			randomness, err := r.store.Derive(block.ParentHash())
+			if err != nil {
+				return common.Hash{}, err
+			}
+			commitment, err := random.ComputeCommitment(opts, randomness)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if commitment != lastCommitment {
+				return common.Hash{}, fmt.Errorf("commitment %x in block %d was not derived by the validator key", lastCommitment, number)
+			}
+			log.Info("Recovered the preimage of the last randomness commitment", "commitment", lastCommitment.Hex(), "number", number)
+			return randomness, r.store.Put(lastCommitment, randomness)
+		}
+		hash = block.ParentHash()
+	}
+	return common.Hash{}, ErrRandomnessPreimageNotFound
+}
+
 // RevealAndCommit performs an internal call to the EVM that reveals a
 // proposer's previously committed to randomness, and commits new randomness for
 // a future block.
 func (r *Random) RevealAndCommit(randomness, newCommitment common.Hash, proposer common.Address, header *types.Header, state *state.StateDB) error {
-	args := []interface{}{randomness, newCommitment, proposer}
+	random, err := r.contract()
+	if err != nil {
+		return err
+	}
 	log.Trace("Revealing and committing randomness", "randomness", randomness.Hex(), "commitment", newCommitment.Hex())
-	_, err := r.iEvmH.MakeCall(*r.address(), revealAndCommitFuncABI, "revealAndCommit", args, nil, gasAmount, zeroValue, header, state)
-	return err
+	return random.RevealAndCommit(&bind.InternalCallOpts{Gas: gasAmount, Value: zeroValue, Header: header, State: state}, randomness, newCommitment, proposer)
+}
+
+// contractAt returns the Random contract binding at the address registered in
+// the given state.
+func (r *Random) contractAt(header *types.Header, state *state.StateDB) (*contracts.Random, error) {
+	if r.registeredAddresses == nil {
+		return nil, ErrSmartContractNotDeployed
+	}
+	randomAddress, err := r.registeredAddresses.GetRegisteredAddressAtStateAndHeader(params.RandomRegistryId, state, header)
+	if err != nil {
+		return nil, err
+	}
+	return contracts.NewRandom(*randomAddress, r.iEvmH)
+}
+
+// GetCommitment returns the commitment to randomness the given address last made,
+// as recorded in the given state.
+func (r *Random) GetCommitment(address common.Address, header *types.Header, state *state.StateDB) (common.Hash, error) {
+	random, err := r.contractAt(header, state)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return random.Commitments(&bind.InternalCallOpts{Gas: gasAmount, Header: header, State: state}, address)
+}
+
+// ComputeCommitment returns the commitment the Random contract in the given
+// state derives from the given randomness.
+func (r *Random) ComputeCommitment(randomness common.Hash, header *types.Header, state *state.StateDB) (common.Hash, error) {
+	random, err := r.contractAt(header, state)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return random.ComputeCommitment(&bind.InternalCallOpts{Gas: gasAmount, Header: header, State: state}, randomness)
 }
@@ -0,0 +1,253 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// ErrRandomnessStoreLocked is returned if a preimage is read from or written
+	// to the randomness store before the validator key has unlocked it.
+	ErrRandomnessStoreLocked = errors.New("randomness store locked")
+
+	// ErrRandomnessPreimageNotFound is returned if the randomness store holds no
+	// preimage for a commitment. The validator cannot reveal until the preimage
+	// is regenerated, see Random.RecoverLastRandomness, or imported from a backup.
+	ErrRandomnessPreimageNotFound = errors.New("randomness preimage not found")
+
+	// ErrNondeterministicSigner is returned if the validator key signs the same
+	// message differently, so it can neither unlock the store on another host nor
+	// regenerate lost preimages.
+	ErrNondeterministicSigner = errors.New("validator key signs nondeterministically")
+
+	// randomnessPreimagePrefix + commitment -> nonce + sealed randomness
+	randomnessPreimagePrefix = []byte("randomness-preimage-")
+
+	// randomnessStoreKeyMessage is signed by the validator key to derive the key
+	// preimages are encrypted with.
+	randomnessStoreKeyMessage = []byte("celo randomness preimage store")
+
+	// randomnessDerivationMessage is signed by the validator key, together with a
+	// seed, to derive the randomness committed to.
+	randomnessDerivationMessage = []byte("celo randomness derivation")
+)
+
+// randomnessEntry is the export format of a single preimage, which is kept
+// encrypted so backups are as safe as the store itself.
+type randomnessEntry struct {
+	Commitment common.Hash
+	Sealed     []byte
+}
+
+// RandomnessStore keeps the preimages of the randomness commitments made by the
+// local validator in a database separate from the chain data, encrypted with a
+// key derived from the validator key. Losing the chain data thus doesn't lose
+// the preimages, and a backup of the store is useless without the validator key.
+type RandomnessStore struct {
+	db      ethdb.Database
+	aead    cipher.AEAD // Cipher derived from the validator key, nil until authorized
+	account accounts.Account
+	signFn  func(accounts.Account, []byte) ([]byte, error) // Validator key signer, nil until authorized
+	lock    sync.RWMutex
+}
+
+// NewRandomnessStore creates a randomness store on top of the given database.
+func NewRandomnessStore(db ethdb.Database) *RandomnessStore {
+	return &RandomnessStore{db: db}
+}
+
+// Authorize unlocks the store with the key of the given validator. The
+// encryption key is derived from the validator's signature of a fixed message.
+// Only deterministic (RFC 6979) signers are accepted, so the same validator key
+// unlocks the store on any host it is moved to.
+func (s *RandomnessStore) Authorize(address common.Address, signFn func(accounts.Account, []byte) ([]byte, error)) error {
+	account := accounts.Account{Address: address}
+	hash := crypto.Keccak256(randomnessStoreKeyMessage, address.Bytes())
+	sig, err := signFn(account, hash)
+	if err != nil {
+		return err
+	}
+	again, err := signFn(account, hash)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig, again) {
+		return ErrNondeterministicSigner
+	}
+	block, err := aes.NewCipher(crypto.Keccak256(sig))
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	s.aead, s.account, s.signFn = aead, account, signFn
+	s.lock.Unlock()
+	return nil
+}
+
+// Derive returns the randomness the validator key derives from the given seed.
+// Randomness committed to is derived rather than drawn at random, so a preimage
+// missing from the store can be regenerated from the validator key.
+func (s *RandomnessStore) Derive(seed common.Hash) (common.Hash, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.signFn == nil {
+		return common.Hash{}, ErrRandomnessStoreLocked
+	}
+	sig, err := s.signFn(s.account, crypto.Keccak256(randomnessDerivationMessage, seed.Bytes()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(sig), nil
+}
+
+// Put stores the randomness committed to by the given commitment.
+func (s *RandomnessStore) Put(commitment, randomness common.Hash) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.aead == nil {
+		return ErrRandomnessStoreLocked
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := s.aead.Seal(nonce, nonce, randomness[:], commitment[:])
+	return s.db.Put(randomnessPreimageKey(commitment), sealed)
+}
+
+// Get returns the randomness committed to by the given commitment.
+func (s *RandomnessStore) Get(commitment common.Hash) (common.Hash, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.aead == nil {
+		return common.Hash{}, ErrRandomnessStoreLocked
+	}
+	if has, err := s.db.Has(randomnessPreimageKey(commitment)); err != nil {
+		return common.Hash{}, err
+	} else if !has {
+		return common.Hash{}, ErrRandomnessPreimageNotFound
+	}
+	sealed, err := s.db.Get(randomnessPreimageKey(commitment))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return common.Hash{}, fmt.Errorf("randomness preimage of %x truncated", commitment)
+	}
+	randomness, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], commitment[:])
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to decrypt randomness preimage of %x: %v", commitment, err)
+	}
+	return common.BytesToHash(randomness), nil
+}
+
+// Export writes all preimages in the store to w as an RLP stream, returning the
+// number of preimages written. Preimages stay encrypted with the validator key.
+func (s *RandomnessStore) Export(w io.Writer) (int, error) {
+	keys, err := ethdb.KeysWithPrefix(s.db, randomnessPreimagePrefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		sealed, err := s.db.Get(key)
+		if err != nil {
+			return i, err
+		}
+		entry := randomnessEntry{
+			Commitment: common.BytesToHash(key[len(randomnessPreimagePrefix):]),
+			Sealed:     sealed,
+		}
+		if err := rlp.Encode(w, &entry); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Import reads preimages previously exported by Export from r and adds them to
+// the store, returning the number of preimages imported. The store doesn't need
+// to be unlocked, but the preimages only decrypt with the key of the validator
+// that exported them.
+func (s *RandomnessStore) Import(r io.Reader) (int, error) {
+	stream := rlp.NewStream(r, 0)
+	for imported := 0; ; imported++ {
+		var entry randomnessEntry
+		if err := stream.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return imported, nil
+			}
+			return imported, err
+		}
+		if err := s.db.Put(randomnessPreimageKey(entry.Commitment), entry.Sealed); err != nil {
+			return imported, err
+		}
+	}
+}
+
+// Migrate moves the unencrypted preimages earlier versions kept in the chain
+// database into the store, returning the number of preimages moved.
+func (s *RandomnessStore) Migrate(chainDb ethdb.Database) (int, error) {
+	keys, err := ethdb.KeysWithPrefix(chainDb, dbRandomnessPrefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		randomness, err := chainDb.Get(key)
+		if err != nil {
+			return i, err
+		}
+		commitment := common.BytesToHash(key[len(dbRandomnessPrefix):])
+		if err := s.Put(commitment, common.BytesToHash(randomness)); err != nil {
+			return i, err
+		}
+		if err := chainDb.Delete(key); err != nil {
+			return i, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Close closes the underlying database.
+func (s *RandomnessStore) Close() {
+	s.db.Close()
+}
+
+func randomnessPreimageKey(commitment common.Hash) []byte {
+	return append(randomnessPreimagePrefix, commitment.Bytes()...)
+}
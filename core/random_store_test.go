@@ -0,0 +1,146 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// authorizeRandomnessStore unlocks the store with the given validator key.
+func authorizeRandomnessStore(t *testing.T, store *RandomnessStore, key *ecdsa.PrivateKey) {
+	signFn := func(account accounts.Account, hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, key)
+	}
+	if err := store.Authorize(crypto.PubkeyToAddress(key.PublicKey), signFn); err != nil {
+		t.Fatalf("failed to authorize randomness store: %v", err)
+	}
+}
+
+// Tests that preimages are stored encrypted, only decrypt with the validator key
+// that stored them, and survive an export and import into a new store.
+func TestRandomnessStore(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		other, _   = crypto.GenerateKey()
+		commitment = common.HexToHash("0xc0")
+		randomness = common.HexToHash("0x4a")
+	)
+	db := ethdb.NewMemDatabase()
+	store := NewRandomnessStore(db)
+	if err := store.Put(commitment, randomness); err != ErrRandomnessStoreLocked {
+		t.Fatalf("put into locked store error mismatch: have %v, want %v", err, ErrRandomnessStoreLocked)
+	}
+	authorizeRandomnessStore(t, store, key)
+	if err := store.Put(commitment, randomness); err != nil {
+		t.Fatalf("failed to put preimage: %v", err)
+	}
+	sealed, _ := db.Get(randomnessPreimageKey(commitment))
+	if bytes.Contains(sealed, randomness[:]) {
+		t.Errorf("preimage stored unencrypted")
+	}
+	if have, err := store.Get(commitment); err != nil || have != randomness {
+		t.Errorf("preimage mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+	if _, err := store.Get(common.HexToHash("0xc1")); err != ErrRandomnessPreimageNotFound {
+		t.Errorf("missing preimage error mismatch: have %v, want %v", err, ErrRandomnessPreimageNotFound)
+	}
+
+	// Move the preimages to a new host
+	var dump bytes.Buffer
+	if n, err := store.Export(&dump); err != nil || n != 1 {
+		t.Fatalf("failed to export preimages: have %d, %v, want 1", n, err)
+	}
+	imported := NewRandomnessStore(ethdb.NewMemDatabase())
+	if n, err := imported.Import(bytes.NewReader(dump.Bytes())); err != nil || n != 1 {
+		t.Fatalf("failed to import preimages: have %d, %v, want 1", n, err)
+	}
+	authorizeRandomnessStore(t, imported, other)
+	if _, err := imported.Get(commitment); err == nil {
+		t.Errorf("preimage decrypted with another validator key")
+	}
+	authorizeRandomnessStore(t, imported, key)
+	if have, err := imported.Get(commitment); err != nil || have != randomness {
+		t.Errorf("imported preimage mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+}
+
+// Tests that the unencrypted preimages kept in the chain database by earlier
+// versions are moved into the randomness store.
+func TestRandomnessStoreMigrate(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		commitment = common.HexToHash("0xc0")
+		randomness = common.HexToHash("0x4a")
+	)
+	chainDb := ethdb.NewMemDatabase()
+	chainDb.Put(append(dbRandomnessPrefix, commitment.Bytes()...), randomness[:])
+
+	store := NewRandomnessStore(ethdb.NewMemDatabase())
+	authorizeRandomnessStore(t, store, key)
+	if n, err := store.Migrate(chainDb); err != nil || n != 1 {
+		t.Fatalf("failed to migrate preimages: have %d, %v, want 1", n, err)
+	}
+	if chainDb.Len() != 0 {
+		t.Errorf("preimages left in chain database: %d", chainDb.Len())
+	}
+	if have, err := store.Get(commitment); err != nil || have != randomness {
+		t.Errorf("migrated preimage mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+}
+
+// Tests that randomness is derived deterministically from the validator key, and
+// that validator keys which don't sign deterministically are refused.
+func TestRandomnessStoreDerive(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		seed   = common.HexToHash("0x5eed")
+	)
+	store := NewRandomnessStore(ethdb.NewMemDatabase())
+	if _, err := store.Derive(seed); err != ErrRandomnessStoreLocked {
+		t.Fatalf("derive from locked store error mismatch: have %v, want %v", err, ErrRandomnessStoreLocked)
+	}
+	authorizeRandomnessStore(t, store, key)
+	randomness, err := store.Derive(seed)
+	if err != nil {
+		t.Fatalf("failed to derive randomness: %v", err)
+	}
+	// The same validator key derives the same randomness on another host
+	moved := NewRandomnessStore(ethdb.NewMemDatabase())
+	authorizeRandomnessStore(t, moved, key)
+	if have, err := moved.Derive(seed); err != nil || have != randomness {
+		t.Errorf("derived randomness mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+	if have, _ := moved.Derive(common.HexToHash("0x5eee")); have == randomness {
+		t.Errorf("same randomness derived from different seeds")
+	}
+
+	nonce := byte(0)
+	signFn := func(account accounts.Account, hash []byte) ([]byte, error) {
+		nonce++
+		return crypto.Keccak256(hash, []byte{nonce}), nil
+	}
+	if err := NewRandomnessStore(ethdb.NewMemDatabase()).Authorize(crypto.PubkeyToAddress(key.PublicKey), signFn); err != ErrNondeterministicSigner {
+		t.Errorf("nondeterministic signer error mismatch: have %v, want %v", err, ErrNondeterministicSigner)
+	}
+}
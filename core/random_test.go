@@ -0,0 +1,155 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testRandomCode is a stand-in for the Random contract that answers
+// commitments(address) with the storage slot keyed by the address, and
+// computeCommitment(randomness) with the hash of the randomness.
+//
+// PUSH1 0 CALLDATALOAD PUSH29 0x01<<224 SWAP1 DIV PUSH4 0xe8fcf723 EQ
+// PUSH1 63 JUMPI PUSH1 4 CALLDATALOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 SHA3
+// PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN JUMPDEST PUSH1 4 CALLDATALOAD SLOAD
+// PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+var testRandomCode = common.FromHex("0x6000357c0100000000000000000000000000000000000000000000000000000000900463e8fcf72314603f57600435600052602060002060005260206000f35b6004355460005260206000f3")
+
+// testBlockReader serves blocks from memory.
+type testBlockReader map[common.Hash]*types.Block
+
+func (r testBlockReader) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := r[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+// newTestRandom creates a Random reading the given commitments from the
+// stand-in of the Random contract.
+func newTestRandom(t *testing.T, commitments map[common.Hash]common.Hash, store *RandomnessStore) (*Random, *BlockChain) {
+	var (
+		db     = ethdb.NewMemDatabase()
+		random = common.HexToAddress("0xd006")
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				registrySmartContractAddress: {
+					Code:    testRegistryCode,
+					Storage: map[common.Hash]common.Hash{{}: random.Hash()},
+					Balance: big.NewInt(0),
+				},
+				random: {Code: testRandomCode, Storage: commitments, Balance: big.NewInt(0)},
+			},
+		}
+	)
+	gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	iEvmH := NewInternalEVMHandler(chain)
+	regAdd := NewRegisteredAddresses(iEvmH)
+	iEvmH.SetRegisteredAddresses(regAdd)
+	return NewRandom(regAdd, iEvmH, store), chain
+}
+
+// Tests that commitments are read from the Random contract registered in the
+// given state.
+func TestGetCommitment(t *testing.T) {
+	var (
+		committer  = common.HexToAddress("0x1001")
+		commitment = common.HexToHash("0x01")
+	)
+	r, chain := newTestRandom(t, map[common.Hash]common.Hash{committer.Hash(): commitment}, nil)
+	defer chain.Stop()
+	iEvmH := NewInternalEVMHandler(chain)
+
+	statedb, _ := chain.State()
+	header := chain.CurrentHeader()
+	if have, err := r.GetCommitment(committer, header, statedb); err != nil || have != commitment {
+		t.Errorf("commitment mismatch: have %x (err %v), want %x", have, err, commitment)
+	}
+	// Addresses that never committed have no commitment on record
+	if have, err := r.GetCommitment(common.HexToAddress("0x1002"), header, statedb); err != nil || (have != common.Hash{}) {
+		t.Errorf("commitment mismatch: have %x (err %v), want none", have, err)
+	}
+	// Without a registry lookup, there is no Random contract to read from
+	if _, err := NewRandom(nil, iEvmH, nil).GetCommitment(committer, header, statedb); err != ErrSmartContractNotDeployed {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrSmartContractNotDeployed)
+	}
+}
+
+// Tests that a preimage missing from the randomness store is regenerated from
+// the block that committed to it.
+func TestRecoverLastRandomness(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		validator = crypto.PubkeyToAddress(key.PublicKey)
+		other     = common.HexToAddress("0x1002")
+		store     = NewRandomnessStore(ethdb.NewMemDatabase())
+	)
+	authorizeRandomnessStore(t, store, key)
+
+	// The validator committed in block 1, another validator proposed block 2
+	blocks := make(testBlockReader)
+	seed := common.HexToHash("0x5eed")
+	randomness, _ := store.Derive(seed)
+	commitment := crypto.Keccak256Hash(randomness.Bytes())
+	otherCommitment := crypto.Keccak256Hash(common.HexToHash("0x4a").Bytes())
+	committed := types.NewBlock(&types.Header{ParentHash: seed, Number: big.NewInt(1), Coinbase: validator}, nil, nil, nil, &types.Randomness{Committed: commitment})
+	head := types.NewBlock(&types.Header{ParentHash: committed.Hash(), Number: big.NewInt(2), Coinbase: other}, nil, nil, nil, &types.Randomness{Committed: otherCommitment})
+	blocks[committed.Hash()], blocks[head.Hash()] = committed, head
+	header := &types.Header{ParentHash: head.Hash(), Number: big.NewInt(3), Time: big.NewInt(0), Difficulty: big.NewInt(0)}
+
+	r, chain := newTestRandom(t, map[common.Hash]common.Hash{
+		validator.Hash(): commitment,
+		other.Hash():     otherCommitment,
+	}, store)
+	defer chain.Stop()
+	statedb, _ := chain.State()
+
+	if _, err := r.GetLastRandomness(validator, header, statedb); err != ErrRandomnessPreimageNotFound {
+		t.Fatalf("missing preimage error mismatch: have %v, want %v", err, ErrRandomnessPreimageNotFound)
+	}
+	if have, err := r.RecoverLastRandomness(validator, blocks, header, statedb); err != nil || have != randomness {
+		t.Fatalf("recovered randomness mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+	if have, err := r.GetLastRandomness(validator, header, statedb); err != nil || have != randomness {
+		t.Errorf("stored randomness mismatch: have %x, %v, want %x", have, err, randomness)
+	}
+	// Commitments the validator key didn't derive cannot be recovered
+	if _, err := r.RecoverLastRandomness(other, blocks, header, statedb); err == nil || err == ErrRandomnessPreimageNotFound {
+		t.Errorf("recovered randomness of a commitment not derived by the validator key")
+	}
+	// Nor can commitments of blocks that aren't found
+	orphan := types.CopyHeader(header)
+	orphan.ParentHash = common.HexToHash("0x01")
+	if _, err := r.RecoverLastRandomness(validator, blocks, orphan, statedb); err != ErrRandomnessPreimageNotFound {
+		t.Errorf("missing block error mismatch: have %v, want %v", err, ErrRandomnessPreimageNotFound)
+	}
+}
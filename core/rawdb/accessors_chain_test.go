@@ -278,6 +278,7 @@ func TestBlockReceiptStorage(t *testing.T) {
 		TxHash:          common.BytesToHash([]byte{0x11, 0x11}),
 		ContractAddress: common.BytesToAddress([]byte{0x01, 0x11, 0x11}),
 		GasUsed:         111111,
+		TobinTax:        big.NewInt(11),
 	}
 	receipt2 := &types.Receipt{
 		PostState:         common.Hash{2}.Bytes(),
@@ -310,6 +311,9 @@ func TestBlockReceiptStorage(t *testing.T) {
 				t.Fatalf("receipt #%d: receipt mismatch: have %v, want %v", i, rs[i], receipts[i])
 			}
 		}
+		if rs[0].TobinTax == nil || rs[0].TobinTax.Cmp(receipt1.TobinTax) != 0 {
+			t.Fatalf("tobin tax mismatch: have %v, want %v", rs[0].TobinTax, receipt1.TobinTax)
+		}
 	}
 	// Delete the receipt slice and check purge
 	DeleteReceipts(db, hash, 0)
@@ -317,3 +321,48 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that receipts stored before the tobin tax was recorded can still be
+// retrieved.
+func TestLegacyBlockReceiptStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	// The storage encoding of a receipt without the tobin tax
+	type legacyReceipt struct {
+		PostStateOrStatus   []byte
+		CumulativeGasUsed   uint64
+		Bloom               types.Bloom
+		TxHash              common.Hash
+		ContractAddress     common.Address
+		Logs                []*types.LogForStorage
+		GasUsed             uint64
+		AttestationRequests []types.AttestationRequest
+	}
+	legacy := []legacyReceipt{{
+		PostStateOrStatus: []byte{0x01},
+		CumulativeGasUsed: 1,
+		TxHash:            common.BytesToHash([]byte{0x11, 0x11}),
+		ContractAddress:   common.BytesToAddress([]byte{0x01, 0x11, 0x11}),
+		Logs:              []*types.LogForStorage{{Address: common.BytesToAddress([]byte{0x11})}},
+		GasUsed:           111111,
+	}}
+	data, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatalf("failed to encode legacy receipts: %v", err)
+	}
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	if err := db.Put(blockReceiptsKey(0, hash), data); err != nil {
+		t.Fatalf("failed to store legacy receipts: %v", err)
+	}
+
+	rs := ReadReceipts(db, hash, 0)
+	if len(rs) != 1 {
+		t.Fatalf("receipts mismatch: have %d, want 1", len(rs))
+	}
+	if rs[0].Status != types.ReceiptStatusSuccessful || rs[0].TxHash != legacy[0].TxHash || rs[0].GasUsed != legacy[0].GasUsed || len(rs[0].Logs) != 1 {
+		t.Errorf("receipt mismatch: have %+v", rs[0])
+	}
+	if rs[0].TobinTax != nil {
+		t.Errorf("tobin tax mismatch: have %v, want none", rs[0].TobinTax)
+	}
+}
@@ -18,40 +18,24 @@ package core
 
 import (
 	"errors"
-	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/contracts"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
 )
 
+const registryCacheLimit = 256 // Number of registry states to keep registered addresses for
+
 // ErrSmartContractNotDeployed is returned when the RegisteredAddresses mapping does not contain the specified contract
 var ErrSmartContractNotDeployed = errors.New("registered contract not deployed")
 
-const (
-	// This is taken from celo-monorepo/packages/protocol/build/<env>/contracts/Registry.json
-	getAddressForABI = `[{"constant": true,
-                              "inputs": [
-                                   {
-                                       "name": "identifier",
-                                       "type": "string"
-                                   }
-                              ],
-                              "name": "getAddressFor",
-                              "outputs": [
-                                   {
-                                       "name": "",
-                                       "type": "address"
-                                   }
-                              ],
-                              "payable": false,
-                              "stateMutability": "view",
-                              "type": "function"
-                             }]`
-)
-
 var (
 	registrySmartContractAddress = common.HexToAddress("0x000000000000000000000000000000000000ce10")
 	registeredContractIds        = []string{
@@ -66,45 +50,113 @@ var (
 		params.SortedOraclesRegistryId,
 		params.ValidatorsRegistryId,
 	}
-	getAddressForFuncABI, _ = abi.JSON(strings.NewReader(getAddressForABI))
 )
 
+// registryEVMHandler calls the Registry contract without looking up the registered
+// addresses first, as those are what the Registry is being queried for.
+type registryEVMHandler struct {
+	*InternalEVMHandler
+}
+
+func (h registryEVMHandler) MakeStaticCall(scAddress common.Address, abi abi.ABI, funcName string, args []interface{}, returnObj interface{}, gas uint64, header *types.Header, state *state.StateDB) (uint64, error) {
+	return h.MakeStaticCallNoRegisteredAddressMap(scAddress, abi, funcName, args, returnObj, gas, header, state)
+}
+
+// registryCacheKey identifies the contents of the Registry contract: the root the
+// state was opened at, and the version of the Registry storage within that state.
+// The version changes whenever the Registry is written to, e.g. by setAddressFor
+// during block processing, which invalidates the cached addresses.
+type registryCacheKey struct {
+	root    common.Hash
+	version uint64
+}
+
+// registryLookup is the cached outcome of a Registry lookup.
+type registryLookup struct {
+	address common.Address
+	err     error
+}
+
 type RegisteredAddresses struct {
-	iEvmH *InternalEVMHandler
+	registry *contracts.Registry
+	iEvmH    *InternalEVMHandler
+
+	cache     *lru.Cache // Cache of registryCacheKey -> map[string]registryLookup
+	cacheLock sync.Mutex
+}
+
+// cachedLookup retrieves a previous lookup of the registry id, if any.
+func (ra *RegisteredAddresses) cachedLookup(key registryCacheKey, registryId string) (registryLookup, bool) {
+	ra.cacheLock.Lock()
+	defer ra.cacheLock.Unlock()
+
+	if lookups, ok := ra.cache.Get(key); ok {
+		lookup, ok := lookups.(map[string]registryLookup)[registryId]
+		return lookup, ok
+	}
+	return registryLookup{}, false
+}
+
+// cacheLookup stores the outcome of a lookup of the registry id.
+func (ra *RegisteredAddresses) cacheLookup(key registryCacheKey, registryId string, lookup registryLookup) {
+	ra.cacheLock.Lock()
+	defer ra.cacheLock.Unlock()
+
+	lookups, ok := ra.cache.Get(key)
+	if !ok {
+		lookups = make(map[string]registryLookup)
+		ra.cache.Add(key, lookups)
+	}
+	lookups.(map[string]registryLookup)[registryId] = lookup
+}
+
+// currentState returns the given state, or the state of the current head if nil.
+func (ra *RegisteredAddresses) currentState(state *state.StateDB) (*state.StateDB, error) {
+	if state != nil {
+		return state, nil
+	}
+	return ra.iEvmH.chain.State()
 }
 
 func (ra *RegisteredAddresses) getRegisteredAddress(registryId string, state *state.StateDB, header *types.Header) (*common.Address, error) {
-	var contractAddress common.Address
-	_, err := ra.iEvmH.MakeStaticCallNoRegisteredAddressMap(registrySmartContractAddress, getAddressForFuncABI, "getAddressFor", []interface{}{registryId}, &contractAddress, 20000, header, state)
-	if (contractAddress == common.Address{}) {
+	key := registryCacheKey{root: state.OriginalRoot(), version: state.StorageVersion(registrySmartContractAddress)}
+
+	lookup, ok := ra.cachedLookup(key, registryId)
+	if !ok {
+		lookup.address, lookup.err = ra.registry.GetAddressFor(&bind.InternalCallOpts{Gas: 20000, Header: header, State: state}, registryId)
+		ra.cacheLookup(key, registryId, lookup)
+	}
+	if (lookup.address == common.Address{}) {
 		return nil, ErrSmartContractNotDeployed
 	}
-	return &contractAddress, err
+	contractAddress := lookup.address
+	return &contractAddress, lookup.err
 }
 
 func (ra *RegisteredAddresses) GetRegisteredAddressAtCurrentHeader(registryId string) (*common.Address, error) {
 	if ra == nil {
 		return nil, errors.New("Method called on nil interface of type RegisteredAddresses")
 	}
-	return ra.getRegisteredAddress(registryId, nil, nil)
+	state, err := ra.currentState(nil)
+	if err != nil {
+		return nil, err
+	}
+	return ra.getRegisteredAddress(registryId, state, nil)
 }
 
 func (ra *RegisteredAddresses) GetRegisteredAddressAtStateAndHeader(registryId string, state *state.StateDB, header *types.Header) (*common.Address, error) {
 	if ra == nil {
 		return nil, errors.New("Method called on nil interface of type RegisteredAddresses")
 	}
+	state, err := ra.currentState(state)
+	if err != nil {
+		return nil, err
+	}
 	return ra.getRegisteredAddress(registryId, state, header)
 }
 
 func (ra *RegisteredAddresses) GetRegisteredAddressMapAtCurrentHeader() map[string]*common.Address {
-	returnMap := make(map[string]*common.Address)
-
-	for _, contractRegistryId := range registeredContractIds {
-		contractAddress, _ := ra.getRegisteredAddress(contractRegistryId, nil, nil)
-		returnMap[contractRegistryId] = contractAddress
-	}
-
-	return returnMap
+	return ra.GetRegisteredAddressMapAtStateAndHeader(nil, nil)
 }
 
 func (ra *RegisteredAddresses) GetRegisteredAddressMapAtStateAndHeader(state *state.StateDB, header *types.Header) map[string]*common.Address {
@@ -113,6 +165,11 @@ func (ra *RegisteredAddresses) GetRegisteredAddressMapAtStateAndHeader(state *st
 		return returnMap
 	}
 
+	state, err := ra.currentState(state)
+	if err != nil {
+		log.Error("Error in retrieving the state from the blockchain", "err", err)
+		return returnMap
+	}
 	for _, contractRegistryId := range registeredContractIds {
 		contractAddress, _ := ra.getRegisteredAddress(contractRegistryId, state, header)
 		returnMap[contractRegistryId] = contractAddress
@@ -122,8 +179,17 @@ func (ra *RegisteredAddresses) GetRegisteredAddressMapAtStateAndHeader(state *st
 }
 
 func NewRegisteredAddresses(iEvmH *InternalEVMHandler) *RegisteredAddresses {
+	registry, err := contracts.NewRegistry(registrySmartContractAddress, registryEVMHandler{iEvmH})
+	if err != nil {
+		log.Crit("Failed to bind Registry contract", "err", err)
+	}
+
+	cache, _ := lru.New(registryCacheLimit)
+
 	ra := &RegisteredAddresses{
-		iEvmH: iEvmH,
+		registry: registry,
+		iEvmH:    iEvmH,
+		cache:    cache,
 	}
 
 	return ra
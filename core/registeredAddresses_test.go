@@ -0,0 +1,96 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testRegistryCode is a stand-in for the Registry contract that answers every
+// call with the address stored in its first storage slot.
+//
+// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+var testRegistryCode = common.FromHex("0x60005460005260206000f3")
+
+// Tests that registry lookups are cached per registry state, and that writes to
+// the Registry storage invalidate the cached addresses.
+func TestRegisteredAddressCache(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		original = common.HexToAddress("0x0000000000000000000000000000000000001234")
+		updated  = common.HexToAddress("0x0000000000000000000000000000000000005678")
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				registrySmartContractAddress: {
+					Code:    testRegistryCode,
+					Storage: map[common.Hash]common.Hash{{}: original.Hash()},
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+	)
+	gspec.MustCommit(db)
+	chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	iEvmH := NewInternalEVMHandler(chain)
+	regAdd := NewRegisteredAddresses(iEvmH)
+
+	statedb, _ := chain.State()
+	header := chain.CurrentHeader()
+	check := func(want common.Address) {
+		t.Helper()
+		if address, err := regAdd.GetRegisteredAddressAtStateAndHeader(params.RandomRegistryId, statedb, header); err != nil || *address != want {
+			t.Fatalf("registered address mismatch: have %v (err %v), want %x", address, err, want)
+		}
+	}
+	check(original)
+	if regAdd.cache.Len() != 1 {
+		t.Fatalf("cached registry state count mismatch: have %d, want 1", regAdd.cache.Len())
+	}
+	// Other states at the same root share the cached addresses
+	if address, err := regAdd.GetRegisteredAddressAtCurrentHeader(params.RandomRegistryId); err != nil || *address != original {
+		t.Fatalf("current registered address mismatch: have %v (err %v), want %x", address, err, original)
+	}
+	if regAdd.cache.Len() != 1 {
+		t.Fatalf("cached registry state count mismatch: have %d, want 1", regAdd.cache.Len())
+	}
+	// Writing to the registry, as setAddressFor would, invalidates the cache
+	snapshot := statedb.Snapshot()
+	statedb.SetState(registrySmartContractAddress, common.Hash{}, updated.Hash())
+	check(updated)
+
+	// Reverting the write brings back the original address
+	statedb.RevertToSnapshot(snapshot)
+	check(original)
+
+	// Writes to other contracts keep the cached addresses
+	statedb.SetState(common.HexToAddress("0xc0ffee"), common.Hash{}, updated.Hash())
+	cached := regAdd.cache.Len()
+	check(original)
+	if regAdd.cache.Len() != cached {
+		t.Fatalf("cached registry state count mismatch: have %d, want %d", regAdd.cache.Len(), cached)
+	}
+}
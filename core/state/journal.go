@@ -18,6 +18,7 @@ package state
 
 import (
 	"math/big"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -32,24 +33,38 @@ type journalEntry interface {
 	dirtied() *common.Address
 }
 
+// storageVersionCounter is the source of the storage versions handed out by all
+// journals.
+var storageVersionCounter uint64
+
 // journal contains the list of state modifications applied since the last state
 // commit. These are tracked to be able to be reverted in case of an execution
 // exception or revertal request.
+//
+// Every storage or code modification, and every revert of one, additionally bumps
+// the storage version of the affected account. Unlike the entries, the versions
+// are owned by the StateDB and outlive the journal.
 type journal struct {
-	entries []journalEntry         // Current changes tracked by the journal
-	dirties map[common.Address]int // Dirty accounts and the number of changes
+	entries         []journalEntry            // Current changes tracked by the journal
+	dirties         map[common.Address]int    // Dirty accounts and the number of changes
+	storageVersions map[common.Address]uint64 // Storage versions of the accounts modified since the state was opened
 }
 
-// newJournal create a new initialized journal.
-func newJournal() *journal {
+// newJournal create a new initialized journal, tracking storage versions in the
+// given map.
+func newJournal(storageVersions map[common.Address]uint64) *journal {
 	return &journal{
-		dirties: make(map[common.Address]int),
+		dirties:         make(map[common.Address]int),
+		storageVersions: storageVersions,
 	}
 }
 
 // append inserts a new modification entry to the end of the change journal.
 func (j *journal) append(entry journalEntry) {
 	j.entries = append(j.entries, entry)
+	if addr := storageDirtied(entry); addr != nil {
+		j.markStorageDirty(*addr)
+	}
 	if addr := entry.dirtied(); addr != nil {
 		j.dirties[*addr]++
 	}
@@ -61,6 +76,9 @@ func (j *journal) revert(statedb *StateDB, snapshot int) {
 	for i := len(j.entries) - 1; i >= snapshot; i-- {
 		// Undo the changes made by the operation
 		j.entries[i].revert(statedb)
+		if addr := storageDirtied(j.entries[i]); addr != nil {
+			j.markStorageDirty(*addr)
+		}
 
 		// Drop any dirty tracking induced by the change
 		if addr := j.entries[i].dirtied(); addr != nil {
@@ -79,6 +97,29 @@ func (j *journal) dirty(addr common.Address) {
 	j.dirties[addr]++
 }
 
+// storageDirtied returns the address whose storage or code is modified by the
+// journal entry, if any.
+func storageDirtied(entry journalEntry) *common.Address {
+	switch ch := entry.(type) {
+	case storageChange:
+		return ch.account
+	case codeChange:
+		return ch.account
+	case suicideChange:
+		return ch.account
+	case resetObjectChange:
+		return &ch.prev.address
+	}
+	return nil
+}
+
+// markStorageDirty assigns a new storage version to the given address. Versions
+// are drawn from a process wide counter, so that two states never share a version
+// for different storage contents.
+func (j *journal) markStorageDirty(addr common.Address) {
+	j.storageVersions[addr] = atomic.AddUint64(&storageVersionCounter, 1)
+}
+
 // length returns the current number of entries in the journal.
 func (j *journal) length() int {
 	return len(j.entries)
@@ -60,6 +60,11 @@ type StateDB struct {
 	db   Database
 	trie Trie
 
+	// The root the state was opened at, and the storage versions of the accounts
+	// modified since. Together they identify the storage contents of an account.
+	originalRoot    common.Hash
+	storageVersions map[common.Address]uint64
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects      map[common.Address]*stateObject
 	stateObjectsDirty map[common.Address]struct{}
@@ -94,14 +99,17 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 	if err != nil {
 		return nil, err
 	}
+	storageVersions := make(map[common.Address]uint64)
 	return &StateDB{
 		db:                db,
 		trie:              tr,
+		originalRoot:      root,
+		storageVersions:   storageVersions,
 		stateObjects:      make(map[common.Address]*stateObject),
 		stateObjectsDirty: make(map[common.Address]struct{}),
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
-		journal:           newJournal(),
+		journal:           newJournal(storageVersions),
 	}, nil
 }
 
@@ -116,6 +124,20 @@ func (self *StateDB) Error() error {
 	return self.dbErr
 }
 
+// OriginalRoot returns the root of the state trie the state was opened at.
+func (self *StateDB) OriginalRoot() common.Hash {
+	return self.originalRoot
+}
+
+// StorageVersion returns the version of the storage and code of the given account.
+// It is zero if they were not modified since the state was opened at its original
+// root, and otherwise changes on every modification or revert. Non-zero versions
+// are unique across all states, so the original root and the version together
+// identify the storage contents of an account.
+func (self *StateDB) StorageVersion(addr common.Address) uint64 {
+	return self.storageVersions[addr]
+}
+
 // Reset clears out all ephemeral state objects from the state db, but keeps
 // the underlying state trie to avoid reloading data for the next operations.
 func (self *StateDB) Reset(root common.Hash) error {
@@ -124,6 +146,8 @@ func (self *StateDB) Reset(root common.Hash) error {
 		return err
 	}
 	self.trie = tr
+	self.originalRoot = root
+	self.storageVersions = make(map[common.Address]uint64)
 	self.stateObjects = make(map[common.Address]*stateObject)
 	self.stateObjectsDirty = make(map[common.Address]struct{})
 	self.thash = common.Hash{}
@@ -494,16 +518,22 @@ func (db *StateDB) ForEachStorage(addr common.Address, cb func(key, value common
 // Snapshots of the copied state cannot be applied to the copy.
 func (self *StateDB) Copy() *StateDB {
 	// Copy all the basic fields, initialize the memory ones
+	storageVersions := make(map[common.Address]uint64, len(self.storageVersions))
+	for addr, version := range self.storageVersions {
+		storageVersions[addr] = version
+	}
 	state := &StateDB{
 		db:                self.db,
 		trie:              self.db.CopyTrie(self.trie),
+		originalRoot:      self.originalRoot,
+		storageVersions:   storageVersions,
 		stateObjects:      make(map[common.Address]*stateObject, len(self.journal.dirties)),
 		stateObjectsDirty: make(map[common.Address]struct{}, len(self.journal.dirties)),
 		refund:            self.refund,
 		logs:              make(map[common.Hash][]*types.Log, len(self.logs)),
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
-		journal:           newJournal(),
+		journal:           newJournal(storageVersions),
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.journal.dirties {
@@ -612,7 +642,7 @@ func (self *StateDB) Prepare(thash, bhash common.Hash, ti int) {
 }
 
 func (s *StateDB) clearJournalAndRefund() {
-	s.journal = newJournal()
+	s.journal = newJournal(s.storageVersions)
 	s.validRevisions = s.validRevisions[:0]
 	s.refund = 0
 }
@@ -434,3 +434,53 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that storage versions change on every storage modification and revert,
+// survive the end of a transaction, and are never shared between states.
+func TestStorageVersion(t *testing.T) {
+	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	addr := common.HexToAddress("aaaa")
+
+	if version := sdb.StorageVersion(addr); version != 0 {
+		t.Fatalf("unmodified storage version mismatch: have %d, want 0", version)
+	}
+	// Balance changes don't affect the storage
+	sdb.SetBalance(addr, big.NewInt(42))
+	if version := sdb.StorageVersion(addr); version != 0 {
+		t.Fatalf("storage version changed by balance: have %d, want 0", version)
+	}
+	sdb.SetState(addr, common.Hash{1}, common.Hash{2})
+	written := sdb.StorageVersion(addr)
+	if written == 0 {
+		t.Fatalf("storage version not changed by write")
+	}
+	// Reverting a write must not restore the old version, as it may have been observed
+	snapshot := sdb.Snapshot()
+	sdb.SetState(addr, common.Hash{1}, common.Hash{3})
+	overwritten := sdb.StorageVersion(addr)
+	sdb.RevertToSnapshot(snapshot)
+	reverted := sdb.StorageVersion(addr)
+	if overwritten == written || reverted == written || reverted == overwritten {
+		t.Fatalf("storage versions not unique: written %d, overwritten %d, reverted %d", written, overwritten, reverted)
+	}
+	// Versions are retained across transactions and copies, but not shared afterwards
+	sdb.Finalise(true)
+	if version := sdb.StorageVersion(addr); version != reverted {
+		t.Fatalf("storage version changed by finalise: have %d, want %d", version, reverted)
+	}
+	copy := sdb.Copy()
+	if version := copy.StorageVersion(addr); version != reverted {
+		t.Fatalf("copied storage version mismatch: have %d, want %d", version, reverted)
+	}
+	copy.SetState(addr, common.Hash{1}, common.Hash{4})
+	sdb.SetState(addr, common.Hash{1}, common.Hash{4})
+	if copy.StorageVersion(addr) == sdb.StorageVersion(addr) {
+		t.Fatalf("storage version shared between states: %d", sdb.StorageVersion(addr))
+	}
+	// Resetting the state forgets all versions
+	root, _ := sdb.Commit(true)
+	sdb.Reset(root)
+	if version := sdb.StorageVersion(addr); version != 0 || sdb.OriginalRoot() != root {
+		t.Fatalf("reset state mismatch: version %d, root %x, want 0, %x", version, sdb.OriginalRoot(), root)
+	}
+}
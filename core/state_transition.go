@@ -65,6 +65,7 @@ type StateTransition struct {
 	gasPriceMinimum *big.Int
 	infraFraction   *InfrastructureFraction
 	infraAddress    *common.Address
+	currencyGasUsed uint64 // Gas used to read the balance in, and debit, the non-gold gas currency
 }
 
 // Message represents a message sent to a contract.
@@ -198,7 +199,8 @@ func (st *StateTransition) buyGas() error {
 
 	st.initialGas = st.msg.Gas()
 	st.gas += st.msg.Gas()
-	err := st.debitGas(st.msg.From(), mgval, st.msg.GasCurrency())
+	gasUsed, err := st.debitGas(st.msg.From(), mgval, st.msg.GasCurrency())
+	st.currencyGasUsed += gasUsed
 	return err
 }
 
@@ -206,16 +208,17 @@ func (st *StateTransition) canBuyGas(accountOwner common.Address, gasNeeded *big
 	if gasCurrency == nil {
 		return st.state.GetBalance(accountOwner).Cmp(gasNeeded) > 0
 	}
-	balanceOf, _, err := GetBalanceOf(accountOwner, *gasCurrency, nil, st.evm, params.MaxGasToReadErc20Balance)
+	balanceOf, gasUsed, err := GetBalanceOf(accountOwner, *gasCurrency, nil, st.evm, params.MaxGasToReadErc20Balance)
+	st.currencyGasUsed += gasUsed
 	if err != nil {
 		return false
 	}
 	return balanceOf.Cmp(gasNeeded) > 0
 }
 
-func (st *StateTransition) debitFrom(address common.Address, amount *big.Int, gasCurrency *common.Address) error {
+func (st *StateTransition) debitFrom(address common.Address, amount *big.Int, gasCurrency *common.Address) (uint64, error) {
 	if amount.Cmp(big.NewInt(0)) == 0 {
-		return nil
+		return 0, nil
 	}
 	evm := st.evm
 	// Function is "debitFrom(address from, uint256 value)"
@@ -228,8 +231,8 @@ func (st *StateTransition) debitFrom(address common.Address, amount *big.Int, ga
 
 	rootCaller := vm.AccountRef(common.HexToAddress("0x0"))
 	// The caller was already charged for the cost of this operation via IntrinsicGas.
-	_, _, err := evm.Call(rootCaller, *gasCurrency, transactionData, params.MaxGasForDebitFromTransactions, big.NewInt(0))
-	return err
+	_, leftoverGas, err := evm.Call(rootCaller, *gasCurrency, transactionData, params.MaxGasForDebitFromTransactions, big.NewInt(0))
+	return params.MaxGasForDebitFromTransactions - leftoverGas, err
 }
 
 func (st *StateTransition) creditTo(address common.Address, amount *big.Int, gasCurrency *common.Address) error {
@@ -250,12 +253,14 @@ func (st *StateTransition) creditTo(address common.Address, amount *big.Int, gas
 	return err
 }
 
-func (st *StateTransition) debitGas(from common.Address, amount *big.Int, gasCurrency *common.Address) (err error) {
+// debitGas debits amount from the given account, and returns the gas used to do
+// so if the amount is denominated in a non-gold currency.
+func (st *StateTransition) debitGas(from common.Address, amount *big.Int, gasCurrency *common.Address) (gasUsed uint64, err error) {
 	log.Debug("Debiting gas", "from", from, "amount", amount, "gasCurrency", gasCurrency)
 	// native currency
 	if gasCurrency == nil {
 		st.state.SubBalance(from, amount)
-		return nil
+		return 0, nil
 	} else {
 		return st.debitFrom(from, amount, gasCurrency)
 	}
@@ -353,60 +358,70 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		}
 	}
 
+	// With metered gas currency debits, return the unused part of the gas charged
+	// for reading the balance and debiting it via IntrinsicGas. The credits are
+	// charged their upper bound: the fees they pay are computed from the gas used,
+	// so it has to be settled before they run, and the refund is the last of them.
+	if msg.GasCurrency() != nil && st.evm.ChainConfig().IsGasCurrencyRefund(st.evm.BlockNumber) {
+		if budget := params.MaxGasToReadErc20Balance + params.ExpectedGasForDebitFromTransactions; st.currencyGasUsed < budget {
+			st.gas += budget - st.currencyGasUsed
+		}
+	}
+	st.refundGas()
+	// Return remaining gas to the block gas counter so it is
+	// available for the next transaction.
+	st.gp.AddGas(st.gas)
+
 	// Distribute transaction fees
-	err = st.refundGas()
-	if err != nil {
-		log.Error("Failed to refund gas", "err", err)
+	if err = st.distributeTxFees(); err != nil {
+		log.Error("Failed to distribute transaction fees", "err", err)
 		return nil, 0, false, err
 	}
-	gasUsed := st.gasUsed()
+	return ret, st.gasUsed(), vmerr != nil, nil
+}
+
+// refundGas applies the refund counter to the remaining gas.
+func (st *StateTransition) refundGas() {
+	refund := st.state.GetRefund()
+	// Apply refund counter, capped to half of the used gas.
+	if refund > st.gasUsed()/2 {
+		refund = st.gasUsed() / 2
+	}
+	st.gas += refund
+}
+
+// distributeTxFees returns the remaining gas to the sender, exchanged at the
+// original rate, and pays the fee for the gas used to the infrastructure fund and
+// the fee recipient.
+func (st *StateTransition) distributeTxFees() error {
+	msg := st.msg
+
+	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
+	if err := st.creditGas(msg.From(), remaining, msg.GasCurrency()); err != nil {
+		return err
+	}
 
 	// Pay tx fee to tx fee recipient and Infrastructure fund
-	totalTxFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), st.gasPrice)
+	totalTxFee := new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice)
 
 	var recipientTxFee *big.Int
 	if st.infraAddress != nil {
-		infraTxFee := new(big.Int).Div(new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), new(big.Int).Mul(st.gasPriceMinimum, st.infraFraction.Numerator)), st.infraFraction.Denominator)
+		infraTxFee := InfrastructureFee(st.gasUsed(), st.gasPriceMinimum, st.infraFraction)
 		recipientTxFee = new(big.Int).Sub(totalTxFee, infraTxFee)
-		err = st.creditGas(*st.infraAddress, infraTxFee, msg.GasCurrency())
+		if err := st.creditGas(*st.infraAddress, infraTxFee, msg.GasCurrency()); err != nil {
+			return err
+		}
 	} else {
 		log.Error("no infrastructure account address found - sending entire txFee to fee recipient")
 		recipientTxFee = totalTxFee
 	}
 
-	if err != nil {
-		return nil, 0, false, err
-	}
-
 	txFeeRecipient := msg.GasFeeRecipient()
 	if txFeeRecipient == nil {
 		sender := msg.From()
 		txFeeRecipient = &sender
 	}
-	err = st.creditGas(*txFeeRecipient, recipientTxFee, msg.GasCurrency())
-
-	if err != nil {
-		return nil, 0, false, err
-	}
-
-	return ret, st.gasUsed(), vmerr != nil, err
-}
-
-func (st *StateTransition) refundGas() error {
-	refund := st.state.GetRefund()
-	// Apply refund counter, capped to half of the used gas.
-	if refund > st.gasUsed()/2 {
-		refund = st.gasUsed() / 2
-	}
-
-	st.gas += refund
-	// Return ETH for remaining gas, exchanged at the original rate.
-	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	err := st.creditGas(st.msg.From(), remaining, st.msg.GasCurrency())
-	// Also return remaining gas to the block gas counter so it is
-	// available for the next transaction.
-	st.gp.AddGas(st.gas)
-	return err
+	return st.creditGas(*txFeeRecipient, recipientTxFee, msg.GasCurrency())
 }
 
 // gasUsed returns the amount of gas used up by the state transition.
@@ -0,0 +1,84 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testGasCurrencyCode is a stand-in for a gas currency contract that answers
+// every call, be it balanceOf, debitFrom or creditTo, with a large balance.
+//
+// PUSH8 0xffffffffffffffff PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+var testGasCurrencyCode = common.FromHex("0x67ffffffffffffffff60005260206000f3")
+
+// Tests that the unused gas budgeted for non-gold gas currency debits and credits
+// is only refunded once the gas currency refund fork is active.
+func TestGasCurrencyRefund(t *testing.T) {
+	var (
+		sender    = common.HexToAddress("0x1001")
+		recipient = common.HexToAddress("0x1002")
+		currency  = common.HexToAddress("0xc0ffee")
+		budget    = params.TxGas + params.AdditionalGasForNonGoldCurrencies
+	)
+	apply := func(forkBlock *big.Int) uint64 {
+		config := *params.TestChainConfig
+		config.GasCurrencyRefundBlock = forkBlock
+
+		db := ethdb.NewMemDatabase()
+		gspec := &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				currency: {Code: testGasCurrencyCode, Balance: big.NewInt(0)},
+			},
+		}
+		gspec.MustCommit(db)
+		chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+		defer chain.Stop()
+
+		regAdd := NewRegisteredAddresses(NewInternalEVMHandler(chain))
+		gcWl := NewGasCurrencyWhitelist(regAdd, NewInternalEVMHandler(chain))
+		gcWl.whitelistedAddresses[currency] = true
+
+		statedb, _ := chain.State()
+		msg := types.NewMessage(sender, &recipient, 0, big.NewInt(0), 200000, big.NewInt(1), &currency, nil, nil, false)
+		evm := vm.NewEVM(NewEVMContext(msg, chain.CurrentHeader(), chain, nil, nil), statedb, &config, vm.Config{})
+
+		_, gasUsed, failed, err := ApplyMessage(evm, msg, new(GasPool).AddGas(msg.Gas()), gcWl, big.NewInt(0), FallbackInfraFraction, nil)
+		if err != nil || failed {
+			t.Fatalf("failed to apply message: failed %v, err %v", failed, err)
+		}
+		return gasUsed
+	}
+	if gasUsed := apply(nil); gasUsed != budget {
+		t.Errorf("gas used before fork mismatch: have %d, want %d", gasUsed, budget)
+	}
+	// The stand-in currency uses next to no gas, so nearly all of the balance read
+	// and debit budget is refunded, while the credits are charged in full
+	charged := params.TxGas + 3*params.MaxGasForCreditToTransactions
+	if gasUsed := apply(big.NewInt(0)); gasUsed <= charged || gasUsed >= charged+1000 {
+		t.Errorf("gas used after fork mismatch: have %d, want within (%d, %d)", gasUsed, charged, charged+1000)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// currencyCacheLimit is the number of balances above which the currency cache
+	// is cleared on the next head.
+	currencyCacheLimit = 16384
+
+	// currencyCacheDepth is the maximum number of new blocks the cached balances
+	// are invalidated from, rather than cleared.
+	currencyCacheDepth = 64
+
+	// currencyCacheAge is the number of blocks after which a cached balance is
+	// retrieved again, as blocks can change balances without a trace in their
+	// transactions and logs, e.g. when they are finalized.
+	currencyCacheAge = 64
+)
+
+// transferEventTopic is the topic of the ERC20 Transfer(address,address,uint256) event.
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// currencyBalanceKey identifies the balance of an account in a gas currency.
+type currencyBalanceKey struct {
+	currency common.Address
+	account  common.Address
+}
+
+// cachedBalance is a balance in a gas currency, with the number of the head it
+// was retrieved at.
+type cachedBalance struct {
+	balance *big.Int
+	number  uint64
+}
+
+// currencyCache caches the gas currency whitelist at the pool's current head, and
+// the balances of accounts in the whitelisted currencies, so that validating a
+// transaction paying for gas in a currency doesn't take EVM calls. The balances
+// are kept across heads for up to currencyCacheAge blocks, except those that the
+// transactions and the ERC20 Transfer logs of the new blocks may have changed.
+//
+// Note, the cache is not thread safe, and is guarded by the pool lock.
+type currencyCache struct {
+	whitelist map[common.Address]bool
+	balances  map[currencyBalanceKey]*cachedBalance
+	head      uint64                                                   // Number of the pool's current head
+	balanceOf func(account, currency common.Address) (*big.Int, error) // Retrieves a balance missing from the cache
+}
+
+// newCurrencyCache creates an empty currency cache, which retrieves the missing
+// balances with balanceOf.
+func newCurrencyCache(balanceOf func(account, currency common.Address) (*big.Int, error)) *currencyCache {
+	return &currencyCache{
+		whitelist: make(map[common.Address]bool),
+		balances:  make(map[currencyBalanceKey]*cachedBalance),
+		balanceOf: balanceOf,
+	}
+}
+
+// isWhitelisted returns whether gas can be paid for in the given currency.
+func (c *currencyCache) isWhitelisted(currency common.Address) bool {
+	return c.whitelist[currency]
+}
+
+// setWhitelist replaces the whitelisted currencies, dropping the balances in the
+// currencies no longer whitelisted.
+func (c *currencyCache) setWhitelist(whitelist []common.Address) {
+	c.whitelist = make(map[common.Address]bool)
+	for _, currency := range whitelist {
+		c.whitelist[currency] = true
+	}
+	for key := range c.balances {
+		if !c.whitelist[key.currency] {
+			delete(c.balances, key)
+		}
+	}
+}
+
+// balance returns the balance of an account in a currency, retrieving it if it
+// isn't cached.
+func (c *currencyCache) balance(account, currency common.Address) (*big.Int, error) {
+	key := currencyBalanceKey{currency: currency, account: account}
+	if cached, ok := c.balances[key]; ok {
+		return cached.balance, nil
+	}
+	balance, err := c.balanceOf(account, currency)
+	if err != nil {
+		return nil, err
+	}
+	c.balances[key] = &cachedBalance{balance: balance, number: c.head}
+	return balance, nil
+}
+
+// setHead moves the cache to the head with the given number, dropping the
+// balances retrieved currencyCacheAge blocks or more before it.
+func (c *currencyCache) setHead(number uint64) {
+	c.head = number
+	for key, cached := range c.balances {
+		if cached.number+currencyCacheAge <= number {
+			delete(c.balances, key)
+		}
+	}
+}
+
+// invalidate drops the balances the given block may have changed: those of the
+// senders and recipients of the tokens transferred, of the senders paying for gas
+// in a currency, and of the recipients of their gas fees, including the given
+// infrastructure fund, if any.
+func (c *currencyCache) invalidate(block *types.Block, receipts types.Receipts, signer types.Signer, infra *common.Address) {
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if len(log.Topics) != 3 || log.Topics[0] != transferEventTopic {
+				continue
+			}
+			delete(c.balances, currencyBalanceKey{currency: log.Address, account: common.BytesToAddress(log.Topics[1].Bytes())})
+			delete(c.balances, currencyBalanceKey{currency: log.Address, account: common.BytesToAddress(log.Topics[2].Bytes())})
+		}
+	}
+	for _, tx := range block.Transactions() {
+		currency := tx.GasCurrency()
+		if currency == nil {
+			continue
+		}
+		if from, err := types.Sender(signer, tx); err == nil {
+			delete(c.balances, currencyBalanceKey{currency: *currency, account: from})
+		}
+		if recipient := tx.GasFeeRecipient(); recipient != nil {
+			delete(c.balances, currencyBalanceKey{currency: *currency, account: *recipient})
+		}
+		if infra != nil {
+			delete(c.balances, currencyBalanceKey{currency: *currency, account: *infra})
+		}
+		delete(c.balances, currencyBalanceKey{currency: *currency, account: block.Coinbase()})
+	}
+}
+
+// clear drops all the cached balances.
+func (c *currencyCache) clear() {
+	c.balances = make(map[currencyBalanceKey]*cachedBalance)
+}
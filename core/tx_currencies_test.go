@@ -0,0 +1,259 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testBalances is a gas currency balance source counting the balances retrieved.
+type testBalances struct {
+	balances map[currencyBalanceKey]*big.Int
+	fetched  int
+}
+
+func (b *testBalances) set(account, currency common.Address, balance int64) {
+	b.balances[currencyBalanceKey{currency: currency, account: account}] = big.NewInt(balance)
+}
+
+func (b *testBalances) balanceOf(account, currency common.Address) (*big.Int, error) {
+	b.fetched++
+	if balance, ok := b.balances[currencyBalanceKey{currency: currency, account: account}]; ok {
+		return balance, nil
+	}
+	return new(big.Int), nil
+}
+
+// Tests that balances are retrieved once, until a block may have changed them.
+func TestCurrencyCacheInvalidation(t *testing.T) {
+	source := &testBalances{balances: make(map[currencyBalanceKey]*big.Int)}
+	cache := newCurrencyCache(source.balanceOf)
+
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	currency, other := common.HexToAddress("0xc0ffee"), common.HexToAddress("0xdecaf")
+	recipient, coinbase, bystander := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	infra := common.HexToAddress("0x04")
+	cache.setWhitelist([]common.Address{currency, other})
+
+	accounts := []common.Address{sender, recipient, coinbase, bystander, infra}
+	for _, account := range accounts {
+		cache.balance(account, currency)
+		cache.balance(account, other)
+	}
+	for _, account := range accounts {
+		cache.balance(account, currency)
+	}
+	if source.fetched != 10 {
+		t.Fatalf("fetched balances mismatch: have %d, want 10", source.fetched)
+	}
+
+	// The recipient received other tokens, while the sender paid for gas in currency
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1), Coinbase: coinbase}, types.Transactions{currencyTransaction(0, 1, &currency, key)}, nil, nil, nil)
+	receipts := types.Receipts{{Logs: []*types.Log{{
+		Address: other,
+		Topics:  []common.Hash{transferEventTopic, common.BytesToHash(bystander.Bytes()), common.BytesToHash(recipient.Bytes())},
+	}}}}
+	cache.invalidate(block, receipts, types.HomesteadSigner{}, &infra)
+
+	invalidated := map[currencyBalanceKey]bool{
+		{currency: currency, account: sender}:   true,
+		{currency: currency, account: coinbase}: true,
+		{currency: currency, account: infra}:    true,
+		{currency: other, account: bystander}:   true,
+		{currency: other, account: recipient}:   true,
+	}
+	for _, account := range accounts {
+		for _, currency := range []common.Address{currency, other} {
+			key := currencyBalanceKey{currency: currency, account: account}
+			if _, cached := cache.balances[key]; cached == invalidated[key] {
+				t.Errorf("balance of %x in %x: cached mismatch: have %v, want %v", account, currency, cached, !invalidated[key])
+			}
+		}
+	}
+
+	// Balances in currencies leaving the whitelist are dropped
+	cache.setWhitelist([]common.Address{currency})
+	if cache.isWhitelisted(other) || len(cache.balances) != 2 {
+		t.Errorf("cache mismatch after delisting: whitelisted %v, %d balances", cache.isWhitelisted(other), len(cache.balances))
+	}
+}
+
+// Tests that cached balances are retrieved again once they get too old, as blocks
+// can change balances without a trace in their transactions and logs.
+func TestCurrencyCacheExpiry(t *testing.T) {
+	source := &testBalances{balances: make(map[currencyBalanceKey]*big.Int)}
+	cache := newCurrencyCache(source.balanceOf)
+
+	currency := common.HexToAddress("0xc0ffee")
+	old, recent := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	cache.setWhitelist([]common.Address{currency})
+
+	cache.setHead(1)
+	cache.balance(old, currency)
+	cache.setHead(2)
+	cache.balance(recent, currency)
+
+	cache.setHead(currencyCacheAge)
+	if len(cache.balances) != 2 {
+		t.Fatalf("cached balances mismatch: have %d, want 2", len(cache.balances))
+	}
+	cache.setHead(currencyCacheAge + 1)
+	if _, cached := cache.balances[currencyBalanceKey{currency: currency, account: old}]; cached {
+		t.Errorf("expired balance still cached")
+	}
+	if _, cached := cache.balances[currencyBalanceKey{currency: currency, account: recent}]; !cached {
+		t.Errorf("recent balance not cached")
+	}
+	cache.balance(old, currency)
+	if source.fetched != 3 {
+		t.Fatalf("fetched balances mismatch: have %d, want 3", source.fetched)
+	}
+}
+
+// testReceiptsChain is a test chain serving the blocks and receipts it holds.
+type testReceiptsChain struct {
+	*testBlockChain
+	blocks   map[common.Hash]*types.Block
+	receipts map[common.Hash]types.Receipts
+}
+
+func (bc *testReceiptsChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.blocks[hash]
+}
+
+func (bc *testReceiptsChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return bc.receipts[hash]
+}
+
+// Tests that the pool invalidates the balances the new blocks changed, and clears
+// the cached balances if the receipts of a new block are missing.
+func TestUpdateCurrencies(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testReceiptsChain{
+		testBlockChain: &testBlockChain{statedb, 1000000, new(event.Feed)},
+		blocks:         make(map[common.Hash]*types.Block),
+		receipts:       make(map[common.Hash]types.Receipts),
+	}
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
+	defer pool.Stop()
+
+	source := &testBalances{balances: make(map[currencyBalanceKey]*big.Int)}
+	pool.currencies = newCurrencyCache(source.balanceOf)
+
+	currency := common.HexToAddress("0xc0ffee")
+	sender, bystander := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	pool.currencies.setWhitelist([]common.Address{currency})
+
+	parent := &types.Header{Number: big.NewInt(1)}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(2), ParentHash: parent.Hash()}, nil, nil, nil, nil)
+	blockchain.blocks[block.Hash()] = block
+
+	// Without the receipts of the new block, the cached balances are cleared
+	pool.currencies.balance(sender, currency)
+	pool.currencies.balance(bystander, currency)
+	pool.mu.Lock()
+	pool.updateCurrencies(parent, block.Header(), statedb)
+	pool.mu.Unlock()
+	if len(pool.currencies.balances) != 0 {
+		t.Fatalf("cached balances mismatch: have %d, want 0", len(pool.currencies.balances))
+	}
+
+	// With them, only the balances the block changed are dropped
+	pool.currencies.balance(sender, currency)
+	pool.currencies.balance(bystander, currency)
+	blockchain.receipts[block.Hash()] = types.Receipts{{Logs: []*types.Log{{
+		Address: currency,
+		Topics:  []common.Hash{transferEventTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(sender.Bytes())},
+	}}}}
+	pool.mu.Lock()
+	pool.updateCurrencies(parent, block.Header(), statedb)
+	pool.mu.Unlock()
+	if _, cached := pool.currencies.balances[currencyBalanceKey{currency: currency, account: sender}]; cached {
+		t.Errorf("changed balance still cached")
+	}
+	if _, cached := pool.currencies.balances[currencyBalanceKey{currency: currency, account: bystander}]; !cached {
+		t.Errorf("unchanged balance not cached")
+	}
+}
+
+// Tests that the pool validates transactions paying for gas in currencies against
+// the cached balances, and drops them when the balances drop.
+func TestTransactionCurrencyBalances(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
+	defer pool.Stop()
+
+	source := &testBalances{balances: make(map[currencyBalanceKey]*big.Int)}
+	pool.currencies = newCurrencyCache(source.balanceOf)
+
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	currency, delisted := common.HexToAddress("0xc0ffee"), common.HexToAddress("0xdecaf")
+	pool.currencies.setWhitelist([]common.Address{currency})
+	source.set(account, currency, 600000)
+
+	if err := pool.AddRemote(currencyTransaction(0, 1, &delisted, key)); err != ErrNonWhitelistedGasCurrency {
+		t.Fatalf("non-whitelisted transaction error mismatch: have %v, want %v", err, ErrNonWhitelistedGasCurrency)
+	}
+	for nonce, price := range []int64{1, 2, 1} {
+		if err := pool.AddRemote(currencyTransaction(uint64(nonce), price, &currency, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(currencyTransaction(3, 4, &currency, key)); err != ErrInsufficientFunds {
+		t.Fatalf("unaffordable transaction error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	if source.fetched != 1 {
+		t.Fatalf("fetched balances mismatch: have %d, want 1", source.fetched)
+	}
+
+	// Once the balance drops, the transactions it can't pay for are dropped, and
+	// the following ones queued
+	source.set(account, currency, 300000)
+	pool.currencies.clear()
+	pool.lockedReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending, %d queued, want 1, 1", pending, queued)
+	}
+	if source.fetched != 2 {
+		t.Fatalf("fetched balances mismatch: have %d, want 2", source.fetched)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -248,19 +248,16 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 //
 // If the new transaction is accepted into the list, the lists' cost and gas
 // thresholds are also potentially updated.
-func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
+//
+// The prices of the old and new transactions are compared in gold, so that a
+// transaction can be replaced by one paying for gas in another currency.
+func (l *txList) Add(tx *types.Transaction, priceBump uint64, pricer *txPricer) (bool, *types.Transaction) {
 	// If there's an older better transaction, abort
-	old := l.txs.Get(tx.Nonce())
-	if old != nil {
-		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
-		// Have to ensure that the new gas price is higher than the old gas
-		// price as well as checking the percentage threshold to ensure that
-		// this is accurate for low (Wei-level) gas price replacements
-		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
-			return false, nil
-		}
+	if !l.Replaceable(tx, priceBump, pricer) {
+		return false, nil
 	}
 	// Otherwise overwrite the old transaction with the current one
+	old := l.txs.Get(tx.Nonce())
 	l.txs.Put(tx)
 	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
@@ -271,6 +268,22 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	return true, old
 }
 
+// Replaceable checks whether a transaction would be added to the list, which it
+// isn't if it replaces a transaction without bumping its price by priceBump
+// percent.
+func (l *txList) Replaceable(tx *types.Transaction, priceBump uint64, pricer *txPricer) bool {
+	old := l.txs.Get(tx.Nonce())
+	if old == nil {
+		return true
+	}
+	threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
+	// Have to ensure that the new gas price is higher than the old gas
+	// price as well as checking the percentage threshold to ensure that
+	// this is accurate for low (Wei-level) gas price replacements
+	return pricer.cmp(old.GasPrice(), old.GasCurrency(), tx.GasPrice(), tx.GasCurrency()) < 0 &&
+		pricer.cmp(threshold, old.GasCurrency(), tx.GasPrice(), tx.GasCurrency()) <= 0
+}
+
 // Forward removes all transactions from the list with a nonce lower than the
 // provided threshold. Every removed transaction is returned for any post-removal
 // maintenance.
@@ -308,19 +321,38 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 		}
 	})
 
-	// If the list was strict, filter anything above the lowest nonce
-	var invalids types.Transactions
+	return removed, l.invalidated(removed)
+}
 
-	if l.strict && len(removed) > 0 {
-		lowest := uint64(math.MaxUint64)
-		for _, tx := range removed {
-			if nonce := tx.Nonce(); lowest > nonce {
-				lowest = nonce
-			}
+// FilterGasCurrencies removes all transactions from the list paying for gas in a
+// currency with a gas cost higher than the balance in that currency, as returned
+// by balance. Transactions whose balance is nil are kept. Every removed
+// transaction is returned for any post-removal maintenance. Strict-mode
+// invalidated transactions are also returned.
+func (l *txList) FilterGasCurrencies(balance func(currency common.Address) *big.Int) (types.Transactions, types.Transactions) {
+	removed := l.txs.Filter(func(tx *types.Transaction) bool {
+		if tx.GasCurrency() == nil {
+			return false
+		}
+		limit := balance(*tx.GasCurrency())
+		return limit != nil && new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())).Cmp(limit) > 0
+	})
+	return removed, l.invalidated(removed)
+}
+
+// invalidated removes and returns the transactions above the lowest nonce of the
+// removed ones, if the list is strict.
+func (l *txList) invalidated(removed types.Transactions) types.Transactions {
+	if !l.strict || len(removed) == 0 {
+		return nil
+	}
+	lowest := uint64(math.MaxUint64)
+	for _, tx := range removed {
+		if nonce := tx.Nonce(); lowest > nonce {
+			lowest = nonce
 		}
-		invalids = l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
 	}
-	return removed, invalids
+	return l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
 }
 
 // Cap places a hard limit on the number of items, returning all transactions
@@ -404,6 +436,34 @@ func (h *priceHeap) Pop() interface{} {
 	return x
 }
 
+// txPricer compares gas prices denominated in possibly different currencies by
+// normalizing them to gold. The exchange rates are those of the block the pool
+// state is at, rather than of the latest chain head, so that all the comparisons
+// made against the same pool state agree with each other.
+type txPricer struct {
+	co     *CurrencyOperator // Exchange rate oracle
+	header *types.Header     // Block the exchange rates are taken from, the latest chain head if nil
+}
+
+// newTxPricer creates a pricer using the exchange rates of the latest chain head
+// until it is pinned to a block.
+func newTxPricer(co *CurrencyOperator) *txPricer {
+	return &txPricer{co: co}
+}
+
+// setHeader pins the exchange rates to the given block.
+func (p *txPricer) setHeader(header *types.Header) {
+	p.header = header
+}
+
+// cmp compares the gold equivalents of two gas prices.
+func (p *txPricer) cmp(price1 *big.Int, currency1 *common.Address, price2 *big.Int, currency2 *common.Address) int {
+	if currency1 == nil && currency2 == nil || currency1 != nil && currency2 != nil && *currency1 == *currency2 {
+		return price1.Cmp(price2)
+	}
+	return p.co.CmpAtHeader(price1, currency1, price2, currency2, p.header)
+}
+
 // txPricedList is a price-sorted heap to allow operating on transactions pool
 // contents in a price-incrementing way.
 type txPricedList struct {
@@ -411,16 +471,16 @@ type txPricedList struct {
 	nonNilCurrencyHeaps map[common.Address]*priceHeap // Heap of prices of all the stored non-nil currency transactions
 	nilCurrencyHeap     *priceHeap                    // Heap of prices of all the stored nil currency transactions
 	stales              int                           // Number of stale price points to (re-heap trigger)
-	co                  *CurrencyOperator             // Comparator object used to compare prices that are using different currencies
+	pricer              *txPricer                     // Comparator used to compare prices that are using different currencies
 }
 
 // newTxPricedList creates a new price-sorted transaction heap.
-func newTxPricedList(all *txLookup, co *CurrencyOperator) *txPricedList {
+func newTxPricedList(all *txLookup, pricer *txPricer) *txPricedList {
 	return &txPricedList{
 		all:                 all,
 		nonNilCurrencyHeaps: make(map[common.Address]*priceHeap),
 		nilCurrencyHeap:     new(priceHeap),
-		co:                  co,
+		pricer:              pricer,
 	}
 }
 
@@ -489,7 +549,7 @@ func (l *txPricedList) Cap(cgThreshold *big.Int, local *accountSet) types.Transa
 			continue
 		}
 
-		if l.co.Cmp(tx.GasPrice(), tx.GasCurrency(), cgThreshold, nil) >= 0 {
+		if l.pricer.cmp(tx.GasPrice(), tx.GasCurrency(), cgThreshold, nil) >= 0 {
 			save = append(save, tx)
 			break
 		}
@@ -531,18 +591,77 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 	}
 
 	cheapest := l.getMinPricedTx()
-	return l.co.Cmp(cheapest.GasPrice(), cheapest.GasCurrency(), tx.GasPrice(), tx.GasCurrency()) >= 0
+	return l.pricer.cmp(cheapest.GasPrice(), cheapest.GasCurrency(), tx.GasPrice(), tx.GasCurrency()) >= 0
+}
+
+// CurrencyUnderpriced checks whether a transaction is cheaper than (or as cheap
+// as) the lowest priced transaction currently being tracked in its gas currency.
+func (l *txPricedList) CurrencyUnderpriced(tx *types.Transaction, local *accountSet) bool {
+	// Local transactions cannot be underpriced
+	if local.containsTx(tx) {
+		return false
+	}
+	h := l.getPriceHeap(tx)
+	// Discard stale price points if found at the heap start
+	for h.Len() > 0 {
+		head := (*h)[0]
+		if l.all.Get(head.Hash()) == nil {
+			l.stales--
+			heap.Pop(h)
+			continue
+		}
+		break
+	}
+	if h.Len() == 0 {
+		return false
+	}
+	return (*h)[0].GasPrice().Cmp(tx.GasPrice()) >= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
 // priced list and returns them for further removal from the entire pool.
 func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions {
+	return l.discard(count, local, l.Len, l.pop)
+}
+
+// DiscardCurrency finds a number of most underpriced transactions paying for gas
+// in the given currency, removes them from the priced list and returns them for
+// further removal from the entire pool.
+func (l *txPricedList) DiscardCurrency(currency common.Address, count int, local *accountSet) types.Transactions {
+	h, ok := l.nonNilCurrencyHeaps[currency]
+	if !ok {
+		return nil
+	}
+	return l.discard(count, local, h.Len, func() *types.Transaction { return heap.Pop(h).(*types.Transaction) })
+}
+
+// CurrencyDiscardable checks whether the given number of remote transactions
+// paying for gas in the currency can be discarded, without discarding them.
+func (l *txPricedList) CurrencyDiscardable(currency common.Address, count int, local *accountSet) bool {
+	h, ok := l.nonNilCurrencyHeaps[currency]
+	if !ok {
+		return count <= 0
+	}
+	for _, tx := range *h {
+		if count <= 0 {
+			break
+		}
+		if l.all.Get(tx.Hash()) != nil && !local.containsTx(tx) {
+			count--
+		}
+	}
+	return count <= 0
+}
+
+// discard removes the given number of remote transactions from the priced list,
+// in the order they are popped.
+func (l *txPricedList) discard(count int, local *accountSet, size func() int, pop func() *types.Transaction) types.Transactions {
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
 
-	for l.Len() > 0 && count > 0 {
+	for size() > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
-		tx := l.pop()
+		tx := pop()
 		if l.all.Get(tx.Hash()) == nil {
 			l.stales--
 			continue
@@ -579,8 +698,8 @@ func (l *txPricedList) getHeapWithMinHead() (*priceHeap, *types.Transaction) {
 				cheapestTxn = []*types.Transaction(*cheapestHeap)[0]
 			} else {
 				txn := []*types.Transaction(*priceHeap)[0]
-				if l.co.Cmp(cheapestTxn.GasPrice(), cheapestTxn.GasCurrency(), txn.GasPrice(), txn.GasCurrency()) < 0 {
-					cheapestHeap = priceHeap
+				if l.pricer.cmp(txn.GasPrice(), txn.GasCurrency(), cheapestTxn.GasPrice(), cheapestTxn.GasCurrency()) < 0 {
+					cheapestHeap, cheapestTxn = priceHeap, txn
 				}
 			}
 		}
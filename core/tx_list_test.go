@@ -17,9 +17,12 @@
 package core
 
 import (
+	"crypto/ecdsa"
+	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -36,8 +39,9 @@ func TestStrictTxListAdd(t *testing.T) {
 	}
 	// Insert the transactions in a random order
 	list := newTxList(true)
+	pricer := newTxPricer(NewCurrencyOperator(nil, nil, nil, nil))
 	for _, v := range rand.Perm(len(txs)) {
-		list.Add(txs[v], DefaultTxPoolConfig.PriceBump)
+		list.Add(txs[v], DefaultTxPoolConfig.PriceBump, pricer)
 	}
 	// Verify internal state
 	if len(list.txs.items) != len(txs) {
@@ -49,3 +53,106 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// currencyTransaction creates a transaction paying for gas in the given currency.
+func currencyTransaction(nonce uint64, gasprice int64, currency *common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(100), 200000, big.NewInt(gasprice), currency, nil, nil), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// newTestPricer creates a pricer pinned to a block in which one unit of the
+// returned currency is worth rate units of gold, while the chain head uses
+// another rate.
+func newTestPricer(rate int64) (*txPricer, *common.Address) {
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	currency := common.HexToAddress("0xc0ffee")
+
+	header := &types.Header{Number: big.NewInt(1), Root: common.HexToHash("0x01")}
+	head := &types.Header{Number: big.NewInt(2), Root: common.HexToHash("0x02")}
+	addExchangeRateSnapshot(co, header, currency, rate)
+	addExchangeRateSnapshot(co, head, currency, 100*rate)
+	co.setHead(head)
+
+	pricer := newTxPricer(co)
+	pricer.setHeader(header)
+	return pricer, &currency
+}
+
+// Tests that replacements are priced in gold, at the exchange rates of the block
+// the pricer is pinned to, whatever the currencies of the old and new transactions.
+func TestTxListCurrencyReplacement(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pricer, currency := newTestPricer(2)
+
+	tests := []struct {
+		old, replacement *types.Transaction
+		replaced         bool
+	}{
+		// Same currency, the bump applies to the raw prices
+		{currencyTransaction(0, 100, currency, key), currencyTransaction(0, 109, currency, key), false},
+		{currencyTransaction(0, 100, currency, key), currencyTransaction(0, 110, currency, key), true},
+		// 100 units of currency are worth 200 gold
+		{currencyTransaction(0, 100, currency, key), currencyTransaction(0, 219, nil, key), false},
+		{currencyTransaction(0, 100, currency, key), currencyTransaction(0, 220, nil, key), true},
+		// 200 gold are worth 100 units of currency
+		{currencyTransaction(0, 200, nil, key), currencyTransaction(0, 109, currency, key), false},
+		{currencyTransaction(0, 200, nil, key), currencyTransaction(0, 110, currency, key), true},
+		// A raw price bump that is a drop in gold is rejected
+		{currencyTransaction(0, 150, nil, key), currencyTransaction(0, 70, currency, key), false},
+	}
+	for i, tt := range tests {
+		list := newTxList(true)
+		list.Add(tt.old, DefaultTxPoolConfig.PriceBump, pricer)
+
+		inserted, old := list.Add(tt.replacement, DefaultTxPoolConfig.PriceBump, pricer)
+		if inserted != tt.replaced {
+			t.Errorf("test %d: replacement mismatch: have %v, want %v", i, inserted, tt.replaced)
+		}
+		if tt.replaced && old != tt.old {
+			t.Errorf("test %d: replaced transaction mismatch: have %v, want %v", i, old, tt.old)
+		}
+		if have := list.txs.Get(0); tt.replaced && have != tt.replacement || !tt.replaced && have != tt.old {
+			t.Errorf("test %d: transaction mismatch after replacement attempt", i)
+		}
+	}
+}
+
+// Tests that the priced list orders transactions across currencies by their gold
+// equivalent prices.
+func TestTxPricedListCurrencyOrdering(t *testing.T) {
+	pricer, currency := newTestPricer(2)
+
+	key, _ := crypto.GenerateKey()
+	txs := types.Transactions{
+		currencyTransaction(0, 30, nil, key),      // 30 gold
+		currencyTransaction(1, 10, currency, key), // 20 gold
+		currencyTransaction(2, 25, nil, key),      // 25 gold
+		currencyTransaction(3, 20, currency, key), // 40 gold
+		currencyTransaction(4, 10, nil, key),      // 10 gold
+	}
+	all := newTxLookup()
+	priced := newTxPricedList(all, pricer)
+	for _, tx := range txs {
+		all.Add(tx)
+		priced.Put(tx)
+	}
+
+	// Transactions are only underpriced if cheaper than the cheapest in gold
+	if priced.Underpriced(currencyTransaction(5, 11, nil, key), newAccountSet(types.HomesteadSigner{})) {
+		t.Errorf("transaction pricier than the cheapest reported underpriced")
+	}
+	if !priced.Underpriced(currencyTransaction(5, 5, currency, key), newAccountSet(types.HomesteadSigner{})) {
+		t.Errorf("transaction as cheap as the cheapest not reported underpriced")
+	}
+
+	drop := priced.Discard(len(txs), newAccountSet(types.HomesteadSigner{}))
+	want := []uint64{4, 1, 2, 0, 3}
+	if len(drop) != len(want) {
+		t.Fatalf("discarded count mismatch: have %d, want %d", len(drop), len(want))
+	}
+	for i, tx := range drop {
+		if tx.Nonce() != want[i] {
+			t.Errorf("discarded transaction %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), want[i])
+		}
+	}
+}
@@ -81,6 +81,15 @@ var (
 
 	// ErrNonWhitelistedGasCurrency is returned if the txn gas currency is not white listed
 	ErrNonWhitelistedGasCurrency = errors.New("non-whitelisted gas currency")
+
+	// ErrAccountLimit is returned if the sender of a transaction already has the
+	// maximum number of transactions permitted per account in the pool.
+	ErrAccountLimit = errors.New("account transaction limit reached")
+
+	// ErrCurrencyLimit is returned if the pool already holds the maximum number of
+	// transactions permitted in the gas currency of a transaction, and all of them
+	// are priced higher.
+	ErrCurrencyLimit = errors.New("gas currency transaction limit reached")
 )
 
 var (
@@ -104,6 +113,11 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+
+	// Quota metrics
+	accountLimitCounter  = metrics.NewRegisteredCounter("txpool/accountlimit", nil)  // Rejected due to the account quota
+	currencyLimitCounter = metrics.NewRegisteredCounter("txpool/currencylimit", nil) // Rejected or discarded due to a currency quota
+	delistedTxCounter    = metrics.NewRegisteredCounter("txpool/delisted", nil)      // Dropped due to their currency leaving the whitelist
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -131,6 +145,9 @@ type blockChain interface {
 	// GetHeader returns the header corresponding to their hash.
 	GetHeader(common.Hash, uint64) *types.Header
 
+	// GetReceiptsByHash retrieves the receipts of the block with the given hash.
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+
 	GetVMConfig() *vm.Config
 }
 
@@ -151,6 +168,10 @@ type TxPoolConfig struct {
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 
+	AccountLimit   uint64                    // Maximum number of executable and non-executable transactions permitted per account
+	CurrencySlots  uint64                    // Maximum number of transactions permitted per non-gold gas currency
+	CurrencyLimits map[common.Address]uint64 `toml:",omitempty"` // Gas currencies permitted a different number of transactions than CurrencySlots
+
 	CurrencyAddresses *[]common.Address // The addresses of all the currencies that are accepted by the node
 }
 
@@ -169,6 +190,9 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	AccountLimit:  1024,
+	CurrencySlots: 2048,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -207,9 +231,26 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.AccountLimit < 1 {
+		log.Warn("Sanitizing invalid txpool account limit", "provided", conf.AccountLimit, "updated", DefaultTxPoolConfig.AccountLimit)
+		conf.AccountLimit = DefaultTxPoolConfig.AccountLimit
+	}
+	if conf.CurrencySlots < 1 {
+		log.Warn("Sanitizing invalid txpool currency slots", "provided", conf.CurrencySlots, "updated", DefaultTxPoolConfig.CurrencySlots)
+		conf.CurrencySlots = DefaultTxPoolConfig.CurrencySlots
+	}
 	return conf
 }
 
+// currencyLimit returns the maximum number of transactions permitted in the
+// given gas currency.
+func (config *TxPoolConfig) currencyLimit(currency common.Address) uint64 {
+	if limit, ok := config.CurrencyLimits[currency]; ok {
+		return limit
+	}
+	return config.CurrencySlots
+}
+
 // TxPool contains all currently known transactions. Transactions
 // enter the pool when they are received from the network or submitted
 // locally. They exit the pool when they are included in the blockchain.
@@ -229,6 +270,7 @@ type TxPool struct {
 	signer       types.Signer
 	mu           sync.RWMutex
 
+	currentHead   *types.Header       // Current head of the blockchain
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
@@ -241,6 +283,9 @@ type TxPool struct {
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price.  One heap per gas currency.
+	pricer  *txPricer                    // Gas price comparator, using the exchange rates of the current head
+
+	currencies *currencyCache // Gas currency whitelist and balances at the current head
 
 	wg sync.WaitGroup // for shutdown sync
 
@@ -278,7 +323,12 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
-	pool.priced = newTxPricedList(pool.all, pool.co)
+	pool.pricer = newTxPricer(pool.co)
+	pool.currencies = newCurrencyCache(func(account, currency common.Address) (*big.Int, error) {
+		balance, _, err := GetBalanceOf(account, currency, pool.iEvmH, nil, params.MaxGasToReadErc20Balance)
+		return balance, err
+	})
+	pool.priced = newTxPricedList(pool.all, pool.pricer)
 
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -311,6 +361,7 @@ func (pool *TxPool) loop() {
 
 	// Start the stats reporting and transaction eviction tickers
 	var prevPending, prevQueued, prevStales int
+	var prevCurrencies map[common.Address]*CurrencyStats
 
 	report := time.NewTicker(statsReportInterval)
 	defer report.Stop()
@@ -354,6 +405,14 @@ func (pool *TxPool) loop() {
 				log.Debug("Transaction pool status report", "executable", pending, "queued", queued, "stales", stales)
 				prevPending, prevQueued, prevStales = pending, queued, stales
 			}
+			if metrics.Enabled {
+				pool.mu.RLock()
+				currencies := pool.currencyStats()
+				pool.mu.RUnlock()
+
+				updateCurrencyGauges(currencies, prevCurrencies)
+				prevCurrencies = currencies
+			}
 
 		// Handle inactive account transaction eviction
 		case <-evict.C:
@@ -453,10 +512,16 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHead = newHead
+	pool.pricer.setHeader(newHead)
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
 
+	// Update the gas currency whitelist and balances, dropping the transactions
+	// paying for gas in currencies no longer whitelisted
+	pool.updateCurrencies(oldHead, newHead, statedb)
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
@@ -551,6 +616,65 @@ func (pool *TxPool) stats() (int, int) {
 	return pending, queued
 }
 
+// CurrencyStats counts the pending and queued transactions paying for gas in a
+// currency.
+type CurrencyStats struct {
+	Pending int
+	Queued  int
+}
+
+// CurrencyStats retrieves the number of pending and queued transactions paying
+// for gas in each currency, gold being keyed by the zero address.
+func (pool *TxPool) CurrencyStats() map[common.Address]*CurrencyStats {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.currencyStats()
+}
+
+// currencyStats retrieves the number of pending and queued transactions paying
+// for gas in each currency, gold being keyed by the zero address.
+func (pool *TxPool) currencyStats() map[common.Address]*CurrencyStats {
+	stats := make(map[common.Address]*CurrencyStats)
+	count := func(lists map[common.Address]*txList, pending bool) {
+		for _, list := range lists {
+			for _, tx := range list.txs.items {
+				var currency common.Address
+				if tx.GasCurrency() != nil {
+					currency = *tx.GasCurrency()
+				}
+				if stats[currency] == nil {
+					stats[currency] = new(CurrencyStats)
+				}
+				if pending {
+					stats[currency].Pending++
+				} else {
+					stats[currency].Queued++
+				}
+			}
+		}
+	}
+	count(pool.pending, true)
+	count(pool.queue, false)
+	return stats
+}
+
+// updateCurrencyGauges reports the number of pending and queued transactions in
+// each gas currency, and unregisters the gauges of the currencies previously
+// reported that the pool no longer holds transactions in.
+func updateCurrencyGauges(currencies, prev map[common.Address]*CurrencyStats) {
+	for currency, stats := range currencies {
+		metrics.GetOrRegisterGauge("txpool/currency/"+currency.Hex()+"/pending", nil).Update(int64(stats.Pending))
+		metrics.GetOrRegisterGauge("txpool/currency/"+currency.Hex()+"/queued", nil).Update(int64(stats.Queued))
+	}
+	for currency := range prev {
+		if currencies[currency] == nil {
+			metrics.Unregister("txpool/currency/" + currency.Hex() + "/pending")
+			metrics.Unregister("txpool/currency/" + currency.Hex() + "/queued")
+		}
+	}
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
@@ -630,14 +754,13 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	}
 
 	if tx.GasCurrency() != nil && // Non native gas in the tx
-		(pool.gcWl == nil ||
-			!pool.gcWl.IsWhitelisted(*tx.GasCurrency())) { // The tx currency is not white listed
+		!pool.currencies.isWhitelisted(*tx.GasCurrency()) { // The tx currency is not white listed
 		return ErrNonWhitelistedGasCurrency
 	}
 
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !local && pool.co.Cmp(pool.gasPrice, nil, tx.GasPrice(), tx.GasCurrency()) > 0 {
+	if !local && pool.pricer.cmp(pool.gasPrice, nil, tx.GasPrice(), tx.GasCurrency()) > 0 {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
@@ -648,7 +771,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		log.Debug("validateTx insufficient funds", "balance", pool.currentState.GetBalance(from).String(), "from", from.Hex(), "txn cost", tx.Cost().String())
 		return ErrInsufficientFunds
 	} else if tx.GasCurrency() != nil {
-		gasCurrencyBalance, _, err := GetBalanceOf(from, *tx.GasCurrency(), pool.iEvmH, nil, params.MaxGasToReadErc20Balance)
+		gasCurrencyBalance, err := pool.currencies.balance(from, *tx.GasCurrency())
 
 		if err != nil {
 			log.Debug("validateTx error in getting gas currency balance", "gasCurrency", tx.GasCurrency(), "error", err)
@@ -698,6 +821,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// If the transaction exceeds the quotas of its sender or gas currency, discard it
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	quotas := !local && !pool.locals.contains(from)
+	if quotas {
+		if err := pool.checkQuotas(from, tx); err != nil {
+			log.Debug("Discarding transaction over quota", "hash", hash, "from", from, "currency", tx.GasCurrency(), "err", err)
+			return false, err
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -714,11 +846,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
+	// Make room for the transaction in its gas currency quota, unless it fails to
+	// replace the transaction of the same nonce and is discarded below
+	if quotas && pool.replaceable(from, tx) {
+		pool.makeCurrencyRoom(from, tx)
+	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := list.Add(tx, pool.config.PriceBump, pool.pricer)
 		if !inserted {
 			pendingDiscardCounter.Inc(1)
 			return false, ErrReplaceUnderpriced
@@ -758,6 +894,200 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	return replace, nil
 }
 
+// checkQuotas checks that a transaction fits in the quotas of its sender and of
+// its gas currency, which don't apply to transactions replacing one of the same
+// sender and currency. A transaction over its currency quota fits if it is
+// pricier than the cheapest transaction in the currency, and enough remote ones
+// can be discarded to make room for it, see makeCurrencyRoom.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) checkQuotas(from common.Address, tx *types.Transaction) error {
+	old := pool.replacedTx(from, tx)
+	if old == nil {
+		var count uint64
+		if list := pool.pending[from]; list != nil {
+			count += uint64(list.Len())
+		}
+		if list := pool.queue[from]; list != nil {
+			count += uint64(list.Len())
+		}
+		if count >= pool.config.AccountLimit {
+			accountLimitCounter.Inc(1)
+			return ErrAccountLimit
+		}
+	}
+	currency := quotaCurrency(tx, old)
+	if currency == nil {
+		return nil
+	}
+	limit, count := pool.config.currencyLimit(*currency), pool.all.CurrencyCount(currency)
+	if count < limit {
+		return nil
+	}
+	if pool.priced.CurrencyUnderpriced(tx, pool.locals) || !pool.priced.CurrencyDiscardable(*currency, int(count-limit+1), pool.locals) {
+		currencyLimitCounter.Inc(1)
+		return ErrCurrencyLimit
+	}
+	return nil
+}
+
+// makeCurrencyRoom discards the cheapest remote transactions paying for gas in
+// the currency of a transaction over the currency quota, once checkQuotas
+// admitted it.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) makeCurrencyRoom(from common.Address, tx *types.Transaction) {
+	currency := quotaCurrency(tx, pool.replacedTx(from, tx))
+	if currency == nil {
+		return
+	}
+	limit, count := pool.config.currencyLimit(*currency), pool.all.CurrencyCount(currency)
+	if count < limit {
+		return
+	}
+	for _, tx := range pool.priced.DiscardCurrency(*currency, int(count-limit+1), pool.locals) {
+		log.Debug("Discarding transaction over currency quota", "hash", tx.Hash(), "currency", currency, "price", tx.GasPrice())
+		currencyLimitCounter.Inc(1)
+		pool.removeTx(tx.Hash(), false)
+	}
+}
+
+// replacedTx returns the pending or queued transaction of the sender with the
+// nonce of the given one, if any.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) replacedTx(from common.Address, tx *types.Transaction) *types.Transaction {
+	if list := pool.pending[from]; list != nil {
+		if old := list.txs.Get(tx.Nonce()); old != nil {
+			return old
+		}
+	}
+	if list := pool.queue[from]; list != nil {
+		return list.txs.Get(tx.Nonce())
+	}
+	return nil
+}
+
+// replaceable checks whether a transaction would be added to the pending or
+// queued transactions of its sender, which it isn't if it replaces one without
+// the required price bump.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) replaceable(from common.Address, tx *types.Transaction) bool {
+	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
+		return list.Replaceable(tx, pool.config.PriceBump, pool.pricer)
+	}
+	if list := pool.queue[from]; list != nil {
+		return list.Replaceable(tx, pool.config.PriceBump, pool.pricer)
+	}
+	return true
+}
+
+// quotaCurrency returns the gas currency whose quota a transaction counts
+// against, which is none if it pays for gas in gold or replaces a transaction in
+// the same currency.
+func quotaCurrency(tx, old *types.Transaction) *common.Address {
+	currency := tx.GasCurrency()
+	if currency == nil || old != nil && old.GasCurrency() != nil && *old.GasCurrency() == *currency {
+		return nil
+	}
+	return currency
+}
+
+// updateCurrencies refreshes the gas currency whitelist at the new head, dropping
+// the transactions paying for gas in currencies no longer whitelisted, and
+// invalidates the cached balances that the new blocks may have changed. The
+// cached balances are cleared on reorgs, and if the receipts of a new block are
+// missing.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) updateCurrencies(oldHead, newHead *types.Header, statedb *state.StateDB) {
+	pool.currencies.setHead(newHead.Number.Uint64())
+	if pool.gcWl != nil {
+		if whitelist, err := pool.gcWl.WhitelistAtStateAndHeader(statedb, newHead); err != nil {
+			log.Debug("Failed to retrieve gas currency whitelist", "number", newHead.Number, "err", err)
+		} else {
+			pool.currencies.setWhitelist(whitelist)
+			if pool.all.HasCurrencyTxs() {
+				pool.purgeCurrencies(pool.currencies.whitelist)
+			}
+		}
+	}
+
+	// Gather the blocks added on top of the old head
+	if oldHead == nil || len(pool.currencies.balances) > currencyCacheLimit {
+		pool.currencies.clear()
+		return
+	}
+	var blocks []*types.Block
+	for hash, number := newHead.Hash(), newHead.Number.Uint64(); hash != oldHead.Hash(); {
+		if len(blocks) == currencyCacheDepth || number <= oldHead.Number.Uint64() {
+			pool.currencies.clear()
+			return
+		}
+		block := pool.chain.GetBlock(hash, number)
+		if block == nil {
+			pool.currencies.clear()
+			return
+		}
+		blocks = append(blocks, block)
+		hash, number = block.ParentHash(), number-1
+	}
+	// Gas fees are credited to the infrastructure fund without Transfer logs
+	var infra *common.Address
+	if pool.iEvmH != nil && pool.iEvmH.regAdd != nil {
+		infra, _ = pool.iEvmH.regAdd.GetRegisteredAddressAtStateAndHeader(params.GovernanceRegistryId, statedb, newHead)
+	}
+	for _, block := range blocks {
+		receipts := pool.chain.GetReceiptsByHash(block.Hash())
+		if receipts == nil {
+			pool.currencies.clear()
+			return
+		}
+		pool.currencies.invalidate(block, receipts, pool.signer, infra)
+	}
+}
+
+// gasCurrencyBalance returns a function retrieving the balances of the account in
+// gas currencies, zero for the currencies not whitelisted and nil for the balances
+// that can't be retrieved.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) gasCurrencyBalance(addr common.Address) func(currency common.Address) *big.Int {
+	return func(currency common.Address) *big.Int {
+		if !pool.currencies.isWhitelisted(currency) {
+			return common.Big0
+		}
+		balance, err := pool.currencies.balance(addr, currency)
+		if err != nil {
+			log.Debug("Failed to retrieve gas currency balance", "account", addr, "currency", currency, "err", err)
+			return nil
+		}
+		return balance
+	}
+}
+
+// purgeCurrencies drops the transactions paying for gas in currencies missing
+// from the given whitelist.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) purgeCurrencies(whitelisted map[common.Address]bool) {
+	var purged []common.Hash
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction) bool {
+		if currency := tx.GasCurrency(); currency != nil && !whitelisted[*currency] {
+			purged = append(purged, hash)
+		}
+		return true
+	})
+	for _, hash := range purged {
+		if tx := pool.all.Get(hash); tx != nil {
+			log.Debug("Discarding transaction in delisted gas currency", "hash", hash, "currency", tx.GasCurrency())
+			delistedTxCounter.Inc(1)
+			pool.removeTx(hash, true)
+		}
+	}
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -767,7 +1097,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump, pool.pricer)
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardCounter.Inc(1)
@@ -809,7 +1139,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
+	inserted, old := list.Add(tx, pool.config.PriceBump, pool.pricer)
 	if !inserted {
 		// An older transaction was better, discard this
 		pool.all.Remove(hash)
@@ -1012,9 +1342,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
-		// Drop all transactions that are too costly (low balance or out of gas)
+		// Drop all transactions that are too costly (low balance, low gas currency balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
-		for _, tx := range drops {
+		currencyDrops, _ := list.FilterGasCurrencies(pool.gasCurrencyBalance(addr))
+		for _, tx := range append(drops, currencyDrops...) {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable queued transaction", "hash", hash)
 			pool.all.Remove(hash)
@@ -1176,8 +1507,10 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
-		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
+		// Drop all transactions that are too costly (low balance, low gas currency balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		currencyDrops, currencyInvalids := list.FilterGasCurrencies(pool.gasCurrencyBalance(addr))
+		drops, invalids = append(drops, currencyDrops...), append(invalids, currencyInvalids...)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -1321,6 +1654,27 @@ func (t *txLookup) Count() int {
 	return len(t.all)
 }
 
+// CurrencyCount returns the number of transactions paying for gas in the given
+// currency, nil standing for gold.
+func (t *txLookup) CurrencyCount(currency *common.Address) uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if currency == nil {
+		return t.nilCurrencyTxCurrCount
+	}
+	return t.nonNilCurrencyTxCurrCount[*currency]
+}
+
+// HasCurrencyTxs returns whether the lookup contains transactions paying for gas
+// in other currencies than gold.
+func (t *txLookup) HasCurrencyTxs() bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.all) > int(t.nilCurrencyTxCurrCount)
+}
+
 // Add adds a transaction to the lookup.
 func (t *txLookup) Add(tx *types.Transaction) {
 	t.lock.Lock()
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -83,6 +84,10 @@ func (bc *testBlockChain) GetVMConfig() *vm.Config {
 	return nil
 }
 
+func (bc *testBlockChain) GetReceiptsByHash(common.Hash) types.Receipts {
+	return nil
+}
+
 func transaction(nonce uint64, gaslimit uint64, key *ecdsa.PrivateKey) *types.Transaction {
 	return pricedTransaction(nonce, gaslimit, big.NewInt(1), key)
 }
@@ -97,7 +102,7 @@ func setupTxPool() (*TxPool, *ecdsa.PrivateKey) {
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
 	key, _ := crypto.GenerateKey()
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 
 	return pool, key
@@ -208,7 +213,7 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T) {
 	tx0 := transaction(0, 100000, key)
 	tx1 := transaction(1, 100000, key)
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -574,7 +579,7 @@ func TestTransactionPostponing(t *testing.T) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -794,7 +799,7 @@ func testTransactionQueueGlobalLimiting(t *testing.T, nolocals bool) {
 	config.NoLocals = nolocals
 	config.GlobalQueue = config.AccountQueue*3 - 1 // reduce the queue limits to shorten test time (-1 to make it non divisible)
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -883,7 +888,7 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals bool) {
 	config.Lifetime = time.Second
 	config.NoLocals = nolocals
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1037,7 +1042,7 @@ func TestTransactionPendingGlobalLimiting(t *testing.T) {
 	config := testTxPoolConfig
 	config.GlobalSlots = config.AccountSlots * 10
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1086,7 +1091,7 @@ func TestTransactionCapClearsFromAll(t *testing.T) {
 	config.AccountQueue = 2
 	config.GlobalSlots = 8
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1119,7 +1124,7 @@ func TestTransactionPendingMinimumAllowance(t *testing.T) {
 	config := testTxPoolConfig
 	config.GlobalSlots = 1
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1165,7 +1170,7 @@ func TestTransactionPoolRepricing(t *testing.T) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1287,7 +1292,7 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1354,7 +1359,7 @@ func TestTransactionPoolUnderpricing(t *testing.T) {
 	config.GlobalSlots = 2
 	config.GlobalQueue = 2
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1461,7 +1466,7 @@ func TestTransactionPoolStableUnderpricing(t *testing.T) {
 	config.GlobalSlots = 128
 	config.GlobalQueue = 0
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1524,7 +1529,7 @@ func TestTransactionReplacement(t *testing.T) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
@@ -1595,6 +1600,178 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that the number of transactions of an account is capped, except for
+// replacements and local transactions.
+func TestTransactionAccountLimit(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountLimit = 3
+
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Pending and queued transactions both count towards the limit
+	for _, nonce := range []uint64{0, 1, 3} {
+		if err := pool.AddRemote(pricedTransaction(nonce, 100000, big.NewInt(1), key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(pricedTransaction(2, 100000, big.NewInt(1), key)); err != ErrAccountLimit {
+		t.Fatalf("transaction over limit error mismatch: have %v, want %v", err, ErrAccountLimit)
+	}
+	if err := pool.AddRemote(pricedTransaction(3, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("failed to replace transaction at the limit: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(2, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add local transaction over limit: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d pending, %d queued, want 4, 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the number of transactions paying for gas in a currency is capped,
+// the cheapest ones making room for pricier ones.
+func TestTransactionCurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	currency, other := common.HexToAddress("0xc0ffee"), common.HexToAddress("0xdecaf")
+	config := testTxPoolConfig
+	config.CurrencySlots = 16
+	config.CurrencyLimits = map[common.Address]uint64{currency: 2}
+
+	co := NewCurrencyOperator(nil, nil, nil, nil)
+	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	cheap, pricy := currencyTransaction(0, 10, &currency, keys[0]), currencyTransaction(0, 20, &currency, keys[1])
+	pool.enqueueTx(cheap.Hash(), cheap)
+	pool.enqueueTx(pricy.Hash(), pricy)
+
+	from := crypto.PubkeyToAddress(keys[2].PublicKey)
+	if err := pool.checkQuotas(from, currencyTransaction(0, 10, &currency, keys[2])); err != ErrCurrencyLimit {
+		t.Fatalf("underpriced transaction over limit error mismatch: have %v, want %v", err, ErrCurrencyLimit)
+	}
+	// Other currencies and gold are not affected by the limit
+	if err := pool.checkQuotas(from, currencyTransaction(0, 1, &other, keys[2])); err != nil {
+		t.Fatalf("transaction in other currency rejected: %v", err)
+	}
+	if err := pool.checkQuotas(from, currencyTransaction(0, 1, nil, keys[2])); err != nil {
+		t.Fatalf("gold transaction rejected: %v", err)
+	}
+	// Replacements in the same currency don't count towards the limit
+	replacement := currencyTransaction(0, 11, &currency, keys[0])
+	if err := pool.checkQuotas(crypto.PubkeyToAddress(keys[0].PublicKey), replacement); err != nil {
+		t.Fatalf("replacement rejected: %v", err)
+	}
+	pool.makeCurrencyRoom(crypto.PubkeyToAddress(keys[0].PublicKey), replacement)
+	if pool.all.Get(cheap.Hash()) == nil {
+		t.Fatalf("transaction discarded by replacement")
+	}
+	// Replacements without a price bump are rejected before making room
+	if pool.replaceable(crypto.PubkeyToAddress(keys[1].PublicKey), currencyTransaction(0, 20, &currency, keys[1])) {
+		t.Fatalf("replacement without price bump accepted")
+	}
+	if !pool.replaceable(crypto.PubkeyToAddress(keys[1].PublicKey), currencyTransaction(0, 30, &currency, keys[1])) {
+		t.Fatalf("replacement with price bump rejected")
+	}
+	// Pricier transactions make room for themselves, once admitted
+	pricier := currencyTransaction(0, 15, &currency, keys[2])
+	if err := pool.checkQuotas(from, pricier); err != nil {
+		t.Fatalf("pricier transaction over limit rejected: %v", err)
+	}
+	if pool.all.Get(cheap.Hash()) == nil {
+		t.Fatalf("transaction discarded before admission")
+	}
+	pool.makeCurrencyRoom(from, pricier)
+	if pool.all.Get(cheap.Hash()) != nil || pool.all.Get(pricy.Hash()) == nil {
+		t.Fatalf("cheapest transaction not discarded")
+	}
+	if stats := pool.CurrencyStats(); len(stats) != 1 || stats[currency].Queued != 1 {
+		t.Fatalf("currency stats mismatch: have %v", stats)
+	}
+	// Local transactions are never discarded to make room
+	local := currencyTransaction(0, 30, &currency, keys[3])
+	pool.enqueueTx(local.Hash(), local)
+	pool.locals.add(crypto.PubkeyToAddress(keys[1].PublicKey))
+	pool.locals.add(crypto.PubkeyToAddress(keys[3].PublicKey))
+	if err := pool.checkQuotas(from, currencyTransaction(0, 40, &currency, keys[2])); err != ErrCurrencyLimit {
+		t.Fatalf("transaction over limit of local transactions error mismatch: have %v, want %v", err, ErrCurrencyLimit)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the gauges of the gas currencies the pool no longer holds
+// transactions in are unregistered.
+func TestUpdateCurrencyGauges(t *testing.T) {
+	kept, gone := common.HexToAddress("0xc0ffee"), common.HexToAddress("0xdecaf")
+	prev := map[common.Address]*CurrencyStats{kept: {Pending: 1}, gone: {Queued: 2}}
+	updateCurrencyGauges(prev, nil)
+	updateCurrencyGauges(map[common.Address]*CurrencyStats{kept: {Pending: 3}}, prev)
+
+	for _, name := range []string{"pending", "queued"} {
+		if metrics.Get("txpool/currency/"+kept.Hex()+"/"+name) == nil {
+			t.Errorf("%s gauge of remaining currency not registered", name)
+		}
+		if metrics.Get("txpool/currency/"+gone.Hex()+"/"+name) != nil {
+			t.Errorf("%s gauge of gone currency still registered", name)
+		}
+	}
+}
+
+// Tests that the transactions paying for gas in currencies leaving the whitelist
+// are dropped.
+func TestTransactionPurgeDelistedCurrencies(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	listed, delisted := common.HexToAddress("0xc0ffee"), common.HexToAddress("0xdecaf")
+	txs := types.Transactions{
+		currencyTransaction(0, 1, &listed, key),
+		currencyTransaction(1, 1, &delisted, key),
+		currencyTransaction(2, 1, nil, key),
+		currencyTransaction(3, 1, &delisted, key),
+	}
+	for _, tx := range txs {
+		pool.enqueueTx(tx.Hash(), tx)
+	}
+	pool.purgeCurrencies(map[common.Address]bool{listed: true})
+
+	for i, tx := range txs {
+		if kept := pool.all.Get(tx.Hash()) != nil; kept == (i%2 == 1) {
+			t.Errorf("transaction %d: kept mismatch: have %v, want %v", i, kept, i%2 == 0)
+		}
+	}
+	if !pool.all.HasCurrencyTxs() || pool.all.CurrencyCount(&delisted) != 0 {
+		t.Errorf("currency counts mismatch")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1624,7 +1801,7 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	config.Journal = journal
 	config.Rejournal = time.Second
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 
 	// Create two test accounts to ensure remotes expire but locals do not
@@ -1662,7 +1839,7 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	statedb.SetNonce(crypto.PubkeyToAddress(local.PublicKey), 1)
 	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co = NewCurrencyOperator(nil, nil, nil)
+	co = NewCurrencyOperator(nil, nil, nil, nil)
 	pool = NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 
 	pending, queued = pool.Stats()
@@ -1689,7 +1866,7 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 
 	statedb.SetNonce(crypto.PubkeyToAddress(local.PublicKey), 1)
 	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
-	co = NewCurrencyOperator(nil, nil, nil)
+	co = NewCurrencyOperator(nil, nil, nil, nil)
 	pool = NewTxPool(config, params.TestChainConfig, blockchain, co, nil, nil)
 
 	pending, queued = pool.Stats()
@@ -1720,7 +1897,7 @@ func TestTransactionStatusCheck(t *testing.T) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
 
-	co := NewCurrencyOperator(nil, nil, nil)
+	co := NewCurrencyOperator(nil, nil, nil, nil)
 	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, co, nil, nil)
 	defer pool.Stop()
 
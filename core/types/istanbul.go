@@ -19,6 +19,7 @@ package types
 import (
 	"errors"
 	"io"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -36,21 +37,42 @@ var (
 	ErrInvalidIstanbulHeaderExtra = errors.New("invalid istanbul header extra-data")
 )
 
+// IstanbulAggregatedSeal is the aggregated BLS signature of the validators that
+// committed to a block. Bit i of the bitmap is set if the i-th validator of the
+// parent's validator set signed.
+type IstanbulAggregatedSeal struct {
+	Bitmap    *big.Int
+	Signature []byte
+}
+
 type IstanbulExtra struct {
 	AddedValidators   []common.Address
 	RemovedValidators []common.Address
 	Seal              []byte
 	CommittedSeal     [][]byte
+
+	// Only used by chains aggregating committed seals, and then left out of the
+	// encoding if empty so that other chains' headers are unaffected.
+	AddedValidatorsPublicKeys [][]byte
+	AggregatedSeal            *IstanbulAggregatedSeal
 }
 
 // EncodeRLP serializes ist into the Ethereum RLP format.
 func (ist *IstanbulExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	fields := []interface{}{
 		ist.AddedValidators,
 		ist.RemovedValidators,
 		ist.Seal,
 		ist.CommittedSeal,
-	})
+	}
+	if len(ist.AddedValidatorsPublicKeys) > 0 || ist.AggregatedSeal != nil {
+		aggregatedSeal := ist.AggregatedSeal
+		if aggregatedSeal == nil {
+			aggregatedSeal = &IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}}
+		}
+		fields = append(fields, ist.AddedValidatorsPublicKeys, aggregatedSeal)
+	}
+	return rlp.Encode(w, fields)
 }
 
 // DecodeRLP implements rlp.Decoder, and load the istanbul fields from a RLP stream.
@@ -60,12 +82,26 @@ func (ist *IstanbulExtra) DecodeRLP(s *rlp.Stream) error {
 		RemovedValidators []common.Address
 		Seal              []byte
 		CommittedSeal     [][]byte
+		Rest              []rlp.RawValue `rlp:"tail"`
 	}
 	if err := s.Decode(&istanbulExtra); err != nil {
 		return err
 	}
 	ist.AddedValidators, ist.RemovedValidators, ist.Seal, ist.CommittedSeal = istanbulExtra.AddedValidators, istanbulExtra.RemovedValidators, istanbulExtra.Seal, istanbulExtra.CommittedSeal
-	return nil
+	ist.AddedValidatorsPublicKeys, ist.AggregatedSeal = nil, nil
+
+	switch len(istanbulExtra.Rest) {
+	case 0:
+		return nil
+	case 2:
+		if err := rlp.DecodeBytes(istanbulExtra.Rest[0], &ist.AddedValidatorsPublicKeys); err != nil {
+			return err
+		}
+		ist.AggregatedSeal = new(IstanbulAggregatedSeal)
+		return rlp.DecodeBytes(istanbulExtra.Rest[1], ist.AggregatedSeal)
+	default:
+		return ErrInvalidIstanbulHeaderExtra
+	}
 }
 
 // ExtractIstanbulExtra extracts all values of the IstanbulExtra from the header. It returns an
@@ -98,6 +134,7 @@ func IstanbulFilteredHeader(h *Header, keepSeal bool) *Header {
 		istanbulExtra.Seal = []byte{}
 	}
 	istanbulExtra.CommittedSeal = [][]byte{}
+	istanbulExtra.AggregatedSeal = nil
 
 	payload, err := rlp.EncodeToBytes(&istanbulExtra)
 	if err != nil {
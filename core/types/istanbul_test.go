@@ -18,11 +18,13 @@ package types
 
 import (
 	"bytes"
+	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestHeaderHash(t *testing.T) {
@@ -88,3 +90,35 @@ func TestExtractToIstanbul(t *testing.T) {
 		}
 	}
 }
+
+func TestIstanbulExtraAggregatedSeal(t *testing.T) {
+	extra := &IstanbulExtra{
+		AddedValidators:           []common.Address{common.HexToAddress("0x44add0ec310f115a0e603b2d7db9f067778eaf8a")},
+		AddedValidatorsPublicKeys: [][]byte{bytes.Repeat([]byte{0x01}, 128)},
+		RemovedValidators:         []common.Address{},
+		Seal:                      []byte{0x02},
+		CommittedSeal:             [][]byte{},
+		AggregatedSeal: &IstanbulAggregatedSeal{
+			Bitmap:    big.NewInt(13),
+			Signature: bytes.Repeat([]byte{0x03}, 64),
+		},
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatalf("failed to encode istanbul extra: %v", err)
+	}
+	h := &Header{Extra: append(bytes.Repeat([]byte{0x00}, IstanbulExtraVanity), payload...)}
+	decoded, err := ExtractIstanbulExtra(h)
+	if err != nil {
+		t.Fatalf("failed to extract istanbul extra: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, extra) {
+		t.Errorf("expected: %v, but got: %v", extra, decoded)
+	}
+
+	// The aggregated seal is not part of the header hash
+	filtered := IstanbulFilteredHeader(h, true)
+	if decoded, _ := ExtractIstanbulExtra(filtered); decoded.AggregatedSeal.Bitmap.Sign() != 0 || len(decoded.AggregatedSeal.Signature) != 0 {
+		t.Errorf("aggregated seal not filtered: %v", decoded.AggregatedSeal)
+	}
+}
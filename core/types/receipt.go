@@ -63,6 +63,7 @@ type Receipt struct {
 
 	// Celo fields
 	AttestationRequests []AttestationRequest
+	TobinTax            *big.Int `json:"tobinTax"` // Total tobin tax taken from the value transfers
 
 	// Implementation fields (don't reorder!)
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
@@ -75,6 +76,7 @@ type receiptMarshaling struct {
 	Status            hexutil.Uint64
 	CumulativeGasUsed hexutil.Uint64
 	GasUsed           hexutil.Uint64
+	TobinTax          *hexutil.Big
 }
 
 // receiptRLP is the consensus encoding of a receipt.
@@ -94,6 +96,22 @@ type receiptStorageRLP struct {
 	Logs              []*LogForStorage
 	GasUsed           uint64
 
+	// Celo fields
+	AttestationRequests []AttestationRequest
+	TobinTax            *big.Int
+}
+
+// legacyReceiptStorageRLP is the storage encoding of a receipt written before
+// the tobin tax was recorded.
+type legacyReceiptStorageRLP struct {
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Bloom             Bloom
+	TxHash            common.Hash
+	ContractAddress   common.Address
+	Logs              []*LogForStorage
+	GasUsed           uint64
+
 	// Celo fields
 	AttestationRequests []AttestationRequest
 }
@@ -211,6 +229,7 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 		Logs:                make([]*LogForStorage, len(r.Logs)),
 		GasUsed:             r.GasUsed,
 		AttestationRequests: r.AttestationRequests,
+		TobinTax:            r.TobinTax,
 	}
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
@@ -221,10 +240,28 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 // DecodeRLP implements rlp.Decoder, and loads both consensus and implementation
 // fields of a receipt from an RLP stream.
 func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
-	var dec receiptStorageRLP
-	if err := s.Decode(&dec); err != nil {
+	blob, err := s.Raw()
+	if err != nil {
 		return err
 	}
+	var dec receiptStorageRLP
+	if err := rlp.DecodeBytes(blob, &dec); err != nil {
+		// Receipts stored before the tobin tax was recorded lack it
+		var legacy legacyReceiptStorageRLP
+		if rlp.DecodeBytes(blob, &legacy) != nil {
+			return err
+		}
+		dec = receiptStorageRLP{
+			PostStateOrStatus:   legacy.PostStateOrStatus,
+			CumulativeGasUsed:   legacy.CumulativeGasUsed,
+			Bloom:               legacy.Bloom,
+			TxHash:              legacy.TxHash,
+			ContractAddress:     legacy.ContractAddress,
+			Logs:                legacy.Logs,
+			GasUsed:             legacy.GasUsed,
+			AttestationRequests: legacy.AttestationRequests,
+		}
+	}
 	if err := (*Receipt)(r).setStatus(dec.PostStateOrStatus); err != nil {
 		return err
 	}
@@ -239,7 +276,7 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	r.TxHash, r.ContractAddress, r.GasUsed = dec.TxHash, dec.ContractAddress, dec.GasUsed
 
 	// Assign the celo fields
-	r.AttestationRequests = dec.AttestationRequests
+	r.AttestationRequests, r.TobinTax = dec.AttestationRequests, dec.TobinTax
 	return nil
 }
 
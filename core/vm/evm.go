@@ -148,6 +148,18 @@ type EVM struct {
 	// Maintains a queue of Celo attestation requests
 	// TODO(asa): Save this in StateDB
 	AttestationRequests []types.AttestationRequest
+	// Tobin taxes taken by the call frames that have not been reverted
+	TobinTaxes []TobinTax
+}
+
+// TobinTax is the tax taken by the reserve from a single value transfer.
+type TobinTax struct {
+	From    common.Address // Sender of the transfer
+	To      common.Address // Recipient of the transfer
+	Reserve common.Address // Reserve the tax was transferred to
+	Amount  *big.Int       // Amount sent, including the tax
+	Tax     *big.Int       // Part of the amount transferred to the reserve
+	Depth   int            // Depth of the call frame making the transfer
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -218,6 +230,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
+		taxes    = len(evm.TobinTaxes)
 	)
 	if !evm.StateDB.Exist(addr) {
 		precompiles := PrecompiledContractsHomestead
@@ -262,6 +275,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.TobinTaxes = evm.TobinTaxes[:taxes]
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -292,6 +306,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
+		taxes    = len(evm.TobinTaxes)
 		to       = AccountRef(caller.Address())
 	)
 	// initialise a new contract and set the code that is to be used by the
@@ -303,6 +318,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.TobinTaxes = evm.TobinTaxes[:taxes]
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -326,6 +342,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
+		taxes    = len(evm.TobinTaxes)
 		to       = AccountRef(caller.Address())
 	)
 
@@ -336,6 +353,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.TobinTaxes = evm.TobinTaxes[:taxes]
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -359,6 +377,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
+		taxes    = len(evm.TobinTaxes)
 	)
 	// Initialise a new contract and set the code that is to be used by the
 	// EVM. The contract is a scoped environment for this execution context
@@ -378,6 +397,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	ret, err = run(evm, contract, input, true)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.TobinTaxes = evm.TobinTaxes[:taxes]
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -417,6 +437,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	}
 	// Create a new account on the state
 	snapshot := evm.StateDB.Snapshot()
+	taxes := len(evm.TobinTaxes)
 	evm.StateDB.CreateAccount(address)
 	if evm.ChainConfig().IsEIP158(evm.BlockNumber) {
 		evm.StateDB.SetNonce(address, 1)
@@ -464,6 +485,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.ChainConfig().IsHomestead(evm.BlockNumber) || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.TobinTaxes = evm.TobinTaxes[:taxes]
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -498,6 +520,16 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
+// TotalTobinTax returns the sum of the tobin taxes taken by the call frames
+// that have not been reverted.
+func (evm *EVM) TotalTobinTax() *big.Int {
+	total := new(big.Int)
+	for _, tax := range evm.TobinTaxes {
+		total.Add(total, tax.Tax)
+	}
+	return total
+}
+
 func getOrComputeTobinTaxFunctionSelector() []byte {
 	// Function is "getOrComputeTobinTax()"
 	// selector is first 4 bytes of keccak256 of "getOrComputeTobinTax()"
@@ -507,7 +539,8 @@ func getOrComputeTobinTaxFunctionSelector() []byte {
 	return hexutil.MustDecode("0x17f9a6f7")
 }
 
-// TobinTransfer performs a transfer that takes a tax from the sent amount and gives it to the reserve
+// TobinTransfer performs a transfer that takes a tax from the sent amount and gives it to the reserve.
+// Taxes taken are recorded in TobinTaxes and reported to the tracer in debug mode.
 func (evm *EVM) TobinTransfer(db StateDB, sender, recipient common.Address, gas uint64, amount *big.Int) (leftOverGas uint64, err error) {
 	reserveAddress := evm.Context.getRegisteredAddress(params.ReserveRegistryId)
 
@@ -526,6 +559,19 @@ func (evm *EVM) TobinTransfer(db StateDB, sender, recipient common.Address, gas
 
 			evm.Context.Transfer(db, sender, recipient, new(big.Int).Sub(amount, tobinTax))
 			evm.Context.Transfer(db, sender, *reserveAddress, tobinTax)
+
+			tax := TobinTax{
+				From:    sender,
+				To:      recipient,
+				Reserve: *reserveAddress,
+				Amount:  new(big.Int).Set(amount),
+				Tax:     tobinTax,
+				Depth:   evm.depth,
+			}
+			evm.TobinTaxes = append(evm.TobinTaxes, tax)
+			if evm.vmConfig.Debug {
+				evm.vmConfig.Tracer.CaptureTobinTax(evm, &tax)
+			}
 			return gas, nil
 		}
 	}
@@ -0,0 +1,92 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testReserveCode is a stand-in for the Reserve contract that answers every
+// call with a tobin tax of 1/10.
+//
+// PUSH1 1 PUSH1 0 MSTORE PUSH1 10 PUSH1 32 MSTORE PUSH1 64 PUSH1 0 RETURN
+var testReserveCode = common.FromHex("0x6001600052600a60205260406000f3")
+
+// Tests that the tobin tax taken from value transfers is recorded per call
+// frame, reported to the tracer, and dropped when the call frame is reverted.
+func TestTobinTransfer(t *testing.T) {
+	var (
+		sender    = common.HexToAddress("0x1001")
+		recipient = common.HexToAddress("0x1002")
+		reverter  = common.HexToAddress("0x1003")
+		reserve   = common.HexToAddress("0xce10")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb.SetBalance(sender, big.NewInt(1000))
+	statedb.SetCode(reserve, testReserveCode)
+	statedb.SetCode(reverter, []byte{0xfe}) // INVALID
+
+	context := Context{
+		CanTransfer: func(db StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(db StateDB, sender, recipient common.Address, amount *big.Int) {
+			db.SubBalance(sender, amount)
+			db.AddBalance(recipient, amount)
+		},
+		BlockNumber:          big.NewInt(1),
+		RegisteredAddressMap: map[string]*common.Address{params.ReserveRegistryId: &reserve},
+	}
+	logger := NewStructLogger(nil)
+	env := NewEVM(context, statedb, params.TestChainConfig, Config{Debug: true, Tracer: logger})
+
+	if _, _, err := env.Call(AccountRef(sender), recipient, nil, 100000, big.NewInt(100)); err != nil {
+		t.Fatalf("failed to transfer: %v", err)
+	}
+	if balance := statedb.GetBalance(recipient); balance.Cmp(big.NewInt(90)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want %v", balance, 90)
+	}
+	if balance := statedb.GetBalance(reserve); balance.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("reserve balance mismatch: have %v, want %v", balance, 10)
+	}
+	if len(env.TobinTaxes) != 1 {
+		t.Fatalf("tobin tax count mismatch: have %d, want 1", len(env.TobinTaxes))
+	}
+	tax := env.TobinTaxes[0]
+	if tax.From != sender || tax.To != recipient || tax.Reserve != reserve || tax.Amount.Cmp(big.NewInt(100)) != 0 || tax.Tax.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("tobin tax mismatch: have %+v", tax)
+	}
+	// Taxes taken by reverted call frames are traced, but not accounted for
+	if _, _, err := env.Call(AccountRef(sender), reverter, nil, 100000, big.NewInt(100)); err == nil {
+		t.Fatalf("call to reverting contract succeeded")
+	}
+	if len(env.TobinTaxes) != 1 {
+		t.Errorf("tobin tax count mismatch: have %d, want 1", len(env.TobinTaxes))
+	}
+	if total := env.TotalTobinTax(); total.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("total tobin tax mismatch: have %v, want %v", total, 10)
+	}
+	if traced := logger.TobinTaxes(); len(traced) != 2 {
+		t.Errorf("traced tobin tax count mismatch: have %d, want 2", len(traced))
+	}
+}
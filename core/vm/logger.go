@@ -101,6 +101,7 @@ type Tracer interface {
 	CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error
 	CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
+	CaptureTobinTax(env *EVM, tax *TobinTax) error
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
@@ -113,6 +114,7 @@ type StructLogger struct {
 	cfg LogConfig
 
 	logs          []StructLog
+	tobinTaxes    []TobinTax
 	changedValues map[common.Address]Storage
 	output        []byte
 	err           error
@@ -190,6 +192,13 @@ func (l *StructLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost ui
 	return nil
 }
 
+// CaptureTobinTax implements the Tracer interface to record the tobin tax taken
+// from a value transfer.
+func (l *StructLogger) CaptureTobinTax(env *EVM, tax *TobinTax) error {
+	l.tobinTaxes = append(l.tobinTaxes, *tax)
+	return nil
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	l.output = output
@@ -206,6 +215,10 @@ func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// TobinTaxes returns the captured tobin taxes, including those taken by call
+// frames that were reverted afterwards.
+func (l *StructLogger) TobinTaxes() []TobinTax { return l.tobinTaxes }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
@@ -72,6 +72,19 @@ func (l *JSONLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint
 	return nil
 }
 
+// CaptureTobinTax outputs the tobin tax taken from a value transfer.
+func (l *JSONLogger) CaptureTobinTax(env *EVM, tax *TobinTax) error {
+	type tobinTaxLog struct {
+		From    common.Address        `json:"from"`
+		To      common.Address        `json:"to"`
+		Reserve common.Address        `json:"reserve"`
+		Amount  *math.HexOrDecimal256 `json:"amount"`
+		Tax     *math.HexOrDecimal256 `json:"tobinTax"`
+		Depth   int                   `json:"depth"`
+	}
+	return l.encoder.Encode(tobinTaxLog{tax.From, tax.To, tax.Reserve, (*math.HexOrDecimal256)(tax.Amount), (*math.HexOrDecimal256)(tax.Tax), tax.Depth})
+}
+
 // CaptureEnd is triggered at end of execution.
 func (l *JSONLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	type endLog struct {
@@ -0,0 +1,245 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bls implements BLS signatures over the BN256 curve, with signatures in
+// G1 and public keys in G2. Signatures of the same message can be aggregated into
+// a single signature that verifies against the sum of the signers' public keys.
+//
+// Aggregation is only safe against rogue key attacks if every public key comes
+// with a proof of possession of its private key, see PrivateKey.ProvePossession.
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+const (
+	PrivateKeyLength = 32  // Length of a marshalled private key
+	PublicKeyLength  = 128 // Length of a marshalled public key
+	SignatureLength  = 64  // Length of a marshalled signature
+)
+
+var (
+	errInvalidPrivateKey = errors.New("invalid bls private key")
+	errInvalidPublicKey  = errors.New("invalid bls public key")
+	errInvalidSignature  = errors.New("invalid bls signature")
+
+	// Domain separation tags of signed messages and proofs of possession
+	signatureDomain  = []byte("celo-bls-signature")
+	possessionDomain = []byte("celo-bls-possession")
+
+	g2Generator = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+	curveB      = big.NewInt(3)
+)
+
+// PrivateKey is a BLS private key.
+type PrivateKey struct {
+	k *big.Int
+}
+
+// PublicKey is a BLS public key.
+type PublicKey struct {
+	p *bn256.G2
+}
+
+// Signature is a BLS signature, or an aggregate of signatures.
+type Signature struct {
+	p *bn256.G1
+}
+
+// GenerateKey generates a private key using randomness from r, or from
+// crypto/rand if r is nil.
+func GenerateKey(r io.Reader) (*PrivateKey, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	for {
+		k, err := rand.Int(r, bn256.Order)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() > 0 {
+			return &PrivateKey{k: k}, nil
+		}
+	}
+}
+
+// PublicKey returns the public key of k.
+func (k *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{p: new(bn256.G2).ScalarBaseMult(k.k)}
+}
+
+// Marshal encodes k into PrivateKeyLength bytes.
+func (k *PrivateKey) Marshal() []byte {
+	return math.PaddedBigBytes(k.k, PrivateKeyLength)
+}
+
+// UnmarshalPrivateKey decodes a private key encoded by PrivateKey.Marshal.
+func UnmarshalPrivateKey(b []byte) (*PrivateKey, error) {
+	k := new(big.Int).SetBytes(b)
+	if len(b) != PrivateKeyLength || k.Sign() <= 0 || k.Cmp(bn256.Order) >= 0 {
+		return nil, errInvalidPrivateKey
+	}
+	return &PrivateKey{k: k}, nil
+}
+
+// LoadKey loads a private key from the given file, in which it is hex-encoded.
+func LoadKey(file string) (*PrivateKey, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPrivateKey(b)
+}
+
+// SaveKey saves a private key to the given file with restrictive permissions.
+// The key is saved hex-encoded.
+func SaveKey(file string, k *PrivateKey) error {
+	return ioutil.WriteFile(file, []byte(hex.EncodeToString(k.Marshal())), 0600)
+}
+
+// Sign signs msg with k.
+func (k *PrivateKey) Sign(msg []byte) *Signature {
+	return &Signature{p: new(bn256.G1).ScalarMult(hashToG1(signatureDomain, msg), k.k)}
+}
+
+// ProvePossession signs the public key of k, proving that whoever registers
+// the public key holds its private key.
+func (k *PrivateKey) ProvePossession() *Signature {
+	return &Signature{p: new(bn256.G1).ScalarMult(hashToG1(possessionDomain, k.PublicKey().Marshal()), k.k)}
+}
+
+// Verify checks that sig is a signature of msg by pk.
+func (pk *PublicKey) Verify(msg []byte, sig *Signature) bool {
+	return verify(pk.p, hashToG1(signatureDomain, msg), sig.p)
+}
+
+// VerifyPossession checks that pop is a proof of possession of the private key
+// of pk.
+func (pk *PublicKey) VerifyPossession(pop *Signature) bool {
+	return verify(pk.p, hashToG1(possessionDomain, pk.Marshal()), pop.p)
+}
+
+// Marshal encodes pk into PublicKeyLength bytes.
+func (pk *PublicKey) Marshal() []byte {
+	return pk.p.Marshal()
+}
+
+// UnmarshalPublicKey decodes a public key encoded by PublicKey.Marshal.
+func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
+	// The point at infinity is rejected, it would verify any aggregate signature
+	if len(b) != PublicKeyLength || bytes.Equal(b, make([]byte, PublicKeyLength)) {
+		return nil, errInvalidPublicKey
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, errInvalidPublicKey
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// Marshal encodes sig into SignatureLength bytes.
+func (sig *Signature) Marshal() []byte {
+	return sig.p.Marshal()
+}
+
+// UnmarshalSignature decodes a signature encoded by Signature.Marshal.
+func UnmarshalSignature(b []byte) (*Signature, error) {
+	if len(b) != SignatureLength {
+		return nil, errInvalidSignature
+	}
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, errInvalidSignature
+	}
+	return &Signature{p: p}, nil
+}
+
+// AggregateSignatures aggregates the given signatures into a single one.
+func AggregateSignatures(sigs []*Signature) *Signature {
+	p := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	for _, sig := range sigs {
+		p.Add(p, sig.p)
+	}
+	return &Signature{p: p}
+}
+
+// AggregatePublicKeys aggregates the given public keys into a single one, which
+// verifies the aggregate of their signatures of the same message.
+func AggregatePublicKeys(pks []*PublicKey) *PublicKey {
+	p := new(bn256.G2).ScalarBaseMult(new(big.Int))
+	for _, pk := range pks {
+		p.Add(p, pk.p)
+	}
+	return &PublicKey{p: p}
+}
+
+// VerifyAggregate checks that sig is the aggregate of the signatures of msg by
+// all of pks.
+func VerifyAggregate(pks []*PublicKey, msg []byte, sig *Signature) bool {
+	if len(pks) == 0 {
+		return false
+	}
+	return AggregatePublicKeys(pks).Verify(msg, sig)
+}
+
+// verify checks that e(sig, g2) == e(h, pk).
+func verify(pk *bn256.G2, h *bn256.G1, sig *bn256.G1) bool {
+	return bn256.PairingCheck([]*bn256.G1{new(bn256.G1).Neg(sig), h}, []*bn256.G2{g2Generator, pk})
+}
+
+// hashToG1 maps msg to a point of G1 by hashing it, together with a counter, to
+// x coordinates until one is on the curve y² = x³ + 3. G1 has a cofactor of 1,
+// so every point on the curve is in the group.
+func hashToG1(domain, msg []byte) *bn256.G1 {
+	var counter [4]byte
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		x := new(big.Int).SetBytes(crypto.Keccak256(domain, msg, counter[:]))
+		x.Mod(x, bn256.P)
+
+		y2 := new(big.Int).Exp(x, big.NewInt(3), bn256.P)
+		y2.Add(y2, curveB).Mod(y2, bn256.P)
+		y := new(big.Int).ModSqrt(y2, bn256.P)
+		if y == nil {
+			continue
+		}
+		point := make([]byte, 64)
+		copy(point[32-len(x.Bytes()):32], x.Bytes())
+		copy(point[64-len(y.Bytes()):], y.Bytes())
+
+		p := new(bn256.G1)
+		if _, err := p.Unmarshal(point); err == nil {
+			return p
+		}
+	}
+}
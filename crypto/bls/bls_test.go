@@ -0,0 +1,140 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package bls
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	key, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	msg := []byte("committed seal")
+	sig := key.Sign(msg)
+	if !key.PublicKey().Verify(msg, sig) {
+		t.Errorf("valid signature rejected")
+	}
+	if key.PublicKey().Verify([]byte("other seal"), sig) {
+		t.Errorf("signature of other message accepted")
+	}
+	other, _ := GenerateKey(nil)
+	if other.PublicKey().Verify(msg, sig) {
+		t.Errorf("signature accepted for other key")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	key, _ := GenerateKey(nil)
+	sk, err := UnmarshalPrivateKey(key.Marshal())
+	if err != nil {
+		t.Fatalf("failed to unmarshal private key: %v", err)
+	}
+	if !bytes.Equal(sk.PublicKey().Marshal(), key.PublicKey().Marshal()) {
+		t.Errorf("unmarshalled private key mismatch")
+	}
+	if _, err := UnmarshalPrivateKey(make([]byte, PrivateKeyLength)); err == nil {
+		t.Errorf("zero accepted as private key")
+	}
+	pk, err := UnmarshalPublicKey(key.PublicKey().Marshal())
+	if err != nil {
+		t.Fatalf("failed to unmarshal public key: %v", err)
+	}
+	sig, err := UnmarshalSignature(key.Sign([]byte("msg")).Marshal())
+	if err != nil {
+		t.Fatalf("failed to unmarshal signature: %v", err)
+	}
+	if !pk.Verify([]byte("msg"), sig) {
+		t.Errorf("unmarshalled signature rejected")
+	}
+	if _, err := UnmarshalPublicKey(make([]byte, PublicKeyLength)); err == nil {
+		t.Errorf("point at infinity accepted as public key")
+	}
+	if _, err := UnmarshalSignature([]byte{1, 2, 3}); err == nil {
+		t.Errorf("short signature accepted")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	msg := []byte("committed seal")
+	var (
+		pks  []*PublicKey
+		sigs []*Signature
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := GenerateKey(nil)
+		pks = append(pks, key.PublicKey())
+		sigs = append(sigs, key.Sign(msg))
+	}
+	sig := AggregateSignatures(sigs)
+	if !VerifyAggregate(pks, msg, sig) {
+		t.Errorf("valid aggregate signature rejected")
+	}
+	if VerifyAggregate(pks[:3], msg, sig) {
+		t.Errorf("aggregate signature accepted for a subset of signers")
+	}
+	if VerifyAggregate(pks[:3], msg, AggregateSignatures(sigs[1:])) {
+		t.Errorf("aggregate signature accepted for other signers")
+	}
+	if VerifyAggregate(nil, msg, AggregateSignatures(nil)) {
+		t.Errorf("empty aggregate signature accepted")
+	}
+}
+
+func TestPossession(t *testing.T) {
+	key, _ := GenerateKey(nil)
+	other, _ := GenerateKey(nil)
+	if !key.PublicKey().VerifyPossession(key.ProvePossession()) {
+		t.Errorf("valid proof of possession rejected")
+	}
+	if key.PublicKey().VerifyPossession(other.ProvePossession()) {
+		t.Errorf("proof of possession of other key accepted")
+	}
+	// A signature of the public key is not a proof of possession
+	if key.PublicKey().VerifyPossession(key.Sign(key.PublicKey().Marshal())) {
+		t.Errorf("signature accepted as proof of possession")
+	}
+}
+
+func TestSaveLoadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "blskey")
+	key, _ := GenerateKey(nil)
+	if err := SaveKey(file, key); err != nil {
+		t.Fatalf("failed to save key: %v", err)
+	}
+	loaded, err := LoadKey(file)
+	if err != nil {
+		t.Fatalf("failed to load key: %v", err)
+	}
+	if !bytes.Equal(loaded.Marshal(), key.Marshal()) {
+		t.Errorf("loaded key mismatch: have %x, want %x", loaded.Marshal(), key.Marshal())
+	}
+	if _, err := LoadKey(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing key file error mismatch: have %v", err)
+	}
+}
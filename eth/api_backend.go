@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -38,6 +39,7 @@ import (
 // EthAPIBackend implements ethapi.Backend for full nodes
 type EthAPIBackend struct {
 	eth *Ethereum
+	gpo *gasprice.Oracle
 }
 
 // ChainConfig returns the active chain configuration.
@@ -184,6 +186,10 @@ func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.TxPool().Content()
 }
 
+func (b *EthAPIBackend) TxPoolCurrencyStats() map[common.Address]*core.CurrencyStats {
+	return b.eth.TxPool().CurrencyStats()
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
@@ -197,11 +203,11 @@ func (b *EthAPIBackend) ProtocolVersion() int {
 }
 
 func (b *EthAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
-	return b.eth.GasPriceMinimum().GetGasPriceSuggestion(nil, nil, nil)
+	return b.gpo.SuggestPrice(ctx, nil)
 }
 
 func (b *EthAPIBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
-	return b.eth.GasPriceMinimum().GetGasPriceSuggestion(currencyAddress, nil, nil)
+	return b.gpo.SuggestPrice(ctx, currencyAddress)
 }
 
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
@@ -235,6 +241,10 @@ func (b *EthAPIBackend) GasCurrencyWhitelist() *core.GasCurrencyWhitelist {
 	return b.eth.GasCurrencyWhitelist()
 }
 
+func (b *EthAPIBackend) CurrencyOperator() *core.CurrencyOperator {
+	return b.eth.CurrencyOperator()
+}
+
 func (b *EthAPIBackend) RegisteredAddresses() *core.RegisteredAddresses {
 	return b.eth.RegisteredAddresses()
 }
@@ -242,3 +252,7 @@ func (b *EthAPIBackend) RegisteredAddresses() *core.RegisteredAddresses {
 func (b *EthAPIBackend) GasPriceMinimum() *core.GasPriceMinimum {
 	return b.eth.GasPriceMinimum()
 }
+
+func (b *EthAPIBackend) Random() *core.Random {
+	return b.eth.Random()
+}
@@ -776,6 +776,8 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 			Failed:      failed,
 			ReturnValue: fmt.Sprintf("%x", ret),
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
+			TobinTax:    (*hexutil.Big)(vmenv.TotalTobinTax()),
+			TobinTaxes:  ethapi.FormatTobinTaxes(tracer.TobinTaxes()),
 		}, nil
 
 	case *tracers.Tracer:
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/abe"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -40,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -96,6 +98,12 @@ type Ethereum struct {
 	regAdd *core.RegisteredAddresses
 	iEvmH  *core.InternalEVMHandler
 	gpm    *core.GasPriceMinimum
+	co     *core.CurrencyOperator
+	random *core.Random
+
+	randomStore *core.RandomnessStore // Randomness preimages, kept apart from the chain data
+
+	attestations *abe.Outbox // Attestation requests to deliver
 
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
@@ -119,6 +127,12 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.MinerGasPrice, "updated", DefaultConfig.MinerGasPrice)
 		config.MinerGasPrice = new(big.Int).Set(DefaultConfig.MinerGasPrice)
 	}
+	if config.MinerVerificationServiceUrl != "" {
+		log.Warn("MinerVerificationServiceUrl is deprecated, use Attestation.URL instead")
+		if config.Attestation.URL == "" || config.Attestation.URL == DefaultConfig.Attestation.URL {
+			config.Attestation.URL = config.MinerVerificationServiceUrl
+		}
+	}
 	// Assemble the Ethereum object
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
@@ -194,14 +208,21 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	eth.gpm = core.NewGasPriceMinimum(eth.iEvmH, eth.regAdd)
 
 	// Object used to compare two different prices using any of the whitelisted gas currencies.
-	co := core.NewCurrencyOperator(eth.gcWl, eth.regAdd, eth.iEvmH)
-	random := core.NewRandom(eth.regAdd, eth.iEvmH)
+	// Exchange rates are pinned to each new chain head.
+	eth.co = core.NewCurrencyOperator(eth.gcWl, eth.regAdd, eth.iEvmH, eth.blockchain)
+	// The randomness preimages are kept in their own database, so they survive the chain data being removed
+	randomDb, err := ctx.OpenDatabase("randomness", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	eth.randomStore = core.NewRandomnessStore(randomDb)
+	eth.random = core.NewRandom(eth.regAdd, eth.iEvmH, eth.randomStore)
 
-	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain, co, eth.gcWl, eth.iEvmH)
+	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain, eth.co, eth.gcWl, eth.iEvmH)
 	eth.blockchain.Processor().SetGasCurrencyWhitelist(eth.gcWl)
 	eth.blockchain.Processor().SetRegisteredAddresses(eth.regAdd)
 	eth.blockchain.Processor().SetGasPriceMinimum(eth.gpm)
-	eth.blockchain.Processor().SetRandom(random)
+	eth.blockchain.Processor().SetRandom(eth.random)
 
 	if eth.protocolManager, err = NewProtocolManager(eth.chainConfig, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb, config.Whitelist, ctx.Server); err != nil {
 		return nil, err
@@ -215,10 +236,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		istanbul.SetGasPriceMinimum(eth.gpm)
 	}
 
-	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine, config.MinerRecommit, config.MinerGasFloor, config.MinerGasCeil, eth.isLocalBlock, config.MinerVerificationServiceUrl, co, random, &chainDb)
+	deliverer, err := abe.NewDeliverer(&config.Attestation)
+	if err != nil {
+		return nil, err
+	}
+	eth.attestations = abe.NewOutbox(chainDb, eth.accountManager, deliverer)
+	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine, config.MinerRecommit, config.MinerGasFloor, config.MinerGasCeil, eth.isLocalBlock, eth.attestations, eth.co, eth.random)
 	eth.miner.SetExtra(makeExtraData(config.MinerExtraData))
 
-	eth.APIBackend = &EthAPIBackend{eth}
+	eth.APIBackend = &EthAPIBackend{eth, nil}
+	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, config.GPO)
+
 	return eth, nil
 }
 
@@ -265,7 +293,17 @@ func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainCo
 			config.Istanbul.Epoch = chainConfig.Istanbul.Epoch
 		}
 		config.Istanbul.ProposerPolicy = istanbul.ProposerPolicy(chainConfig.Istanbul.ProposerPolicy)
-		return istanbulBackend.New(&config.Istanbul, db)
+		config.Istanbul.AggregatedSealBlock = chainConfig.AggregatedSealBlock
+		// The write-ahead log and the BLS key live in the data directory, ephemeral nodes keep them in memory
+		if config.Istanbul.WAL == "" {
+			config.Istanbul.WAL = "istanbul.wal"
+		}
+		config.Istanbul.WAL = ctx.ResolvePath(config.Istanbul.WAL)
+		if config.Istanbul.BLSKey == "" {
+			config.Istanbul.BLSKey = "blskey"
+		}
+		config.Istanbul.BLSKey = ctx.ResolvePath(config.Istanbul.BLSKey)
+		return istanbulBackend.New(&config.Istanbul, db, ctx.NodeKey())
 	}
 
 	// Otherwise assume proof-of-work
@@ -342,6 +380,10 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+		}, {
+			Namespace: "abe",
+			Version:   "1.0",
+			Service:   abe.NewPrivateABEAPI(s.attestations),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -484,6 +526,15 @@ func (s *Ethereum) StartMining(threads int) error {
 			if isIstanbul {
 				istanbul.Authorize(eb, wallet.SignHash)
 			}
+			if err := s.randomStore.Authorize(eb, wallet.SignHash); err != nil {
+				log.Error("Failed to unlock randomness store", "err", err)
+				return fmt.Errorf("randomness store locked: %v", err)
+			}
+			if moved, err := s.randomStore.Migrate(s.chainDb); err != nil {
+				log.Error("Failed to move randomness preimages out of chain database", "err", err)
+			} else if moved > 0 {
+				log.Info("Moved randomness preimages out of chain database", "count", moved)
+			}
 		}
 		// If mining is started, we can disable the transaction rejection mechanism
 		// introduced to speed sync times.
@@ -526,6 +577,8 @@ func (s *Ethereum) GasFeeRecipient() common.Address                  { return s.
 func (s *Ethereum) RegisteredAddresses() *core.RegisteredAddresses   { return s.regAdd }
 func (s *Ethereum) InternalEVMHandler() *core.InternalEVMHandler     { return s.iEvmH }
 func (s *Ethereum) GasPriceMinimum() *core.GasPriceMinimum           { return s.gpm }
+func (s *Ethereum) CurrencyOperator() *core.CurrencyOperator         { return s.co }
+func (s *Ethereum) Random() *core.Random                             { return s.random }
 
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
@@ -558,6 +611,7 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	s.attestations.Start()
 	return nil
 }
 
@@ -572,10 +626,13 @@ func (s *Ethereum) Stop() error {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	s.co.Stop()
 	s.miner.Stop()
+	s.attestations.Stop()
 	s.eventMux.Stop()
 
 	s.chainDb.Close()
+	s.randomStore.Close()
 	close(s.shutdownChan)
 	return nil
 }
@@ -24,12 +24,14 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/ethereum/go-ethereum/abe"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 )
 
 // DefaultConfig contains default settings for use on the Ethereum main net.
@@ -42,17 +44,22 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:                   1,
-	LightPeers:                  100,
-	DatabaseCache:               768,
-	TrieTimeout:                 60 * time.Minute,
-	MinerGasFloor:               8000000,
-	MinerGasCeil:                8000000,
-	MinerGasPrice:               big.NewInt(1),
-	MinerRecommit:               3 * time.Second,
-	MinerVerificationServiceUrl: "https://mining-pool.celo.org/v0.1/sms",
+	NetworkId:     1,
+	LightPeers:    100,
+	DatabaseCache: 768,
+	TrieTimeout:   60 * time.Minute,
+	MinerGasFloor: 8000000,
+	MinerGasCeil:  8000000,
+	MinerGasPrice: big.NewInt(1),
+	MinerRecommit: 3 * time.Second,
+
+	Attestation: abe.DefaultConfig,
 
 	TxPool: core.DefaultTxPoolConfig,
+	GPO: gasprice.Config{
+		Blocks:     20,
+		Percentile: 60,
+	},
 
 	Istanbul: *istanbul.DefaultConfig,
 }
@@ -102,14 +109,19 @@ type Config struct {
 	TrieTimeout        time.Duration
 
 	// Mining-related options
-	MinerNotify                 []string `toml:",omitempty"`
-	MinerExtraData              []byte   `toml:",omitempty"`
-	MinerGasFloor               uint64
-	MinerGasCeil                uint64
-	MinerGasPrice               *big.Int
-	MinerRecommit               time.Duration
-	MinerNoverify               bool
-	MinerVerificationServiceUrl string
+	MinerNotify    []string `toml:",omitempty"`
+	MinerExtraData []byte   `toml:",omitempty"`
+	MinerGasFloor  uint64
+	MinerGasCeil   uint64
+	MinerGasPrice  *big.Int
+	MinerRecommit  time.Duration
+	MinerNoverify  bool
+
+	// Deprecated, use Attestation.URL
+	MinerVerificationServiceUrl string `toml:",omitempty"`
+
+	// Attestation delivery options
+	Attestation abe.Config
 
 	// Ethash options
 	Ethash ethash.Config
@@ -117,6 +129,9 @@ type Config struct {
 	// Transaction pool options
 	TxPool core.TxPoolConfig
 
+	// Gas Price Oracle options
+	GPO gasprice.Config
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
@@ -0,0 +1,229 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package gasprice implements a gas price oracle suggesting gas prices in any of
+// the whitelisted gas currencies.
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type Config struct {
+	Blocks     int
+	Percentile int
+}
+
+// Oracle recommends gas prices based on the content of recent blocks. The prices
+// paid in every gas currency are normalized to gold using the exchange rates of
+// the block they were included in, so that a single sample is taken per block
+// regardless of the currencies used. Suggestions are converted back into the
+// requested currency at the exchange rates of the current head, and never fall
+// below the current gas price minimum of that currency.
+type Oracle struct {
+	backend   ethapi.Backend
+	lastHead  common.Hash
+	lastPrice *big.Int // Gold denominated price at lastHead, nil if no samples were found
+	cacheLock sync.RWMutex
+	fetchLock sync.Mutex
+
+	checkBlocks, maxEmpty, maxBlocks int
+	percentile                       int
+}
+
+// NewOracle returns a new oracle.
+func NewOracle(backend ethapi.Backend, params Config) *Oracle {
+	blocks := params.Blocks
+	if blocks < 1 {
+		blocks = 1
+	}
+	percent := params.Percentile
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return &Oracle{
+		backend:     backend,
+		checkBlocks: blocks,
+		maxEmpty:    blocks / 2,
+		maxBlocks:   blocks * 5,
+		percentile:  percent,
+	}
+}
+
+// SuggestPrice returns the recommended gas price in the given currency, or in
+// gold if currency is nil.
+func (gpo *Oracle) SuggestPrice(ctx context.Context, currency *common.Address) (*big.Int, error) {
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, err
+	}
+	minimum, err := gpo.backend.GasPriceMinimum().GetGasPriceMinimum(currency, nil, nil)
+	if err != nil || minimum == nil {
+		log.Debug("Failed to retrieve gas price minimum", "err", err)
+		minimum = new(big.Int).Set(core.FallbackGasPriceMinimum)
+	}
+	goldPrice, err := gpo.suggestGoldPrice(ctx, head)
+	if err != nil {
+		return minimum, err
+	}
+	if goldPrice == nil {
+		return minimum, nil
+	}
+	price := goldPrice
+	if currency != nil {
+		co := gpo.backend.CurrencyOperator()
+		if co == nil {
+			return minimum, nil
+		}
+		if price, err = co.ConvertAtHeader(goldPrice, nil, currency, head); err != nil {
+			log.Debug("Failed to convert suggested gas price", "currency", currency.Hex(), "err", err)
+			return minimum, nil
+		}
+	}
+	if price.Cmp(minimum) < 0 {
+		return minimum, nil
+	}
+	return price, nil
+}
+
+// suggestGoldPrice returns the gold denominated percentile of the lowest prices
+// paid in the blocks preceding head, or nil if none of them contain a sample.
+func (gpo *Oracle) suggestGoldPrice(ctx context.Context, head *types.Header) (*big.Int, error) {
+	gpo.cacheLock.RLock()
+	lastHead := gpo.lastHead
+	lastPrice := gpo.lastPrice
+	gpo.cacheLock.RUnlock()
+
+	headHash := head.Hash()
+	if headHash == lastHead {
+		return lastPrice, nil
+	}
+
+	gpo.fetchLock.Lock()
+	defer gpo.fetchLock.Unlock()
+
+	// try checking the cache again, maybe the last fetch fetched what we need
+	gpo.cacheLock.RLock()
+	lastHead = gpo.lastHead
+	lastPrice = gpo.lastPrice
+	gpo.cacheLock.RUnlock()
+	if headHash == lastHead {
+		return lastPrice, nil
+	}
+
+	blockNum := head.Number.Uint64()
+	ch := make(chan getBlockPricesResult, gpo.checkBlocks)
+	sent := 0
+	exp := 0
+	var blockPrices []*big.Int
+	for sent < gpo.checkBlocks && blockNum > 0 {
+		go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, ch)
+		sent++
+		exp++
+		blockNum--
+	}
+	maxEmpty := gpo.maxEmpty
+	for exp > 0 {
+		res := <-ch
+		if res.err != nil {
+			return lastPrice, res.err
+		}
+		exp--
+		if res.price != nil {
+			blockPrices = append(blockPrices, res.price)
+			continue
+		}
+		if maxEmpty > 0 {
+			maxEmpty--
+			continue
+		}
+		if blockNum > 0 && sent < gpo.maxBlocks {
+			go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, ch)
+			sent++
+			exp++
+			blockNum--
+		}
+	}
+	var price *big.Int
+	if len(blockPrices) > 0 {
+		sort.Sort(bigIntArray(blockPrices))
+		price = blockPrices[(len(blockPrices)-1)*gpo.percentile/100]
+	}
+
+	gpo.cacheLock.Lock()
+	gpo.lastHead = headHash
+	gpo.lastPrice = price
+	gpo.cacheLock.Unlock()
+	return price, nil
+}
+
+type getBlockPricesResult struct {
+	price *big.Int
+	err   error
+}
+
+// getBlockPrices calculates the lowest gold denominated transaction gas price in
+// a given block and sends it to the result channel. If the block is empty, or
+// none of its transactions could be normalized to gold, price is nil.
+// Transactions sent by the miner are ignored.
+func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, blockNum uint64, ch chan getBlockPricesResult) {
+	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
+	if block == nil {
+		ch <- getBlockPricesResult{nil, err}
+		return
+	}
+	co := gpo.backend.CurrencyOperator()
+
+	var lowest *big.Int
+	for _, tx := range block.Transactions() {
+		sender, err := types.Sender(signer, tx)
+		if err != nil || sender == block.Coinbase() {
+			continue
+		}
+		price := tx.GasPrice()
+		if tx.GasCurrency() != nil {
+			if co == nil {
+				continue
+			}
+			if price, err = co.ConvertAtHeader(price, tx.GasCurrency(), nil, block.Header()); err != nil {
+				continue
+			}
+		}
+		if lowest == nil || price.Cmp(lowest) < 0 {
+			lowest = price
+		}
+	}
+	ch <- getBlockPricesResult{lowest, nil}
+}
+
+type bigIntArray []*big.Int
+
+func (s bigIntArray) Len() int           { return len(s) }
+func (s bigIntArray) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
+func (s bigIntArray) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,136 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBackend is a mock backend serving a fixed chain of blocks. Methods not
+// needed by the oracle are left unimplemented.
+type testBackend struct {
+	ethapi.Backend
+	blocks []*types.Block
+}
+
+func (b *testBackend) block(number rpc.BlockNumber) *types.Block {
+	if number == rpc.LatestBlockNumber {
+		return b.blocks[len(b.blocks)-1]
+	}
+	if int(number) < len(b.blocks) {
+		return b.blocks[number]
+	}
+	return nil
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if block := b.block(number); block != nil {
+		return block.Header(), nil
+	}
+	return nil, nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.block(number), nil
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig         { return params.TestChainConfig }
+func (b *testBackend) GasPriceMinimum() *core.GasPriceMinimum   { return nil }
+func (b *testBackend) CurrencyOperator() *core.CurrencyOperator { return nil }
+
+// newTestBackend creates a chain in which every block contains one gold
+// denominated transaction per given price, plus a cheaper one in an unknown
+// currency that cannot be normalized and is thus ignored.
+func newTestBackend(t *testing.T, prices [][]int64) *testBackend {
+	key, _ := crypto.GenerateKey()
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(1))
+	currency := common.HexToAddress("0xc0ffee")
+
+	backend := &testBackend{blocks: []*types.Block{types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})}}
+	nonce := uint64(0)
+	for i, blockPrices := range prices {
+		var txs []*types.Transaction
+		for _, price := range blockPrices {
+			tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(price), nil, nil, nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			txs = append(txs, tx)
+			nonce++
+		}
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), &currency, nil, nil), signer, key)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		txs = append(txs, tx)
+		nonce++
+
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), Coinbase: common.HexToAddress("0x01")}
+		backend.blocks = append(backend.blocks, types.NewBlock(header, txs, nil, nil, nil))
+	}
+	return backend
+}
+
+// Tests that the suggested gas price is the requested percentile of the lowest
+// gold denominated prices paid in recent blocks.
+func TestSuggestPrice(t *testing.T) {
+	backend := newTestBackend(t, [][]int64{{10, 20}, {30}, {40, 50}, {}, {60}})
+
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60})
+	price, err := oracle.SuggestPrice(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to suggest price: %v", err)
+	}
+	// Block samples are 10, 30, 40 and 60, the empty block is skipped
+	if price.Cmp(big.NewInt(30)) != 0 {
+		t.Errorf("suggested price mismatch: have %v, want %v", price, 30)
+	}
+	// Currencies that cannot be converted fall back to the gas price minimum
+	currency := common.HexToAddress("0xc0ffee")
+	price, err = oracle.SuggestPrice(context.Background(), &currency)
+	if err != nil {
+		t.Fatalf("failed to suggest price: %v", err)
+	}
+	if price.Cmp(core.FallbackGasPriceMinimum) != 0 {
+		t.Errorf("suggested currency price mismatch: have %v, want %v", price, core.FallbackGasPriceMinimum)
+	}
+}
+
+// Tests that the gas price minimum is suggested if recent blocks contain no
+// samples.
+func TestSuggestPriceEmptyBlocks(t *testing.T) {
+	backend := newTestBackend(t, [][]int64{{}, {}, {}})
+
+	oracle := NewOracle(backend, Config{Blocks: 2, Percentile: 60})
+	price, err := oracle.SuggestPrice(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to suggest price: %v", err)
+	}
+	if price.Cmp(core.FallbackGasPriceMinimum) != 0 {
+		t.Errorf("suggested price mismatch: have %v, want %v", price, core.FallbackGasPriceMinimum)
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/abe"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 )
 
 var _ = (*configMarshaling)(nil)
@@ -19,48 +21,54 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		NoPruning               bool
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
-		DatabaseCache           int
-		TrieCleanCache          int
-		TrieDirtyCache          int
-		TrieTimeout             time.Duration
-		Etherbase               common.Address `toml:",omitempty"`
-		MinerNotify             []string       `toml:",omitempty"`
-		MinerExtraData          hexutil.Bytes  `toml:",omitempty"`
-		MinerGasFloor           uint64
-		MinerGasCeil            uint64
-		MinerGasPrice           *big.Int
-		MinerRecommit           time.Duration
-		MinerNoverify           bool
-		Ethash                  ethash.Config
-		TxPool                  core.TxPoolConfig
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
-		EWASMInterpreter        string
-		EVMInterpreter          string
-		Istanbul                istanbul.Config
+		Genesis                     *core.Genesis `toml:",omitempty"`
+		NetworkId                   uint64
+		SyncMode                    downloader.SyncMode
+		NoPruning                   bool
+		Whitelist                   map[uint64]common.Hash `toml:"-"`
+		LightServ                   int                    `toml:",omitempty"`
+		LightPeers                  int                    `toml:",omitempty"`
+		Etherbase                   common.Address         `toml:",omitempty"`
+		SkipBcVersionCheck          bool                   `toml:"-"`
+		DatabaseHandles             int                    `toml:"-"`
+		DatabaseCache               int
+		TrieCleanCache              int
+		TrieDirtyCache              int
+		TrieTimeout                 time.Duration
+		MinerNotify                 []string      `toml:",omitempty"`
+		MinerExtraData              hexutil.Bytes `toml:",omitempty"`
+		MinerGasFloor               uint64
+		MinerGasCeil                uint64
+		MinerGasPrice               *big.Int
+		MinerRecommit               time.Duration
+		MinerNoverify               bool
+		MinerVerificationServiceUrl string `toml:",omitempty"`
+		Attestation                 abe.Config
+		Ethash                      ethash.Config
+		TxPool                      core.TxPoolConfig
+		GPO                         gasprice.Config
+		EnablePreimageRecording     bool
+		Istanbul                    istanbul.Config
+		DocRoot                     string `toml:"-"`
+		EWASMInterpreter            string
+		EVMInterpreter              string
+		ConstantinopleOverride      *big.Int
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
 	enc.NoPruning = c.NoPruning
+	enc.Whitelist = c.Whitelist
 	enc.LightServ = c.LightServ
 	enc.LightPeers = c.LightPeers
+	enc.Etherbase = c.Etherbase
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
-	enc.Etherbase = c.Etherbase
 	enc.MinerNotify = c.MinerNotify
 	enc.MinerExtraData = c.MinerExtraData
 	enc.MinerGasFloor = c.MinerGasFloor
@@ -68,46 +76,55 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.MinerGasPrice = c.MinerGasPrice
 	enc.MinerRecommit = c.MinerRecommit
 	enc.MinerNoverify = c.MinerNoverify
+	enc.MinerVerificationServiceUrl = c.MinerVerificationServiceUrl
+	enc.Attestation = c.Attestation
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
+	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.Istanbul = c.Istanbul
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
 	enc.EVMInterpreter = c.EVMInterpreter
+	enc.ConstantinopleOverride = c.ConstantinopleOverride
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		NoPruning               *bool
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
-		DatabaseCache           *int
-		TrieCleanCache          *int
-		TrieDirtyCache          *int
-		TrieTimeout             *time.Duration
-		Etherbase               *common.Address `toml:",omitempty"`
-		MinerNotify             []string        `toml:",omitempty"`
-		MinerExtraData          *hexutil.Bytes  `toml:",omitempty"`
-		MinerGasFloor           *uint64
-		MinerGasCeil            *uint64
-		MinerGasPrice           *big.Int
-		MinerRecommit           *time.Duration
-		MinerNoverify           *bool
-		Ethash                  *ethash.Config
-		TxPool                  *core.TxPoolConfig
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
-		EWASMInterpreter        *string
-		EVMInterpreter          *string
-		Istanbul                *istanbul.Config
+		Genesis                     *core.Genesis `toml:",omitempty"`
+		NetworkId                   *uint64
+		SyncMode                    *downloader.SyncMode
+		NoPruning                   *bool
+		Whitelist                   map[uint64]common.Hash `toml:"-"`
+		LightServ                   *int                   `toml:",omitempty"`
+		LightPeers                  *int                   `toml:",omitempty"`
+		Etherbase                   *common.Address        `toml:",omitempty"`
+		SkipBcVersionCheck          *bool                  `toml:"-"`
+		DatabaseHandles             *int                   `toml:"-"`
+		DatabaseCache               *int
+		TrieCleanCache              *int
+		TrieDirtyCache              *int
+		TrieTimeout                 *time.Duration
+		MinerNotify                 []string       `toml:",omitempty"`
+		MinerExtraData              *hexutil.Bytes `toml:",omitempty"`
+		MinerGasFloor               *uint64
+		MinerGasCeil                *uint64
+		MinerGasPrice               *big.Int
+		MinerRecommit               *time.Duration
+		MinerNoverify               *bool
+		MinerVerificationServiceUrl *string `toml:",omitempty"`
+		Attestation                 *abe.Config
+		Ethash                      *ethash.Config
+		TxPool                      *core.TxPoolConfig
+		GPO                         *gasprice.Config
+		EnablePreimageRecording     *bool
+		Istanbul                    *istanbul.Config
+		DocRoot                     *string `toml:"-"`
+		EWASMInterpreter            *string
+		EVMInterpreter              *string
+		ConstantinopleOverride      *big.Int
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -125,12 +142,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoPruning != nil {
 		c.NoPruning = *dec.NoPruning
 	}
+	if dec.Whitelist != nil {
+		c.Whitelist = dec.Whitelist
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
 	if dec.LightPeers != nil {
 		c.LightPeers = *dec.LightPeers
 	}
+	if dec.Etherbase != nil {
+		c.Etherbase = *dec.Etherbase
+	}
 	if dec.SkipBcVersionCheck != nil {
 		c.SkipBcVersionCheck = *dec.SkipBcVersionCheck
 	}
@@ -149,9 +172,6 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TrieTimeout != nil {
 		c.TrieTimeout = *dec.TrieTimeout
 	}
-	if dec.Etherbase != nil {
-		c.Etherbase = *dec.Etherbase
-	}
 	if dec.MinerNotify != nil {
 		c.MinerNotify = dec.MinerNotify
 	}
@@ -173,12 +193,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.MinerNoverify != nil {
 		c.MinerNoverify = *dec.MinerNoverify
 	}
+	if dec.MinerVerificationServiceUrl != nil {
+		c.MinerVerificationServiceUrl = *dec.MinerVerificationServiceUrl
+	}
+	if dec.Attestation != nil {
+		c.Attestation = *dec.Attestation
+	}
 	if dec.Ethash != nil {
 		c.Ethash = *dec.Ethash
 	}
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
+	if dec.GPO != nil {
+		c.GPO = *dec.GPO
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
@@ -194,5 +223,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EVMInterpreter != nil {
 		c.EVMInterpreter = *dec.EVMInterpreter
 	}
+	if dec.ConstantinopleOverride != nil {
+		c.ConstantinopleOverride = dec.ConstantinopleOverride
+	}
 	return nil
 }
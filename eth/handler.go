@@ -242,6 +242,10 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	// start sync handlers
 	go pm.syncer()
 	go pm.txsyncLoop()
+
+	if handler, ok := pm.engine.(consensus.Handler); ok {
+		handler.ConnectSentryPeers()
+	}
 }
 
 func (pm *ProtocolManager) Stop() {
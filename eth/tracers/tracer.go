@@ -297,6 +297,9 @@ type Tracer struct {
 	errorValue  *string // Swappable error value wrapped by a log accessor
 	refundValue *uint   // Swappable refund value wrapped by a log accessor
 
+	tobinTaxValue *vm.TobinTax // Swappable tobin tax wrapped by a tax accessor
+	traceTobinTax bool         // Whether the tracer exposes the optional tobinTax function
+
 	ctx map[string]interface{} // Transaction context gathered throughout execution
 	err error                  // Error, if one has occurred
 
@@ -306,7 +309,7 @@ type Tracer struct {
 
 // New instantiates a new tracer instance. code specifies a Javascript snippet,
 // which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
+// and 'result' functions, and optionally a 'tobinTax' function.
 func New(code string) (*Tracer, error) {
 	// Resolve any tracers by name and assemble the tracer object
 	if tracer, ok := tracer(code); ok {
@@ -325,6 +328,7 @@ func New(code string) (*Tracer, error) {
 		costValue:       new(uint),
 		depthValue:      new(uint),
 		refundValue:     new(uint),
+		tobinTaxValue:   new(vm.TobinTax),
 	}
 	// Set up builtins for this environment
 	tracer.vm.PushGlobalGoFunction("toHex", func(ctx *duktape.Context) int {
@@ -433,6 +437,9 @@ func New(code string) (*Tracer, error) {
 	}
 	tracer.vm.Pop()
 
+	tracer.traceTobinTax = tracer.vm.GetPropString(tracer.tracerObject, "tobinTax")
+	tracer.vm.Pop()
+
 	// Tracer is valid, inject the big int library to access large numbers
 	tracer.vm.EvalString(bigIntegerJS)
 	tracer.vm.PutGlobalString("bigInt")
@@ -481,6 +488,37 @@ func New(code string) (*Tracer, error) {
 
 	tracer.vm.PutPropString(tracer.stateObject, "log")
 
+	taxObject := tracer.vm.PushObject()
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int {
+		copy(makeSlice(ctx.PushFixedBuffer(20), 20), tracer.tobinTaxValue.From[:])
+		return 1
+	})
+	tracer.vm.PutPropString(taxObject, "getFrom")
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int {
+		copy(makeSlice(ctx.PushFixedBuffer(20), 20), tracer.tobinTaxValue.To[:])
+		return 1
+	})
+	tracer.vm.PutPropString(taxObject, "getTo")
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int {
+		copy(makeSlice(ctx.PushFixedBuffer(20), 20), tracer.tobinTaxValue.Reserve[:])
+		return 1
+	})
+	tracer.vm.PutPropString(taxObject, "getReserve")
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { pushBigInt(tracer.tobinTaxValue.Amount, ctx); return 1 })
+	tracer.vm.PutPropString(taxObject, "getAmount")
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { pushBigInt(tracer.tobinTaxValue.Tax, ctx); return 1 })
+	tracer.vm.PutPropString(taxObject, "getTax")
+
+	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushInt(tracer.tobinTaxValue.Depth); return 1 })
+	tracer.vm.PutPropString(taxObject, "getDepth")
+
+	tracer.vm.PutPropString(tracer.stateObject, "tax")
+
 	tracer.dbWrapper.pushObject(tracer.vm)
 	tracer.vm.PutPropString(tracer.stateObject, "db")
 
@@ -585,6 +623,21 @@ func (jst *Tracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 	return nil
 }
 
+// CaptureTobinTax implements the Tracer interface to trace the tobin tax taken
+// from a value transfer. It is only forwarded to tracers exposing a 'tobinTax'
+// function.
+func (jst *Tracer) CaptureTobinTax(env *vm.EVM, tax *vm.TobinTax) error {
+	if jst.err == nil && jst.traceTobinTax {
+		*jst.tobinTaxValue = *tax
+		jst.dbWrapper.db = env.StateDB
+
+		if _, err := jst.call("tobinTax", "tax", "db"); err != nil {
+			jst.err = wrapError("tobinTax", err)
+		}
+	}
+	return nil
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	jst.ctx["output"] = output
@@ -108,6 +108,23 @@ func TestOpcodes(t *testing.T) {
 	}
 }
 
+func TestTobinTax(t *testing.T) {
+	tracer, err := New("{taxes: [], step: function() {}, fault: function() {}, tobinTax: function(tax) { this.taxes.push(toHex(tax.getTo()) + ':' + tax.getTax() + '/' + tax.getAmount() + '@' + tax.getDepth()); }, result: function() { return this.taxes; }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, &dummyStatedb{}, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	tracer.CaptureTobinTax(env, &vm.TobinTax{To: common.HexToAddress("0x01"), Amount: big.NewInt(100), Tax: big.NewInt(10), Depth: 1})
+
+	ret, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["0x0000000000000000000000000000000000000001:10/100@1"]`; string(ret) != want {
+		t.Errorf("Expected return value to be %s, got %s", want, string(ret))
+	}
+}
+
 func TestHalt(t *testing.T) {
 	t.Skip("duktape doesn't support abortion")
 
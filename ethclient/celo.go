@@ -0,0 +1,75 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Outcomes of checking a reveal against the commitment its proposer had on
+// record before the block, as reported in Randomness.Status.
+const (
+	RandomnessVerified    = "verified"    // The reveal hashes to the previous commitment
+	RandomnessMismatch    = "mismatch"    // The reveal does not hash to the previous commitment
+	RandomnessFirstReveal = "firstReveal" // The proposer had no previous commitment to check against
+)
+
+// Randomness is the randomness revealed in a block, together with the commitment
+// its proposer made for a future block. Status reports the outcome of checking
+// the reveal against the commitment the proposer had on record before the block.
+type Randomness struct {
+	Number             *big.Int
+	Revealed           common.Hash
+	Committer          common.Address
+	Commitment         common.Hash
+	PreviousCommitment common.Hash
+	Status             string
+}
+
+type rpcRandomness struct {
+	Number             hexutil.Uint64 `json:"number"`
+	Revealed           common.Hash    `json:"revealed"`
+	Committer          common.Address `json:"committer"`
+	Commitment         common.Hash    `json:"commitment"`
+	PreviousCommitment common.Hash    `json:"previousCommitment"`
+	Status             string         `json:"status"`
+}
+
+// RandomnessAt returns the randomness revealed in the block with the given
+// number. If number is nil, the latest known block is used.
+func (ec *Client) RandomnessAt(ctx context.Context, number *big.Int) (*Randomness, error) {
+	var r *rpcRandomness
+	if err := ec.c.CallContext(ctx, &r, "celo_getRandomness", toBlockNumArg(number)); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ethereum.NotFound
+	}
+	return &Randomness{
+		Number:             new(big.Int).SetUint64(uint64(r.Number)),
+		Revealed:           r.Revealed,
+		Committer:          r.Committer,
+		Commitment:         r.Commitment,
+		PreviousCommitment: r.PreviousCommitment,
+		Status:             r.Status,
+	}, nil
+}
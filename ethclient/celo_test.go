@@ -0,0 +1,86 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// CeloService serves celo_getRandomness from a fixed set of blocks.
+type CeloService struct {
+	randomness map[rpc.BlockNumber]*rpcRandomness
+}
+
+func (s *CeloService) GetRandomness(ctx context.Context, number rpc.BlockNumber) (interface{}, error) {
+	if randomness, ok := s.randomness[number]; ok {
+		return randomness, nil
+	}
+	return nil, nil
+}
+
+func TestRandomnessAt(t *testing.T) {
+	first := &rpcRandomness{
+		Number:             1,
+		Revealed:           common.HexToHash("0x01"),
+		Committer:          common.HexToAddress("0x1001"),
+		Commitment:         common.HexToHash("0x02"),
+		PreviousCommitment: common.HexToHash("0x03"),
+		Status:             RandomnessVerified,
+	}
+	latest := &rpcRandomness{
+		Number:     2,
+		Revealed:   common.HexToHash("0x04"),
+		Committer:  common.HexToAddress("0x1002"),
+		Commitment: common.HexToHash("0x05"),
+		Status:     RandomnessFirstReveal,
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	service := &CeloService{randomness: map[rpc.BlockNumber]*rpcRandomness{1: first, rpc.LatestBlockNumber: latest}}
+	if err := server.RegisterName("celo", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	tests := []struct {
+		number *big.Int
+		want   *Randomness
+		err    error
+	}{
+		{big.NewInt(1), &Randomness{big.NewInt(1), first.Revealed, first.Committer, first.Commitment, first.PreviousCommitment, RandomnessVerified}, nil},
+		{nil, &Randomness{big.NewInt(2), latest.Revealed, latest.Committer, latest.Commitment, common.Hash{}, RandomnessFirstReveal}, nil},
+		{big.NewInt(3), nil, ethereum.NotFound},
+	}
+	for _, tt := range tests {
+		randomness, err := client.RandomnessAt(context.Background(), tt.number)
+		if err != tt.err {
+			t.Errorf("block %v: error mismatch: have %v, want %v", tt.number, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(randomness, tt.want) {
+			t.Errorf("block %v: randomness mismatch: have %+v, want %+v", tt.number, randomness, tt.want)
+		}
+	}
+}
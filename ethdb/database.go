@@ -19,12 +19,14 @@
 package ethdb
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -382,3 +384,26 @@ func (b *ldbBatch) Reset() {
 	b.b.Reset()
 	b.size = 0
 }
+
+// KeysWithPrefix returns all keys in db that start with the given prefix.
+func KeysWithPrefix(db Database, prefix []byte) ([][]byte, error) {
+	var keys [][]byte
+	switch db := db.(type) {
+	case *LDBDatabase:
+		it := db.NewIteratorWithPrefix(prefix)
+		defer it.Release()
+		for it.Next() {
+			keys = append(keys, common.CopyBytes(it.Key()))
+		}
+		return keys, it.Error()
+	case *MemDatabase:
+		for _, key := range db.Keys() {
+			if bytes.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("database %T cannot be iterated", db)
+	}
+}
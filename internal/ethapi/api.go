@@ -57,12 +57,43 @@ func NewPublicEthereumAPI(b Backend) *PublicEthereumAPI {
 	return &PublicEthereumAPI{b}
 }
 
-// GasPrice returns a suggestion for a gas price.
-func (s *PublicEthereumAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
-	price, err := s.b.SuggestPrice(ctx)
+// GasPrice returns a suggestion for a gas price in the given currency, or in gold
+// if no currency is given.
+func (s *PublicEthereumAPI) GasPrice(ctx context.Context, currency *common.Address) (*hexutil.Big, error) {
+	if currency == nil {
+		price, err := s.b.SuggestPrice(ctx)
+		return (*hexutil.Big)(price), err
+	}
+	price, err := s.b.SuggestPriceInCurrency(ctx, currency)
 	return (*hexutil.Big)(price), err
 }
 
+// GasPrices returns a suggestion for a gas price in gold and in every whitelisted
+// gas currency, keyed by the address of the currency's token contract.
+func (s *PublicEthereumAPI) GasPrices(ctx context.Context) (map[common.Address]*hexutil.Big, error) {
+	prices := make(map[common.Address]*hexutil.Big)
+
+	goldTokenAddress, err := s.b.RegisteredAddresses().GetRegisteredAddressAtCurrentHeader(params.GoldTokenRegistryId)
+	if err != nil {
+		return nil, err
+	}
+	price, err := s.b.SuggestPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prices[*goldTokenAddress] = (*hexutil.Big)(price)
+
+	for _, currency := range s.b.GasCurrencyWhitelist().Whitelist() {
+		currency := currency
+		price, err := s.b.SuggestPriceInCurrency(ctx, &currency)
+		if err != nil {
+			return nil, err
+		}
+		prices[currency] = (*hexutil.Big)(price)
+	}
+	return prices, nil
+}
+
 // ProtocolVersion returns the current Ethereum protocol version this node supports
 func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 	return hexutil.Uint(s.b.ProtocolVersion())
@@ -130,11 +161,27 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 }
 
 // Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
-	pending, queue := s.b.Stats()
-	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+//
+// The counts are also broken down by gas currency, gold being keyed by "gold".
+func (s *PublicTxPoolAPI) Status() map[string]interface{} {
+	var pending, queue int
+	currencies := make(map[string]map[string]hexutil.Uint)
+	for currency, stats := range s.b.TxPoolCurrencyStats() {
+		name := "gold"
+		if currency != (common.Address{}) {
+			name = currency.Hex()
+		}
+		currencies[name] = map[string]hexutil.Uint{
+			"pending": hexutil.Uint(stats.Pending),
+			"queued":  hexutil.Uint(stats.Queued),
+		}
+		pending += stats.Pending
+		queue += stats.Queued
+	}
+	return map[string]interface{}{
+		"pending":    hexutil.Uint(pending),
+		"queued":     hexutil.Uint(queue),
+		"currencies": currencies,
 	}
 }
 
@@ -681,17 +728,35 @@ type CallArgs struct {
 	Data            hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, timeout time.Duration) ([]byte, uint64, bool, error) {
+// DoCall executes the given call on the state of the given block number, and
+// returns its result, the gas used and whether the execution failed.
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber, timeout time.Duration) ([]byte, uint64, bool, error) {
+	result, err := doCall(ctx, b, args, blockNr, timeout)
+	if result == nil {
+		return nil, 0, false, err
+	}
+	return result.ret, result.gasUsed, result.failed, err
+}
+
+// callResult is the outcome of a call executed by doCall.
+type callResult struct {
+	ret      []byte
+	gasUsed  uint64
+	failed   bool
+	tobinTax *big.Int // Total tobin tax taken from the value transfers of the call
+}
+
+func doCall(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber, timeout time.Duration) (*callResult, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	state, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
-		return nil, 0, false, err
+		return nil, err
 	}
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -706,7 +771,7 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// which will always be in Gold. This allows the default price to be set for the proper currency.
 	// TODO(asa): Remove this once this is handled in the Provider.
 	if gasPrice.Sign() == 0 || gasPrice.Cmp(big.NewInt(0)) == 0 {
-		gasPrice, err = s.b.SuggestPriceInCurrency(ctx, args.GasCurrency)
+		gasPrice, err = b.SuggestPriceInCurrency(ctx, args.GasCurrency)
 	}
 
 	// Create new call message
@@ -725,12 +790,12 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	defer cancel()
 
 	// Needed so that the values returned by estimate gas, view functions, are correct.
-	s.b.GasCurrencyWhitelist().RefreshWhitelistAtStateAndHeader(state, header)
+	b.GasCurrencyWhitelist().RefreshWhitelistAtStateAndHeader(state, header)
 
 	// Get a new instance of the EVM.
-	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header)
 	if err != nil {
-		return nil, 0, false, err
+		return nil, err
 	}
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
@@ -743,52 +808,70 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
 
-	gasPriceMinimum, err := s.b.GasPriceMinimum().GetGasPriceMinimum(args.GasCurrency, state, header)
-	infraFraction, err := s.b.GasPriceMinimum().GetInfrastructureFraction(state, header)
-	infraAddress, _ := s.b.RegisteredAddresses().GetRegisteredAddressAtStateAndHeader(params.GovernanceRegistryId, state, header)
-	res, gas, failed, err := core.ApplyMessage(evm, msg, gp, s.b.GasCurrencyWhitelist(), gasPriceMinimum, infraFraction, infraAddress)
+	gasPriceMinimum, err := b.GasPriceMinimum().GetGasPriceMinimum(args.GasCurrency, state, header)
+	infraFraction, err := b.GasPriceMinimum().GetInfrastructureFraction(state, header)
+	infraAddress, _ := b.RegisteredAddresses().GetRegisteredAddressAtStateAndHeader(params.GovernanceRegistryId, state, header)
+	res, gas, failed, err := core.ApplyMessage(evm, msg, gp, b.GasCurrencyWhitelist(), gasPriceMinimum, infraFraction, infraAddress)
 	if err := vmError(); err != nil {
-		return nil, 0, false, err
+		return nil, err
 	}
-	return res, gas, failed, err
+	return &callResult{ret: res, gasUsed: gas, failed: failed, tobinTax: evm.TotalTobinTax()}, err
 }
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, 5*time.Second)
+	result, _, _, err := DoCall(ctx, s.b, args, blockNr, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
 func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+	return DoEstimateGas(ctx, s.b, args)
+}
+
+// DoEstimateGas binary searches the lowest gas allowance the given call can be
+// executed with against the current pending block.
+func DoEstimateGas(ctx context.Context, b Backend, args CallArgs) (hexutil.Uint64, error) {
+	gas, _, err := doEstimateGas(ctx, b, args)
+	return gas, err
+}
+
+// doEstimateGas binary searches the lowest gas allowance the given call can be
+// executed with, and returns it along with the result of the call executed with
+// that allowance.
+func doEstimateGas(ctx context.Context, b Backend, args CallArgs) (hexutil.Uint64, *callResult, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
-		lo  uint64 = params.TxGas - 1
-		hi  uint64
-		cap uint64
+		lo     uint64 = params.TxGas - 1
+		hi     uint64
+		cap    uint64
+		result *callResult
 	)
 	if uint64(args.Gas) >= params.TxGas {
 		hi = uint64(args.Gas)
 	} else {
 		// Retrieve the current pending block to act as the gas ceiling
-		block, err := s.b.BlockByNumber(ctx, rpc.PendingBlockNumber)
+		block, err := b.BlockByNumber(ctx, rpc.PendingBlockNumber)
 		if err != nil {
-			return 0, err
+			return 0, nil, err
 		}
 		hi = block.GasLimit()
 	}
 	cap = hi
 
-	// Create a helper to check if a gas allowance results in an executable transaction
+	// Create a helper to check if a gas allowance results in an executable transaction.
+	// The allowances that succeed only ever decrease, so the last successful call is
+	// the one made with the allowance returned.
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, 0)
-		if err != nil || failed {
+		res, err := doCall(ctx, b, args, rpc.PendingBlockNumber, 0)
+		if err != nil || res.failed {
 			return false
 		}
+		result = res
 		return true
 	}
 	// Execute the binary search and hone in on an executable gas limit
@@ -803,20 +886,24 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	// Reject the transaction as invalid if it still fails at the highest allowance
 	if hi == cap {
 		if !executable(hi) {
-			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
+			return 0, nil, fmt.Errorf("gas required exceeds allowance or always failing transaction")
 		}
 	}
-	return hexutil.Uint64(hi), nil
+	return hexutil.Uint64(hi), result, nil
 }
 
 // ExecutionResult groups all structured logs emitted by the EVM
 // while replaying a transaction in debug mode as well as transaction
-// execution status, the amount of gas used and the return value
+// execution status, the amount of gas used and the return value.
+// TobinTaxes lists the tobin taxes taken by every call frame, while
+// TobinTax is the total of those that were not reverted.
 type ExecutionResult struct {
 	Gas         uint64         `json:"gas"`
 	Failed      bool           `json:"failed"`
 	ReturnValue string         `json:"returnValue"`
 	StructLogs  []StructLogRes `json:"structLogs"`
+	TobinTax    *hexutil.Big   `json:"tobinTax"`
+	TobinTaxes  []TobinTaxRes  `json:"tobinTaxes"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
@@ -833,6 +920,33 @@ type StructLogRes struct {
 	Storage *map[string]string `json:"storage,omitempty"`
 }
 
+// TobinTaxRes stores a tobin tax taken from a value transfer while replaying a
+// transaction in debug mode
+type TobinTaxRes struct {
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Reserve  common.Address `json:"reserve"`
+	Amount   *hexutil.Big   `json:"amount"`
+	TobinTax *hexutil.Big   `json:"tobinTax"`
+	Depth    int            `json:"depth"`
+}
+
+// FormatTobinTaxes formats EVM returned tobin taxes for json output
+func FormatTobinTaxes(taxes []vm.TobinTax) []TobinTaxRes {
+	formatted := make([]TobinTaxRes, len(taxes))
+	for index, tax := range taxes {
+		formatted[index] = TobinTaxRes{
+			From:     tax.From,
+			To:       tax.To,
+			Reserve:  tax.Reserve,
+			Amount:   (*hexutil.Big)(tax.Amount),
+			TobinTax: (*hexutil.Big)(tax.Tax),
+			Depth:    tax.Depth,
+		}
+	}
+	return formatted
+}
+
 // formatLogs formats EVM returned structured logs for json output
 func FormatLogs(logs []vm.StructLog) []StructLogRes {
 	formatted := make([]StructLogRes, len(logs))
@@ -1170,6 +1284,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		"contractAddress":   nil,
 		"logs":              receipt.Logs,
 		"logsBloom":         receipt.Bloom,
+		"tobinTax":          (*hexutil.Big)(receipt.TobinTax),
 	}
 
 	// Assign receipt status or post state.
@@ -0,0 +1,414 @@
+// Copyright 2017 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxFeeHistoryBlocks is the maximum number of blocks a single fee history
+	// request may cover.
+	maxFeeHistoryBlocks = 1024
+
+	// maxFeeHistoryPercentiles is the maximum number of gas price percentiles a
+	// single fee history request may ask for.
+	maxFeeHistoryPercentiles = 100
+)
+
+// PublicCeloAPI provides an API to access Celo specific information, such as
+// the gas price minimum and the routing of transaction fees.
+type PublicCeloAPI struct {
+	b Backend
+}
+
+// NewPublicCeloAPI creates a new Celo API.
+func NewPublicCeloAPI(b Backend) *PublicCeloAPI {
+	return &PublicCeloAPI{b}
+}
+
+// InfrastructureFractionResult is the fraction of the gas price minimum that is
+// routed to the infrastructure fund.
+type InfrastructureFractionResult struct {
+	Numerator   *hexutil.Big `json:"numerator"`
+	Denominator *hexutil.Big `json:"denominator"`
+}
+
+// FeeHistoryBlock is the fee information of a single block. All maps are keyed by
+// the address of the currency's token contract, fees and prices being denominated
+// in the currency they were paid in. GasPricePercentiles holds, for every currency
+// gas was paid in, the gas prices at the requested percentiles of that currency's
+// gas used.
+type FeeHistoryBlock struct {
+	Number                 hexutil.Uint64                    `json:"number"`
+	GasUsedRatio           float64                           `json:"gasUsedRatio"`
+	GasPriceMinimums       map[common.Address]*hexutil.Big   `json:"gasPriceMinimums"`
+	GasPricePercentiles    map[common.Address][]*hexutil.Big `json:"gasPricePercentiles,omitempty"`
+	InfrastructureFraction *InfrastructureFractionResult     `json:"infrastructureFraction"`
+	InfrastructureFees     map[common.Address]*hexutil.Big   `json:"infrastructureFees"`
+	FeeRecipientFees       map[common.Address]*hexutil.Big   `json:"feeRecipientFees"`
+}
+
+// FeeHistoryResult is the fee information of a range of blocks, oldest first.
+type FeeHistoryResult struct {
+	OldestBlock hexutil.Uint64     `json:"oldestBlock"`
+	Blocks      []*FeeHistoryBlock `json:"blocks"`
+}
+
+// FeeHistory returns the fee information of blockCount blocks up to and including
+// newestBlock. For every block it reports the gas price minimum in the requested
+// currencies (gold and all whitelisted currencies if none are given), the gas
+// used ratio, the infrastructure fraction and the fees routed to the
+// infrastructure fund and to the fee recipients, as they applied while the block
+// was processed, along with the gas prices paid at the given percentiles, in
+// increasing order, of each currency's gas used.
+func (s *PublicCeloAPI) FeeHistory(ctx context.Context, blockCount hexutil.Uint64, newestBlock rpc.BlockNumber, currencies []common.Address, percentiles []float64) (*FeeHistoryResult, error) {
+	if blockCount == 0 {
+		return &FeeHistoryResult{}, nil
+	}
+	if blockCount > maxFeeHistoryBlocks {
+		return nil, fmt.Errorf("block count %d exceeds limit of %d", blockCount, maxFeeHistoryBlocks)
+	}
+	if len(percentiles) > maxFeeHistoryPercentiles {
+		return nil, fmt.Errorf("percentile count %d exceeds limit of %d", len(percentiles), maxFeeHistoryPercentiles)
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 || (i > 0 && p < percentiles[i-1]) {
+			return nil, fmt.Errorf("invalid percentile %v at index %d", p, i)
+		}
+	}
+	if newestBlock == rpc.PendingBlockNumber {
+		newestBlock = rpc.LatestBlockNumber
+	}
+	newest, err := s.b.HeaderByNumber(ctx, newestBlock)
+	if newest == nil || err != nil {
+		return nil, err
+	}
+	oldest := uint64(0)
+	if newest.Number.Uint64() >= uint64(blockCount) {
+		oldest = newest.Number.Uint64() - uint64(blockCount) + 1
+	}
+
+	result := &FeeHistoryResult{OldestBlock: hexutil.Uint64(oldest)}
+	for number := oldest; number <= newest.Number.Uint64(); number++ {
+		block, err := s.feeHistoryBlock(ctx, number, currencies, percentiles)
+		if err != nil {
+			return nil, err
+		}
+		result.Blocks = append(result.Blocks, block)
+	}
+	return result, nil
+}
+
+// feeHistoryBlock assembles the fee information of the given block, evaluating
+// the gas price minimum and the infrastructure fraction on the state the block
+// was processed on.
+func (s *PublicCeloAPI) feeHistoryBlock(ctx context.Context, number uint64, currencies []common.Address, percentiles []float64) (*FeeHistoryBlock, error) {
+	block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("receipts of block %d not found", number)
+	}
+	// The genesis block contains no transactions, use its own state
+	parentNumber := number
+	if number > 0 {
+		parentNumber = number - 1
+	}
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(parentNumber))
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	header := block.Header()
+
+	result := &FeeHistoryBlock{
+		Number:             hexutil.Uint64(number),
+		GasPriceMinimums:   make(map[common.Address]*hexutil.Big),
+		InfrastructureFees: make(map[common.Address]*hexutil.Big),
+		FeeRecipientFees:   make(map[common.Address]*hexutil.Big),
+	}
+	if header.GasLimit > 0 {
+		result.GasUsedRatio = float64(header.GasUsed) / float64(header.GasLimit)
+	}
+	// Gold is reported under the address of the GoldToken contract, if deployed
+	var goldAddress common.Address
+	if address, err := s.b.RegisteredAddresses().GetRegisteredAddressAtStateAndHeader(params.GoldTokenRegistryId, statedb, header); err == nil {
+		goldAddress = *address
+	}
+	currencyKey := func(currency *common.Address) common.Address {
+		if currency == nil {
+			return goldAddress
+		}
+		return *currency
+	}
+	gasPriceMinimums := make(map[common.Address]*big.Int)
+	gasPriceMinimum := func(currency *common.Address) *big.Int {
+		key := currencyKey(currency)
+		if minimum, ok := gasPriceMinimums[key]; ok {
+			return minimum
+		}
+		minimum, _ := s.b.GasPriceMinimum().GetGasPriceMinimum(currency, statedb, header)
+		if minimum == nil {
+			minimum = core.FallbackGasPriceMinimum
+		}
+		gasPriceMinimums[key] = minimum
+		return minimum
+	}
+
+	if len(currencies) == 0 {
+		whitelist, _ := s.b.GasCurrencyWhitelist().WhitelistAtStateAndHeader(statedb, header)
+		currencies = append([]common.Address{goldAddress}, whitelist...)
+	}
+	for _, currency := range currencies {
+		currency := currency
+		if currency == goldAddress {
+			result.GasPriceMinimums[currency] = (*hexutil.Big)(gasPriceMinimum(nil))
+		} else {
+			result.GasPriceMinimums[currency] = (*hexutil.Big)(gasPriceMinimum(&currency))
+		}
+	}
+
+	infraFraction, _ := s.b.GasPriceMinimum().GetInfrastructureFraction(statedb, header)
+	if infraFraction == nil {
+		infraFraction = core.FallbackInfraFraction
+	}
+	result.InfrastructureFraction = &InfrastructureFractionResult{
+		Numerator:   (*hexutil.Big)(infraFraction.Numerator),
+		Denominator: (*hexutil.Big)(infraFraction.Denominator),
+	}
+	infraAddress, _ := s.b.RegisteredAddresses().GetRegisteredAddressAtStateAndHeader(params.GovernanceRegistryId, statedb, header)
+
+	infraFees := make(map[common.Address]*big.Int)
+	recipientFees := make(map[common.Address]*big.Int)
+	gasPrices := make(map[common.Address][]txGasPrice)
+	for i, tx := range block.Transactions() {
+		key := currencyKey(tx.GasCurrency())
+		if infraFees[key] == nil {
+			infraFees[key], recipientFees[key] = new(big.Int), new(big.Int)
+		}
+		gasUsed := receipts[i].GasUsed
+		gasPrices[key] = append(gasPrices[key], txGasPrice{price: tx.GasPrice(), gasUsed: gasUsed})
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), tx.GasPrice())
+		if infraAddress != nil {
+			infraFee := core.InfrastructureFee(gasUsed, gasPriceMinimum(tx.GasCurrency()), infraFraction)
+			infraFees[key].Add(infraFees[key], infraFee)
+			fee.Sub(fee, infraFee)
+		}
+		recipientFees[key].Add(recipientFees[key], fee)
+	}
+	for key := range infraFees {
+		result.InfrastructureFees[key] = (*hexutil.Big)(infraFees[key])
+		result.FeeRecipientFees[key] = (*hexutil.Big)(recipientFees[key])
+	}
+	if len(percentiles) > 0 {
+		result.GasPricePercentiles = make(map[common.Address][]*hexutil.Big)
+		for key, prices := range gasPrices {
+			result.GasPricePercentiles[key] = gasPricePercentiles(prices, percentiles)
+		}
+	}
+	return result, nil
+}
+
+// txGasPrice is the gas price a transaction paid, and the gas it was charged.
+type txGasPrice struct {
+	price   *big.Int
+	gasUsed uint64
+}
+
+// gasPricePercentiles returns the gas prices paid at the given percentiles of the
+// gas used by the given transactions, i.e. for every percentile the lowest price
+// such that the transactions paying at most that price used at least that share
+// of the gas.
+func gasPricePercentiles(prices []txGasPrice, percentiles []float64) []*hexutil.Big {
+	sort.Slice(prices, func(i, j int) bool { return prices[i].price.Cmp(prices[j].price) < 0 })
+
+	var total uint64
+	for _, price := range prices {
+		total += price.gasUsed
+	}
+	result := make([]*hexutil.Big, len(percentiles))
+
+	index, cumulative := 0, prices[0].gasUsed
+	for i, p := range percentiles {
+		threshold := uint64(float64(total) * p / 100)
+		for cumulative < threshold && index < len(prices)-1 {
+			index++
+			cumulative += prices[index].gasUsed
+		}
+		result[i] = (*hexutil.Big)(prices[index].price)
+	}
+	return result
+}
+
+// FeeEstimate is the fee a call is expected to cost when paid for in a given gas
+// currency. Fees are denominated in that currency, except for TobinTax which is
+// the gold taken from the value transferred, and GoldFee which is the gold
+// equivalent of TotalFee at the current exchange rate plus TobinTax.
+type FeeEstimate struct {
+	Gas               hexutil.Uint64  `json:"gas"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	GasPrice          *hexutil.Big    `json:"gasPrice"`
+	GasCurrency       *common.Address `json:"gasCurrency"`
+	TotalFee          *hexutil.Big    `json:"totalFee"`
+	TobinTax          *hexutil.Big    `json:"tobinTax"`
+	GoldFee           *hexutil.Big    `json:"goldFee"`
+	InfrastructureFee *hexutil.Big    `json:"infrastructureFee"`
+	FeeRecipientFee   *hexutil.Big    `json:"feeRecipientFee"`
+}
+
+// EstimateFee returns the gas limit needed to execute the given call against the
+// current pending block when paying for gas in gasCurrency (gold if nil), along
+// with the fee that call would be charged, including the additional intrinsic
+// gas and the debit and credit calls of non-gold currencies, how the fee is split
+// between the infrastructure fund and the fee recipient, and the tobin tax taken
+// from the value it transfers.
+func (s *PublicCeloAPI) EstimateFee(ctx context.Context, args CallArgs, gasCurrency *common.Address) (*FeeEstimate, error) {
+	args.GasCurrency = gasCurrency
+	if args.GasPrice.ToInt().Sign() == 0 {
+		price, err := s.b.SuggestPriceInCurrency(ctx, gasCurrency)
+		if err != nil {
+			return nil, err
+		}
+		args.GasPrice = hexutil.Big(*price)
+	}
+	// The gas charged is that of the call made with the estimated allowance
+	gas, result, err := doEstimateGas(ctx, s.b, args)
+	if err != nil {
+		return nil, err
+	}
+	gasUsed := result.gasUsed
+
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	gasPriceMinimum, _ := s.b.GasPriceMinimum().GetGasPriceMinimum(gasCurrency, statedb, header)
+	if gasPriceMinimum == nil {
+		gasPriceMinimum = core.FallbackGasPriceMinimum
+	}
+	infraFraction, _ := s.b.GasPriceMinimum().GetInfrastructureFraction(statedb, header)
+	if infraFraction == nil {
+		infraFraction = core.FallbackInfraFraction
+	}
+
+	totalFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), args.GasPrice.ToInt())
+	infraFee := new(big.Int)
+	if infraAddress, _ := s.b.RegisteredAddresses().GetRegisteredAddressAtStateAndHeader(params.GovernanceRegistryId, statedb, header); infraAddress != nil {
+		infraFee = core.InfrastructureFee(gasUsed, gasPriceMinimum, infraFraction)
+	}
+	estimate := &FeeEstimate{
+		Gas:               gas,
+		GasUsed:           hexutil.Uint64(gasUsed),
+		GasPrice:          (*hexutil.Big)(args.GasPrice.ToInt()),
+		GasCurrency:       gasCurrency,
+		TotalFee:          (*hexutil.Big)(totalFee),
+		TobinTax:          (*hexutil.Big)(result.tobinTax),
+		InfrastructureFee: (*hexutil.Big)(infraFee),
+		FeeRecipientFee:   (*hexutil.Big)(new(big.Int).Sub(totalFee, infraFee)),
+	}
+	// The gold equivalent is left empty if the exchange rate is unknown
+	if gasCurrency == nil {
+		estimate.GoldFee = (*hexutil.Big)(new(big.Int).Add(totalFee, result.tobinTax))
+	} else if co := s.b.CurrencyOperator(); co != nil {
+		if goldFee, err := co.Convert(totalFee, gasCurrency, nil); err == nil {
+			estimate.GoldFee = (*hexutil.Big)(goldFee.Add(goldFee, result.tobinTax))
+		}
+	}
+	return estimate, nil
+}
+
+// Outcomes of checking a reveal against the commitment its proposer had on record.
+const (
+	randomnessVerified    = "verified"    // The reveal hashes to the previous commitment
+	randomnessMismatch    = "mismatch"    // The reveal does not hash to the previous commitment
+	randomnessFirstReveal = "firstReveal" // The proposer had no previous commitment to check against
+)
+
+// RandomnessResult is the randomness revealed in a block, together with the
+// commitment its proposer made for a future block.
+type RandomnessResult struct {
+	Number             hexutil.Uint64 `json:"number"`
+	Revealed           common.Hash    `json:"revealed"`
+	Committer          common.Address `json:"committer"`
+	Commitment         common.Hash    `json:"commitment"`
+	PreviousCommitment common.Hash    `json:"previousCommitment"`
+	Status             string         `json:"status"`
+}
+
+// GetRandomness returns the randomness revealed by the proposer of the given
+// block and the new commitment it made. The reveal is verified against the
+// commitment the proposer had on record before the block, by hashing it with the
+// Random contract's computeCommitment. A proposer's first reveal has no previous
+// commitment to be verified against, and is reported as such.
+func (s *PublicCeloAPI) GetRandomness(ctx context.Context, blockNr rpc.BlockNumber) (*RandomnessResult, error) {
+	block, err := s.b.BlockByNumber(ctx, blockNr)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	number := block.NumberU64()
+	if number == 0 {
+		return nil, fmt.Errorf("genesis block reveals no randomness")
+	}
+	random := s.b.Random()
+	if random == nil {
+		return nil, fmt.Errorf("randomness not available")
+	}
+	// The reveal is checked against the state the block was processed on
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(number-1))
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	header := block.Header()
+
+	result := &RandomnessResult{
+		Number:     hexutil.Uint64(number),
+		Revealed:   block.Randomness().Revealed,
+		Committer:  header.Coinbase,
+		Commitment: block.Randomness().Committed,
+	}
+	if result.PreviousCommitment, err = random.GetCommitment(header.Coinbase, header, statedb); err != nil {
+		return nil, err
+	}
+	if (result.PreviousCommitment == common.Hash{}) {
+		result.Status = randomnessFirstReveal
+		return result, nil
+	}
+	computed, err := random.ComputeCommitment(result.Revealed, header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	if computed == result.PreviousCommitment {
+		result.Status = randomnessVerified
+	} else {
+		result.Status = randomnessMismatch
+	}
+	return result, nil
+}
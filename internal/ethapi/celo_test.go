@@ -0,0 +1,488 @@
+// Copyright 2019 The Celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// testRegistryCode is a stand-in for the Registry contract that answers
+	// getAddressFor(identifier) with the storage slot keyed by the identifier.
+	//
+	// PUSH1 0x44 CALLDATALOAD SLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	testRegistryCode = common.FromHex("0x6044355460005260206000f3")
+
+	// testWhitelistCode is a stand-in for the GasCurrencyWhitelist contract that
+	// answers every call with the first three storage slots, the ABI encoding of
+	// a whitelist of a single currency.
+	//
+	// PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 1 SLOAD PUSH1 32 MSTORE PUSH1 2 SLOAD
+	// PUSH1 64 MSTORE PUSH1 96 PUSH1 0 RETURN
+	testWhitelistCode = common.FromHex("0x60005460005260015460205260025460405260606000f3")
+
+	// testGasPriceMinimumCode is a stand-in for the GasPriceMinimum contract that
+	// answers a call with the storage slot keyed by its first argument and the
+	// next one, i.e. getGasPriceMinimum(currency) with the slot of the currency,
+	// and infrastructureFraction() with the first two slots.
+	//
+	// PUSH1 4 CALLDATALOAD DUP1 SLOAD PUSH1 0 MSTORE PUSH1 1 ADD SLOAD PUSH1 32
+	// MSTORE PUSH1 64 PUSH1 0 RETURN
+	testGasPriceMinimumCode = common.FromHex("0x60043580546000526001015460205260406000f3")
+
+	// testGasCurrencyCode is a stand-in for a gas currency contract that answers
+	// every call, be it balanceOf, debitFrom or creditTo, with a large balance.
+	//
+	// PUSH8 0xffffffffffffffff PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	testGasCurrencyCode = common.FromHex("0x67ffffffffffffffff60005260206000f3")
+
+	// testReserveCode is a stand-in for the Reserve contract that answers every
+	// call with a tobin tax of 1/10.
+	//
+	// PUSH1 1 PUSH1 0 MSTORE PUSH1 10 PUSH1 32 MSTORE PUSH1 64 PUSH1 0 RETURN
+	testReserveCode = common.FromHex("0x6001600052600a60205260406000f3")
+
+	// testRandomCode is a stand-in for the Random contract that answers
+	// commitments(address) with the storage slot keyed by the address, and
+	// computeCommitment(randomness) with the hash of the randomness.
+	//
+	// PUSH1 0 CALLDATALOAD PUSH29 0x01<<224 SWAP1 DIV PUSH4 0xe8fcf723 EQ
+	// PUSH1 63 JUMPI PUSH1 4 CALLDATALOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 SHA3
+	// PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN JUMPDEST PUSH1 4 CALLDATALOAD SLOAD
+	// PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	testRandomCode = common.FromHex("0x6000357c0100000000000000000000000000000000000000000000000000000000900463e8fcf72314603f57600435600052602060002060005260206000f35b6004355460005260206000f3")
+)
+
+var (
+	testRegistry        = common.HexToAddress("0xce10")
+	testGoldToken       = common.HexToAddress("0xd001")
+	testWhitelist       = common.HexToAddress("0xd002")
+	testGasPriceMinimum = common.HexToAddress("0xd003")
+	testGovernance      = common.HexToAddress("0xd004")
+	testReserve         = common.HexToAddress("0xd005")
+	testRandom          = common.HexToAddress("0xd006")
+	testCurrency        = common.HexToAddress("0xc0ffee")
+
+	testGasPriceMinimums = map[common.Address]int64{testGoldToken: 5, testCurrency: 7}
+	testInfraFraction    = [2]int64{1, 2}
+)
+
+// testSystemContracts returns the genesis allocation of the stand-ins of the
+// system contracts, whitelisting testCurrency.
+func testSystemContracts() core.GenesisAlloc {
+	registry := make(map[common.Hash]common.Hash)
+	for id, address := range map[string]common.Address{
+		params.GoldTokenRegistryId:            testGoldToken,
+		params.GasCurrencyWhitelistRegistryId: testWhitelist,
+		params.GasPriceMinimumRegistryId:      testGasPriceMinimum,
+		params.GovernanceRegistryId:           testGovernance,
+		params.ReserveRegistryId:              testReserve,
+		params.RandomRegistryId:               testRandom,
+	} {
+		registry[common.BytesToHash(common.RightPadBytes([]byte(id), common.HashLength))] = address.Hash()
+	}
+	minimums := map[common.Hash]common.Hash{
+		common.BigToHash(common.Big0): common.BigToHash(big.NewInt(testInfraFraction[0])),
+		common.BigToHash(common.Big1): common.BigToHash(big.NewInt(testInfraFraction[1])),
+	}
+	for currency, minimum := range testGasPriceMinimums {
+		minimums[currency.Hash()] = common.BigToHash(big.NewInt(minimum))
+	}
+	return core.GenesisAlloc{
+		testRegistry: {Code: testRegistryCode, Storage: registry, Balance: new(big.Int)},
+		testWhitelist: {Code: testWhitelistCode, Balance: new(big.Int), Storage: map[common.Hash]common.Hash{
+			common.BigToHash(common.Big0): common.BigToHash(big.NewInt(32)),
+			common.BigToHash(common.Big1): common.BigToHash(common.Big1),
+			common.BigToHash(common.Big2): testCurrency.Hash(),
+		}},
+		testGasPriceMinimum: {Code: testGasPriceMinimumCode, Storage: minimums, Balance: new(big.Int)},
+		testReserve:         {Code: testReserveCode, Balance: new(big.Int)},
+		testRandom:          {Code: testRandomCode, Balance: new(big.Int)},
+		testCurrency:        {Code: testGasCurrencyCode, Balance: new(big.Int)},
+	}
+}
+
+// testBackend serves a chain whose blocks all share the state of its genesis
+// block, which deploys the stand-ins of the system contracts.
+type testBackend struct {
+	Backend // Methods not needed by the tests are left unimplemented
+
+	chain    *core.BlockChain
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+
+	regAdd *core.RegisteredAddresses
+	gcWl   *core.GasCurrencyWhitelist
+	gpm    *core.GasPriceMinimum
+	co     *core.CurrencyOperator
+	random *core.Random
+}
+
+func newTestBackend(t *testing.T, alloc core.GenesisAlloc) *testBackend {
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{Config: params.TestChainConfig, GasLimit: 8000000, Alloc: alloc}
+	genesis := gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	iEvmH := core.NewInternalEVMHandler(chain)
+	regAdd := core.NewRegisteredAddresses(iEvmH)
+	iEvmH.SetRegisteredAddresses(regAdd)
+	gcWl := core.NewGasCurrencyWhitelist(regAdd, iEvmH)
+
+	return &testBackend{
+		chain:    chain,
+		blocks:   []*types.Block{genesis},
+		receipts: make(map[common.Hash]types.Receipts),
+		regAdd:   regAdd,
+		gcWl:     gcWl,
+		gpm:      core.NewGasPriceMinimum(iEvmH, regAdd),
+		co:       core.NewCurrencyOperator(gcWl, regAdd, iEvmH, nil),
+		random:   core.NewRandom(regAdd, iEvmH, nil),
+	}
+}
+
+// addBlock appends a block of the given transactions, charged the given gas, on
+// top of the genesis state.
+func (b *testBackend) addBlock(txs types.Transactions, gasUsed ...uint64) *types.Block {
+	parent := b.blocks[len(b.blocks)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Root:       parent.Root(),
+		GasLimit:   parent.GasLimit(),
+	}
+	receipts := make(types.Receipts, len(txs))
+	for i := range txs {
+		receipts[i] = &types.Receipt{GasUsed: gasUsed[i], TxHash: txs[i].Hash()}
+		header.GasUsed += gasUsed[i]
+	}
+	block := types.NewBlock(header, txs, nil, receipts, nil)
+	b.blocks = append(b.blocks, block)
+	b.receipts[block.Hash()] = receipts
+	return block
+}
+
+// addRevealBlock appends an empty block in which the given proposer reveals the
+// given randomness, on top of the genesis state.
+func (b *testBackend) addRevealBlock(proposer common.Address, revealed, committed common.Hash) *types.Block {
+	parent := b.blocks[len(b.blocks)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Root:       parent.Root(),
+		GasLimit:   parent.GasLimit(),
+		Coinbase:   proposer,
+	}
+	block := types.NewBlock(header, nil, nil, nil, &types.Randomness{Revealed: revealed, Committed: committed})
+	b.blocks = append(b.blocks, block)
+	return block
+}
+
+func (b *testBackend) block(blockNr rpc.BlockNumber) *types.Block {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return b.blocks[len(b.blocks)-1]
+	}
+	if int(blockNr) < len(b.blocks) {
+		return b.blocks[blockNr]
+	}
+	return nil
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if block := b.block(blockNr); block != nil {
+		return block.Header(), nil
+	}
+	return nil, nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	return b.block(blockNr), nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	block := b.block(blockNr)
+	if block == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(block.Root())
+	return statedb, block.Header(), err
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), new(big.Int).SetUint64(math.MaxUint64))
+	context := core.NewEVMContext(msg, header, b.chain, nil, b.regAdd.GetRegisteredAddressMapAtStateAndHeader(state, header))
+	return vm.NewEVM(context, state, b.chain.Config(), vm.Config{}), func() error { return nil }, nil
+}
+
+func (b *testBackend) SuggestPriceInCurrency(ctx context.Context, currency *common.Address) (*big.Int, error) {
+	return big.NewInt(testGasPriceMinimums[testGoldToken]), nil
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig                 { return b.chain.Config() }
+func (b *testBackend) GasCurrencyWhitelist() *core.GasCurrencyWhitelist { return b.gcWl }
+func (b *testBackend) CurrencyOperator() *core.CurrencyOperator         { return b.co }
+func (b *testBackend) RegisteredAddresses() *core.RegisteredAddresses   { return b.regAdd }
+func (b *testBackend) GasPriceMinimum() *core.GasPriceMinimum           { return b.gpm }
+func (b *testBackend) Random() *core.Random                             { return b.random }
+
+func bigsEqual(have []*hexutil.Big, want []int64) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	for i := range have {
+		if have[i] == nil || have[i].ToInt().Cmp(big.NewInt(want[i])) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Tests that the fee history covers the requested range of blocks.
+func TestFeeHistoryRange(t *testing.T) {
+	b := newTestBackend(t, testSystemContracts())
+	defer b.chain.Stop()
+	for i := 0; i < 3; i++ {
+		b.addBlock(nil)
+	}
+	api := NewPublicCeloAPI(b)
+
+	tests := []struct {
+		count   uint64
+		newest  rpc.BlockNumber
+		oldest  uint64
+		numbers []uint64
+	}{
+		{0, rpc.LatestBlockNumber, 0, nil},
+		{2, 2, 1, []uint64{1, 2}},
+		{10, rpc.LatestBlockNumber, 0, []uint64{0, 1, 2, 3}},
+		{1, rpc.PendingBlockNumber, 3, []uint64{3}},
+	}
+	for i, tt := range tests {
+		result, err := api.FeeHistory(context.Background(), hexutil.Uint64(tt.count), tt.newest, nil, nil)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve fee history: %v", i, err)
+		}
+		var numbers []uint64
+		for _, block := range result.Blocks {
+			numbers = append(numbers, uint64(block.Number))
+		}
+		if uint64(result.OldestBlock) != tt.oldest || !reflect.DeepEqual(numbers, tt.numbers) {
+			t.Errorf("test %d: blocks mismatch: have %v from %d, want %v from %d", i, numbers, result.OldestBlock, tt.numbers, tt.oldest)
+		}
+	}
+	if _, err := api.FeeHistory(context.Background(), maxFeeHistoryBlocks+1, rpc.LatestBlockNumber, nil, nil); err == nil {
+		t.Errorf("block count above limit accepted")
+	}
+}
+
+// Tests that the fee history reports the gas price minimums, fees and gas price
+// percentiles of every currency gas was paid in.
+func TestFeeHistoryCurrencies(t *testing.T) {
+	b := newTestBackend(t, testSystemContracts())
+	defer b.chain.Stop()
+
+	recipient := common.HexToAddress("0x1002")
+	transaction := func(nonce uint64, price int64, currency *common.Address) *types.Transaction {
+		return types.NewTransaction(nonce, recipient, new(big.Int), 100000, big.NewInt(price), currency, nil, nil)
+	}
+	b.addBlock(types.Transactions{transaction(0, 10, nil), transaction(1, 30, nil)}, 21000, 63000)
+	b.addBlock(types.Transactions{transaction(2, 20, &testCurrency), transaction(3, 10, nil)}, 50000, 21000)
+	api := NewPublicCeloAPI(b)
+
+	result, err := api.FeeHistory(context.Background(), 2, rpc.LatestBlockNumber, nil, []float64{0, 25, 50, 100})
+	if err != nil {
+		t.Fatalf("failed to retrieve fee history: %v", err)
+	}
+	first, second := result.Blocks[0], result.Blocks[1]
+
+	// The gas prices of the first block are weighted by the gas used
+	if prices := first.GasPricePercentiles[testGoldToken]; !bigsEqual(prices, []int64{10, 10, 30, 30}) {
+		t.Errorf("gold percentiles mismatch: have %v, want [10 10 30 30]", prices)
+	}
+	if len(first.GasPricePercentiles) != 1 {
+		t.Errorf("percentile currencies mismatch: have %d, want 1", len(first.GasPricePercentiles))
+	}
+	// Fees of the second block are reported in the currency they were paid in
+	if ratio := second.GasUsedRatio; ratio != 71000.0/8000000 {
+		t.Errorf("gas used ratio mismatch: have %v, want %v", ratio, 71000.0/8000000)
+	}
+	if fraction := second.InfrastructureFraction; fraction.Numerator.ToInt().Int64() != testInfraFraction[0] || fraction.Denominator.ToInt().Int64() != testInfraFraction[1] {
+		t.Errorf("infrastructure fraction mismatch: have %v/%v, want %d/%d", fraction.Numerator, fraction.Denominator, testInfraFraction[0], testInfraFraction[1])
+	}
+	for currency, want := range map[common.Address][4]int64{
+		testGoldToken: {5, 21000 * 5 / 2, 21000*10 - 21000*5/2, 10},
+		testCurrency:  {7, 50000 * 7 / 2, 50000*20 - 50000*7/2, 20},
+	} {
+		have := []*hexutil.Big{second.GasPriceMinimums[currency], second.InfrastructureFees[currency], second.FeeRecipientFees[currency]}
+		if !bigsEqual(have, want[:3]) {
+			t.Errorf("currency %x: minimum and fees mismatch: have %v, want %v", currency, have, want[:3])
+		}
+		if prices := second.GasPricePercentiles[currency]; !bigsEqual(prices, []int64{want[3], want[3], want[3], want[3]}) {
+			t.Errorf("currency %x: percentiles mismatch: have %v, want %d", currency, prices, want[3])
+		}
+	}
+
+	// Only the requested currencies have their gas price minimum reported
+	result, err = api.FeeHistory(context.Background(), 1, rpc.LatestBlockNumber, []common.Address{testCurrency}, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve fee history: %v", err)
+	}
+	if minimums := result.Blocks[0].GasPriceMinimums; len(minimums) != 1 || minimums[testCurrency].ToInt().Int64() != 7 {
+		t.Errorf("gas price minimums mismatch: have %v, want only %x", minimums, testCurrency)
+	}
+	if result.Blocks[0].GasPricePercentiles != nil {
+		t.Errorf("percentiles reported without being requested")
+	}
+}
+
+// Tests that percentiles out of range or out of order are rejected.
+func TestFeeHistoryInvalidPercentiles(t *testing.T) {
+	b := newTestBackend(t, testSystemContracts())
+	defer b.chain.Stop()
+	api := NewPublicCeloAPI(b)
+
+	for i, percentiles := range [][]float64{{-1}, {101}, {50, 10}, make([]float64, maxFeeHistoryPercentiles+1)} {
+		if _, err := api.FeeHistory(context.Background(), 1, rpc.LatestBlockNumber, nil, percentiles); err == nil {
+			t.Errorf("test %d: percentiles %v accepted", i, percentiles)
+		}
+	}
+}
+
+// Tests that fee estimates cover the gas currency debits and credits, the share
+// of the infrastructure fund and the tobin tax taken from value transfers.
+func TestEstimateFee(t *testing.T) {
+	b := newTestBackend(t, testSystemContracts())
+	defer b.chain.Stop()
+	api := NewPublicCeloAPI(b)
+
+	from, to := common.HexToAddress("0x1001"), common.HexToAddress("0x1002")
+	tests := []struct {
+		currency *common.Address
+		value    int64
+		tobinTax int64
+	}{
+		{nil, 0, 0},
+		{nil, 1000, 100},
+		{&testCurrency, 0, 0},
+		{&testCurrency, 1000, 100},
+	}
+	for i, tt := range tests {
+		args := CallArgs{From: from, To: &to, GasPrice: hexutil.Big(*big.NewInt(20)), Value: hexutil.Big(*big.NewInt(tt.value))}
+		estimate, err := api.EstimateFee(context.Background(), args, tt.currency)
+		if err != nil {
+			t.Fatalf("test %d: failed to estimate fee: %v", i, err)
+		}
+		gasUsed, minimum := uint64(estimate.GasUsed), testGasPriceMinimums[testGoldToken]
+		if tt.currency != nil {
+			minimum = testGasPriceMinimums[*tt.currency]
+			// The allowance must cover the intrinsic gas of the debit and credits
+			if estimate.Gas < hexutil.Uint64(params.TxGas+params.AdditionalGasForNonGoldCurrencies) {
+				t.Errorf("test %d: gas mismatch: have %d, want at least %d", i, estimate.Gas, params.TxGas+params.AdditionalGasForNonGoldCurrencies)
+			}
+		}
+		if gasUsed < params.TxGas || uint64(estimate.Gas) < gasUsed {
+			t.Errorf("test %d: gas mismatch: have %d used of %d", i, gasUsed, estimate.Gas)
+		}
+		totalFee := int64(gasUsed) * 20
+		infraFee := int64(gasUsed) * minimum * testInfraFraction[0] / testInfraFraction[1]
+		have := []*hexutil.Big{estimate.TotalFee, estimate.InfrastructureFee, estimate.FeeRecipientFee, estimate.TobinTax}
+		if want := []int64{totalFee, infraFee, totalFee - infraFee, tt.tobinTax}; !bigsEqual(have, want) {
+			t.Errorf("test %d: fees mismatch: have %v, want %v", i, have, want)
+		}
+		// Without an exchange rate, the gold equivalent of non-gold fees is unknown
+		if tt.currency == nil && !bigsEqual([]*hexutil.Big{estimate.GoldFee}, []int64{totalFee + tt.tobinTax}) {
+			t.Errorf("test %d: gold fee mismatch: have %v, want %d", i, estimate.GoldFee, totalFee+tt.tobinTax)
+		}
+		if tt.currency != nil && estimate.GoldFee != nil {
+			t.Errorf("test %d: gold fee mismatch: have %v, want none", i, estimate.GoldFee)
+		}
+	}
+}
+
+// Tests that the randomness revealed in a block is checked against the commitment
+// its proposer had on record, telling a first reveal apart from a failed check.
+func TestGetRandomness(t *testing.T) {
+	var (
+		proposer  = common.HexToAddress("0x1001")
+		newcomer  = common.HexToAddress("0x1002")
+		revealed  = common.HexToHash("0x01")
+		committed = common.HexToHash("0x02")
+	)
+	alloc := testSystemContracts()
+	random := alloc[testRandom]
+	random.Storage = map[common.Hash]common.Hash{proposer.Hash(): crypto.Keccak256Hash(revealed.Bytes())}
+	alloc[testRandom] = random
+	b := newTestBackend(t, alloc)
+	defer b.chain.Stop()
+
+	b.addRevealBlock(proposer, revealed, committed)
+	b.addRevealBlock(newcomer, revealed, committed)
+	b.addRevealBlock(proposer, committed, committed)
+	api := NewPublicCeloAPI(b)
+
+	if _, err := api.GetRandomness(context.Background(), 0); err == nil {
+		t.Errorf("genesis randomness: expected error")
+	}
+	tests := []struct {
+		number   rpc.BlockNumber
+		proposer common.Address
+		previous common.Hash
+		status   string
+	}{
+		{1, proposer, crypto.Keccak256Hash(revealed.Bytes()), randomnessVerified},
+		{2, newcomer, common.Hash{}, randomnessFirstReveal},
+		{3, proposer, crypto.Keccak256Hash(revealed.Bytes()), randomnessMismatch},
+	}
+	for _, tt := range tests {
+		result, err := api.GetRandomness(context.Background(), tt.number)
+		if err != nil {
+			t.Fatalf("block %d: failed to get randomness: %v", tt.number, err)
+		}
+		if result.Committer != tt.proposer || result.Commitment != committed {
+			t.Errorf("block %d: committer mismatch: have %x %x, want %x %x", tt.number, result.Committer, result.Commitment, tt.proposer, committed)
+		}
+		if result.PreviousCommitment != tt.previous {
+			t.Errorf("block %d: previous commitment mismatch: have %x, want %x", tt.number, result.PreviousCommitment, tt.previous)
+		}
+		if result.Status != tt.status {
+			t.Errorf("block %d: status mismatch: have %s, want %s", tt.number, result.Status, tt.status)
+		}
+	}
+}
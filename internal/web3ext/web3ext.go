@@ -18,8 +18,10 @@
 package web3ext
 
 var Modules = map[string]string{
+	"abe":        ABE_JS,
 	"accounting": Accounting_JS,
 	"admin":      Admin_JS,
+	"celo":       Celo_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
 	"ethash":     Ethash_JS,
@@ -35,6 +37,19 @@ var Modules = map[string]string{
 	"istanbul":   Istanbul_JS,
 }
 
+const ABE_JS = `
+web3._extend({
+	property: 'abe',
+	methods: [],
+	properties: [
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'abe_status'
+		}),
+	]
+});
+`
+
 const Chequebook_JS = `
 web3._extend({
 	property: 'chequebook',
@@ -503,6 +518,10 @@ web3._extend({
 		}),
 	],
 	properties: [
+		new web3._extend.Property({
+			name: 'gasPrices',
+			getter: 'eth_gasPrices'
+		}),
 		new web3._extend.Property({
 			name: 'pendingTransactions',
 			getter: 'eth_pendingTransactions',
@@ -700,6 +719,10 @@ web3._extend({
 			outputFormatter: function(status) {
 				status.pending = web3._extend.utils.toDecimal(status.pending);
 				status.queued = web3._extend.utils.toDecimal(status.queued);
+				for (var currency in status.currencies) {
+					status.currencies[currency].pending = web3._extend.utils.toDecimal(status.currencies[currency].pending);
+					status.currencies[currency].queued = web3._extend.utils.toDecimal(status.currencies[currency].queued);
+				}
 				return status;
 			}
 		}),
@@ -751,6 +774,32 @@ web3._extend({
 	});
 `
 
+const Celo_JS = `
+web3._extend({
+	property: 'celo',
+	methods: [
+		new web3._extend.Method({
+			name: 'feeHistory',
+			call: 'celo_feeHistory',
+			params: 4,
+			inputFormatter: [web3._extend.utils.toHex, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'estimateFee',
+			call: 'celo_estimateFee',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getRandomness',
+			call: 'celo_getRandomness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	]
+});
+`
+
 const Istanbul_JS = `
 web3._extend({
 	property: 'istanbul',
@@ -787,6 +836,16 @@ web3._extend({
 			name: 'discard',
 			call: 'istanbul_discard',
 			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorUptime',
+			call: 'istanbul_getValidatorUptime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'exportEquivocation',
+			call: 'istanbul_exportEquivocation',
+			params: 1
 		})
 	],
 	properties:
@@ -795,6 +854,14 @@ web3._extend({
 			name: 'candidates',
 			getter: 'istanbul_candidates'
 		}),
+		new web3._extend.Property({
+			name: 'equivocations',
+			getter: 'istanbul_getEquivocations'
+		}),
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'istanbul_status'
+		}),
 	]
 });
 `
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/light"
@@ -39,6 +40,7 @@ import (
 
 type LesApiBackend struct {
 	eth *LightEthereum
+	gpo *gasprice.Oracle
 }
 
 func (b *LesApiBackend) ChainConfig() *params.ChainConfig {
@@ -139,6 +141,10 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolCurrencyStats() map[common.Address]*core.CurrencyStats {
+	return b.eth.txPool.CurrencyStats()
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -172,11 +178,11 @@ func (b *LesApiBackend) ProtocolVersion() int {
 }
 
 func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
-	return b.eth.gpm.GetGasPriceSuggestion(nil, nil, nil)
+	return b.gpo.SuggestPrice(ctx, nil)
 }
 
 func (b *LesApiBackend) SuggestPriceInCurrency(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
-	return b.eth.gpm.GetGasPriceSuggestion(currencyAddress, nil, nil)
+	return b.gpo.SuggestPrice(ctx, currencyAddress)
 }
 
 func (b *LesApiBackend) GetGasPriceMinimum(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
@@ -217,6 +223,11 @@ func (b *LesApiBackend) GasCurrencyWhitelist() *core.GasCurrencyWhitelist {
 	return b.eth.gcWl
 }
 
+// CurrencyOperator returns nil, as light clients don't track exchange rates.
+func (b *LesApiBackend) CurrencyOperator() *core.CurrencyOperator {
+	return nil
+}
+
 func (b *LesApiBackend) GasFeeRecipient() common.Address {
 	return b.eth.GetRandomPeerEtherbase()
 }
@@ -228,3 +239,7 @@ func (b *LesApiBackend) RegisteredAddresses() *core.RegisteredAddresses {
 func (b *LesApiBackend) GasPriceMinimum() *core.GasPriceMinimum {
 	return b.eth.gpm
 }
+
+func (b *LesApiBackend) Random() *core.Random {
+	return b.eth.random
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/light"
@@ -77,6 +78,7 @@ type LightEthereum struct {
 	iEvmH  *core.InternalEVMHandler
 	gcWl   *core.GasCurrencyWhitelist
 	gpm    *core.GasPriceMinimum
+	random *core.Random
 
 	wg sync.WaitGroup
 }
@@ -158,6 +160,7 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	leth.iEvmH.SetRegisteredAddresses(leth.regAdd)
 	leth.gcWl = core.NewGasCurrencyWhitelist(leth.regAdd, leth.iEvmH)
 	leth.gpm = core.NewGasPriceMinimum(leth.iEvmH, leth.regAdd)
+	leth.random = core.NewRandom(leth.regAdd, leth.iEvmH, nil)
 
 	// Note: AddChildIndexer starts the update process for the child
 	leth.bloomIndexer.AddChildIndexer(leth.bloomTrieIndexer)
@@ -175,7 +178,9 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	if leth.protocolManager, err = NewProtocolManager(leth.chainConfig, light.DefaultClientIndexerConfig, syncMode, config.NetworkId, leth.eventMux, leth.engine, leth.peers, leth.blockchain, nil, chainDb, leth.odr, leth.relay, leth.serverPool, quitSync, &leth.wg, config.Etherbase); err != nil {
 		return nil, err
 	}
-	leth.ApiBackend = &LesApiBackend{leth}
+	leth.ApiBackend = &LesApiBackend{leth, nil}
+	leth.ApiBackend.gpo = gasprice.NewOracle(leth.ApiBackend, config.GPO)
+
 	return leth, nil
 }
 
@@ -498,7 +498,7 @@ func TestTransactionStatusLes2(t *testing.T) {
 	chain := pm.blockchain.(*core.BlockChain)
 	config := core.DefaultTxPoolConfig
 	config.Journal = ""
-	co := core.NewCurrencyOperator(nil, nil, nil)
+	co := core.NewCurrencyOperator(nil, nil, nil, nil)
 	txpool := core.NewTxPool(config, params.TestChainConfig, chain, co, nil, nil)
 	pm.txpool = txpool
 	peer, _ := newTestPeer(t, "peer", 2, pm, true)
@@ -539,6 +539,26 @@ func (self *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// CurrencyStats retrieves the number of pending transactions paying for gas in
+// each currency, gold being keyed by the zero address.
+func (self *TxPool) CurrencyStats() map[common.Address]*core.CurrencyStats {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	stats := make(map[common.Address]*core.CurrencyStats)
+	for _, tx := range self.pending {
+		var currency common.Address
+		if tx.GasCurrency() != nil {
+			currency = *tx.GasCurrency()
+		}
+		if stats[currency] == nil {
+			stats[currency] = new(core.CurrencyStats)
+		}
+		stats[currency].Pending++
+	}
+	return stats
+}
+
 // RemoveTransactions removes all given transactions from the pool.
 func (self *TxPool) RemoveTransactions(txs types.Transactions) {
 	self.mu.Lock()
@@ -32,7 +32,6 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -89,6 +88,7 @@ type environment struct {
 	tcount    int            // tx count in cycle
 	gasPool   *core.GasPool  // available gas used to pack transactions
 
+	parent     *types.Header // Header of the block being built upon, gas prices are compared at its exchange rates
 	header     *types.Header
 	txs        []*types.Transaction
 	receipts   []*types.Receipt
@@ -180,47 +180,41 @@ type worker struct {
 	fullTaskHook func()                             // Method to call before pushing the full sealing task.
 	resubmitHook func(time.Duration, time.Duration) // Method to call upon updating resubmitting interval.
 
-	// Verification Service
-	verificationService string
-	verificationMu      sync.RWMutex
-	lastBlockVerified   uint64
+	// Attestation requests to deliver to the verification service
+	attestations *abe.Outbox
 
 	// Transaction processing
 	co     *core.CurrencyOperator
 	random *core.Random
-
-	// Needed for randomness
-	db *ethdb.Database
 }
 
-func newWorker(config *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, recommit time.Duration, gasFloor, gasCeil uint64, isLocalBlock func(*types.Block) bool, verificationService string, co *core.CurrencyOperator, random *core.Random, db *ethdb.Database) *worker {
+func newWorker(config *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, recommit time.Duration, gasFloor, gasCeil uint64, isLocalBlock func(*types.Block) bool, attestations *abe.Outbox, co *core.CurrencyOperator, random *core.Random) *worker {
 	worker := &worker{
-		config:              config,
-		engine:              engine,
-		eth:                 eth,
-		mux:                 mux,
-		chain:               eth.BlockChain(),
-		gasFloor:            gasFloor,
-		gasCeil:             gasCeil,
-		isLocalBlock:        isLocalBlock,
-		verificationService: verificationService,
-		localUncles:         make(map[common.Hash]*types.Block),
-		remoteUncles:        make(map[common.Hash]*types.Block),
-		unconfirmed:         newUnconfirmedBlocks(eth.BlockChain(), miningLogAtDepth),
-		pendingTasks:        make(map[common.Hash]*task),
-		txsCh:               make(chan core.NewTxsEvent, txChanSize),
-		chainHeadCh:         make(chan core.ChainHeadEvent, chainHeadChanSize),
-		chainSideCh:         make(chan core.ChainSideEvent, chainSideChanSize),
-		newWorkCh:           make(chan *newWorkReq),
-		taskCh:              make(chan *task),
-		resultCh:            make(chan *types.Block, resultQueueSize),
-		exitCh:              make(chan struct{}),
-		startCh:             make(chan struct{}, 1),
-		resubmitIntervalCh:  make(chan time.Duration),
-		resubmitAdjustCh:    make(chan *intervalAdjust, resubmitAdjustChanSize),
-		co:                  co,
-		random:              random,
-		db:                  db,
+		config:             config,
+		engine:             engine,
+		eth:                eth,
+		mux:                mux,
+		chain:              eth.BlockChain(),
+		gasFloor:           gasFloor,
+		gasCeil:            gasCeil,
+		isLocalBlock:       isLocalBlock,
+		attestations:       attestations,
+		localUncles:        make(map[common.Hash]*types.Block),
+		remoteUncles:       make(map[common.Hash]*types.Block),
+		unconfirmed:        newUnconfirmedBlocks(eth.BlockChain(), miningLogAtDepth),
+		pendingTasks:       make(map[common.Hash]*task),
+		txsCh:              make(chan core.NewTxsEvent, txChanSize),
+		chainHeadCh:        make(chan core.ChainHeadEvent, chainHeadChanSize),
+		chainSideCh:        make(chan core.ChainSideEvent, chainSideChanSize),
+		newWorkCh:          make(chan *newWorkReq),
+		taskCh:             make(chan *task),
+		resultCh:           make(chan *types.Block, resultQueueSize),
+		exitCh:             make(chan struct{}),
+		startCh:            make(chan struct{}, 1),
+		resubmitIntervalCh: make(chan time.Duration),
+		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
+		co:                 co,
+		random:             random,
 	}
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
@@ -323,8 +317,12 @@ func (w *worker) close() {
 	close(w.exitCh)
 }
 
-func (w *worker) txCmp(tx1 *types.Transaction, tx2 *types.Transaction) int {
-	return w.co.Cmp(tx1.GasPrice(), tx1.GasCurrency(), tx2.GasPrice(), tx2.GasCurrency())
+// txCmpAt returns a comparator of transaction gas prices that uses the exchange rates
+// computed from the state of the given header.
+func (w *worker) txCmpAt(header *types.Header) func(tx1 *types.Transaction, tx2 *types.Transaction) int {
+	return func(tx1 *types.Transaction, tx2 *types.Transaction) int {
+		return w.co.CmpAtHeader(tx1.GasPrice(), tx1.GasCurrency(), tx2.GasPrice(), tx2.GasCurrency(), header)
+	}
 }
 
 // newWorkLoop is a standalone goroutine to submit new mining work upon received events.
@@ -393,21 +391,9 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
 
-			processAttestationRequestsUpTo := func(number uint64) {
-				w.verificationMu.Lock()
-				defer w.verificationMu.Unlock()
-				for blockNum := number; blockNum > w.lastBlockVerified; blockNum-- {
-					block := w.chain.GetBlockByNumber(number)
-					if now := time.Now().Unix(); block.Time().Uint64()+params.AttestationExpirySeconds >= uint64(now) {
-						receipts := w.chain.GetReceiptsByHash(block.Hash())
-						abe.SendAttestationMessages(receipts, block, w.coinbase, w.eth.AccountManager(), w.verificationService)
-					} else {
-						break
-					}
-				}
-				w.lastBlockVerified = number
+			if w.attestations != nil {
+				go w.attestations.AddBlocks(w.chain, head.Block, w.coinbase)
 			}
-			go processAttestationRequestsUpTo(headNumber)
 
 		case <-timer.C:
 			// If mining is running resubmit a new work cycle periodically to pull in
@@ -531,7 +517,7 @@ func (w *worker) mainLoop() {
 					wl.RefreshWhitelistAtCurrentHeader()
 				}
 
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.txCmp)
+				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.txCmpAt(w.current.parent))
 				w.commitTransactions(txset, coinbase, nil)
 				w.updateSnapshot()
 			} else {
@@ -685,6 +671,7 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
+		parent:    parent.Header(),
 		header:    header,
 	}
 
@@ -998,13 +985,20 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 
 	// Play our part in generating the random beacon.
 	if w.isRunning() && w.random != nil && w.random.Running() {
-		lastRandomness, err := w.random.GetLastRandomness(w.coinbase, w.db, w.current.header, w.current.state)
-		if err != nil {
+		lastRandomness, err := w.random.GetLastRandomness(w.coinbase, w.current.header, w.current.state)
+		if err == core.ErrRandomnessPreimageNotFound {
+			log.Warn("Preimage of the last randomness commitment missing, regenerating it", "coinbase", w.coinbase)
+			lastRandomness, err = w.random.RecoverLastRandomness(w.coinbase, w.chain, w.current.header, w.current.state)
+		}
+		if err == core.ErrRandomnessPreimageNotFound {
+			log.Error("Cannot reveal randomness without the preimage of the last commitment, import a backup with 'geth randomness import'", "coinbase", w.coinbase)
+			return
+		} else if err != nil {
 			log.Error("Failed to get last randomness", "err", err)
 			return
 		}
 
-		commitment, err := w.random.GenerateNewRandomnessAndCommitment(w.current.header, w.current.state, w.db)
+		commitment, err := w.random.GenerateNewRandomnessAndCommitment(w.current.header, w.current.state)
 		if err != nil {
 			log.Error("Failed to generate randomness commitment", "err", err)
 			return
@@ -1044,13 +1038,13 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs, w.txCmp)
+		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs, w.txCmpAt(w.current.parent))
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs, w.txCmp)
+		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs, w.txCmpAt(w.current.parent))
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
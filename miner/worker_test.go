@@ -53,8 +53,6 @@ var (
 	testUserKey, _  = crypto.GenerateKey()
 	testUserAddress = crypto.PubkeyToAddress(testUserKey.PublicKey)
 
-	testVerificationService = ""
-
 	// Test transactions
 	pendingTxs []*types.Transaction
 	newTxs     []*types.Transaction
@@ -128,7 +126,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 	iEvmH.SetRegisteredAddresses(regAdd)
 	gcWl := core.NewGasCurrencyWhitelist(regAdd, iEvmH)
 	gpm := core.NewGasPriceMinimum(iEvmH, regAdd)
-	co := core.NewCurrencyOperator(gcWl, regAdd, iEvmH)
+	co := core.NewCurrencyOperator(gcWl, regAdd, iEvmH, chain)
 
 	txpool := core.NewTxPool(testTxPoolConfig, chainConfig, chain, co, nil, nil)
 
@@ -188,9 +186,9 @@ func newTestWorker(t *testing.T, chainConfig *params.ChainConfig, engine consens
 	if shouldAddPendingTxs {
 		backend.txPool.AddLocals(pendingTxs)
 	}
-	co := core.NewCurrencyOperator(nil, nil, nil)
-	random := core.NewRandom(backend.regAdd, backend.iEvmH)
-	w := newWorker(chainConfig, engine, backend, new(event.TypeMux), time.Second, params.GenesisGasLimit, params.GenesisGasLimit, nil, testVerificationService, co, random, &backend.db)
+	co := core.NewCurrencyOperator(nil, nil, nil, nil)
+	random := core.NewRandom(backend.regAdd, backend.iEvmH, core.NewRandomnessStore(ethdb.NewMemDatabase()))
+	w := newWorker(chainConfig, engine, backend, new(event.TypeMux), time.Second, params.GenesisGasLimit, params.GenesisGasLimit, nil, nil, co, random)
 	w.setEtherbase(testBankAddress)
 	return w, backend
 }
@@ -208,7 +206,7 @@ func getAuthorizedIstanbulEngine() consensus.Istanbul {
 		return crypto.Sign(data, testBankKey)
 	}
 
-	engine := istanbulBackend.New(istanbul.DefaultConfig, ethdb.NewMemDatabase())
+	engine := istanbulBackend.New(istanbul.DefaultConfig, ethdb.NewMemDatabase(), nil)
 	engine.(*istanbulBackend.Backend).Authorize(crypto.PubkeyToAddress(testBankKey.PublicKey), signerFn)
 	return engine
 }
@@ -70,6 +70,29 @@ func (ec *EthereumClient) GetHeaderByNumber(ctx *Context, number int64) (header
 	return &Header{rawHeader}, err
 }
 
+// Randomness represents the randomness revealed in a block.
+type Randomness struct {
+	randomness *ethclient.Randomness
+}
+
+func (r *Randomness) GetNumber() int64             { return r.randomness.Number.Int64() }
+func (r *Randomness) GetRevealed() *Hash           { return &Hash{r.randomness.Revealed} }
+func (r *Randomness) GetCommitter() *Address       { return &Address{r.randomness.Committer} }
+func (r *Randomness) GetCommitment() *Hash         { return &Hash{r.randomness.Commitment} }
+func (r *Randomness) GetPreviousCommitment() *Hash { return &Hash{r.randomness.PreviousCommitment} }
+func (r *Randomness) GetStatus() string            { return r.randomness.Status }
+
+// GetRandomnessByNumber returns the randomness revealed in the block with the
+// given number, or in the latest known block if number is <0.
+func (ec *EthereumClient) GetRandomnessByNumber(ctx *Context, number int64) (randomness *Randomness, _ error) {
+	if number < 0 {
+		rawRandomness, err := ec.client.RandomnessAt(ctx.context, nil)
+		return &Randomness{rawRandomness}, err
+	}
+	rawRandomness, err := ec.client.RandomnessAt(ctx.context, big.NewInt(number))
+	return &Randomness{rawRandomness}, err
+}
+
 // GetTransactionByHash returns the transaction with the given hash.
 func (ec *EthereumClient) GetTransactionByHash(ctx *Context, hash *Hash) (tx *Transaction, _ error) {
 	// TODO(karalabe): handle isPending
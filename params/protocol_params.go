@@ -120,8 +120,12 @@ const (
 )
 
 const (
-	// TODO(asa): Make these operations less expensive by charging only what is used.
-	// The problem is we don't know how much to refund until the refund is complete.
+	// Gas budgets of the non-gold gas currency operations, all of which are charged
+	// via IntrinsicGas. From the GasCurrencyRefundBlock onwards, the part of the
+	// balance read and debit budgets that is not used is refunded.
+	// TODO(asa): Make the credits less expensive by charging only what is used.
+	// The problem is we don't know how much to refund until the refund is complete,
+	// and the fees paid before it are computed from the gas used.
 	// If these values are changed, "setDefaults" will need updating.
 	MaxGasForDebitFromTransactions      uint64 = 50 * 1000
 	ExpectedGasForDebitFromTransactions uint64 = 35 * 1000